@@ -15,10 +15,30 @@ import (
 
 // Estruturas de mensagens (simplificadas para o cliente)
 type ClientMsg struct {
-	T      string `json:"t"`
-	CardID string `json:"cardId,omitempty"`
-	Text   string `json:"text,omitempty"`
-	TS     int64  `json:"ts,omitempty"`
+	T           string   `json:"t"`
+	CardID      string   `json:"cardId,omitempty"`
+	Action      string   `json:"action,omitempty"`
+	Text        string   `json:"text,omitempty"`
+	TS          int64    `json:"ts,omitempty"`
+	Mode        string   `json:"mode,omitempty"`
+	Target      string   `json:"target,omitempty"`
+	MatchID     string   `json:"matchId,omitempty"`
+	TimeControl string   `json:"timeControl,omitempty"`
+	Cards       []string `json:"cards,omitempty"`
+	Terrain     bool     `json:"terrain,omitempty"`
+	Rules       *Rules   `json:"rules,omitempty"`
+	Code        string   `json:"code,omitempty"`
+	PlayerID    string   `json:"playerId,omitempty"`
+	ChallengeID string   `json:"challengeId,omitempty"`
+	Password    string   `json:"password,omitempty"`
+	Spectators  bool     `json:"spectators,omitempty"`
+	Spectate    bool     `json:"spectate,omitempty"`
+	Async       bool     `json:"async,omitempty"`
+	EncounterID string   `json:"encounterId,omitempty"`
+	ScenarioID  string   `json:"scenarioId,omitempty"`
+	Ranked      bool     `json:"ranked,omitempty"`
+	Page        int      `json:"page,omitempty"`
+	Token       string   `json:"token,omitempty"`
 }
 
 type ServerMsg struct {
@@ -36,20 +56,251 @@ type ServerMsg struct {
 	TS         int64       `json:"ts,omitempty"`
 	RTTMs      int64       `json:"rttMs,omitempty"`
 	Result     string      `json:"result,omitempty"`
+	Reason     string      `json:"reason,omitempty"`
+	Terrain    string      `json:"terrain,omitempty"`
 	Logs       []string    `json:"logs,omitempty"`
+	// Campos para o modo por turnos
+	Mode        string `json:"mode,omitempty"`
+	TimeControl string `json:"timeControl,omitempty"`
+	Phase       string `json:"phase,omitempty"`
+	AttackerID  string `json:"attackerId,omitempty"`
+	// Campos para partidas com mais de dois jogadores
+	Seats     []SeatView `json:"seats,omitempty"`
+	PlayerIDs []string   `json:"playerIds,omitempty"`
+	// Campos para o modo draft
+	Pool []string `json:"pool,omitempty"`
+	Pick int      `json:"pick,omitempty"`
+	// Campos para o mulligan
+	Replaced int `json:"replaced,omitempty"`
+	// Campos para salas personalizadas
+	LobbyCode string      `json:"lobbyCode,omitempty"`
+	HostID    string      `json:"hostId,omitempty"`
+	Rules     *Rules      `json:"rules,omitempty"`
+	Lobby     *LobbyView  `json:"lobby,omitempty"`
+	Lobbies   []LobbyView `json:"lobbies,omitempty"`
+	// Campos para espectadores
+	SpectatorIDs []string          `json:"spectatorIds,omitempty"`
+	Results      map[string]string `json:"results,omitempty"`
+	// Campos para desafios
+	ChallengeID string `json:"challengeId,omitempty"`
+	InviteCode  string `json:"inviteCode,omitempty"`
+	// Campos para partidas assíncronas
+	PlayerID string           `json:"playerId,omitempty"`
+	Token    string           `json:"token,omitempty"`
+	Matches  []AsyncMatchView `json:"matches,omitempty"`
+	// Campos para a campanha
+	EncounterID string          `json:"encounterId,omitempty"`
+	Encounters  []EncounterView `json:"encounters,omitempty"`
+	Coins       int             `json:"coins,omitempty"`
+	// Campos para os cenários de treino
+	Scenarios []ScenarioView  `json:"scenarios,omitempty"`
+	Report    *ScenarioReport `json:"report,omitempty"`
+	// Campos para o raid
+	Boss *BossView `json:"boss,omitempty"`
+	// Campos para o ranking
+	Rating      *RatingChange    `json:"rating,omitempty"`
+	Leaderboard *LeaderboardView `json:"leaderboard,omitempty"`
+	Season      *SeasonResult    `json:"season,omitempty"`
+	// Campos para o perfil
+	Profile *ProfileView `json:"profile,omitempty"`
+	// Campos para replays
+	Replay []ReplayEntry `json:"replay,omitempty"`
 	// Campos para chat
 	SenderID string `json:"senderId,omitempty"`
 	Text     string `json:"text,omitempty"`
 }
 
 type PlayerView struct {
-	HP           int      `json:"hp"`
-	Hand         []string `json:"hand,omitempty"`
-	HandSize     int      `json:"handSize,omitempty"`
-	CardID       string   `json:"cardId,omitempty"`
-	ElementBonus int      `json:"elementBonus,omitempty"`
-	DmgDealt     int      `json:"dmgDealt,omitempty"`
-	DmgTaken     int      `json:"dmgTaken,omitempty"`
+	HP           int          `json:"hp"`
+	Hand         []string     `json:"hand,omitempty"`
+	HandSize     int          `json:"handSize,omitempty"`
+	CardID       string       `json:"cardId,omitempty"`
+	Action       string       `json:"action,omitempty"`
+	ElementBonus int          `json:"elementBonus,omitempty"`
+	TerrainBonus int          `json:"terrainBonus,omitempty"`
+	DmgDealt     int          `json:"dmgDealt,omitempty"`
+	DmgTaken     int          `json:"dmgTaken,omitempty"`
+	Effects      []StatusView `json:"effects,omitempty"`
+	Energy       int          `json:"energy,omitempty"`
+	MaxEnergy    int          `json:"maxEnergy,omitempty"`
+	DeckSize     int          `json:"deckSize,omitempty"`
+	BankMs       int64        `json:"bankMs,omitempty"`
+}
+
+type SeatView struct {
+	PlayerID   string `json:"playerId"`
+	Team       int    `json:"team"`
+	Target     string `json:"target,omitempty"`
+	Eliminated bool   `json:"eliminated,omitempty"`
+	PlayerView
+}
+
+type ReplayEntry struct {
+	T        string            `json:"t"`
+	TS       int64             `json:"ts"`
+	Mode     string            `json:"mode,omitempty"`
+	Players  []string          `json:"players,omitempty"`
+	Hands    [][]string        `json:"hands,omitempty"`
+	Round    int               `json:"round,omitempty"`
+	Terrain  string            `json:"terrain,omitempty"`
+	PlayerID string            `json:"playerId,omitempty"`
+	CardID   string            `json:"cardId,omitempty"`
+	Action   string            `json:"action,omitempty"`
+	Cards    []string          `json:"cards,omitempty"`
+	Target   string            `json:"target,omitempty"`
+	Auto     bool              `json:"auto,omitempty"`
+	Seats    []SeatView        `json:"seats,omitempty"`
+	Results  map[string]string `json:"results,omitempty"`
+	Reason   string            `json:"reason,omitempty"`
+}
+
+type Rules struct {
+	HPStart           int      `json:"hpStart,omitempty"`
+	HandSize          int      `json:"handSize,omitempty"`
+	ElementalATKBonus *int     `json:"elementalAtkBonus,omitempty"`
+	RoundPlayTimeout  int      `json:"roundPlayTimeoutMs,omitempty"`
+	DeckPolicy        string   `json:"deckPolicy,omitempty"`
+	BannedCards       []string `json:"bannedCards,omitempty"`
+}
+
+type LobbyView struct {
+	Code              string `json:"code"`
+	HostID            string `json:"hostId"`
+	Mode              string `json:"mode"`
+	Rules             *Rules `json:"rules"`
+	Players           int    `json:"players"`
+	MaxPlayers        int    `json:"maxPlayers"`
+	PasswordProtected bool   `json:"passwordProtected"`
+	Spectators        bool   `json:"spectators"`
+}
+
+type AsyncMatchView struct {
+	MatchID    string `json:"matchId"`
+	Mode       string `json:"mode"`
+	OpponentID string `json:"opponentId"`
+	Round      int    `json:"round"`
+	YourTurn   bool   `json:"yourTurn"`
+	DeadlineMs int64  `json:"deadlineMs"`
+	HP         int    `json:"hp"`
+	OpponentHP int    `json:"opponentHp"`
+}
+
+type EncounterView struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Mode        string `json:"mode"`
+	EnemyHP     int    `json:"enemyHp"`
+	Strategy    string `json:"strategy"`
+	RewardPacks int    `json:"rewardPacks"`
+	RewardCoins int    `json:"rewardCoins"`
+	Unlocked    bool   `json:"unlocked"`
+	Cleared     bool   `json:"cleared"`
+}
+
+type BossView struct {
+	ID          string `json:"id"`
+	PlayerID    string `json:"playerId"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	HP          int    `json:"hp"`
+	Armor       int    `json:"armor"`
+	EnrageBelow int    `json:"enrageBelow"`
+	EnrageATK   int    `json:"enrageAtk"`
+	CleaveEvery int    `json:"cleaveEvery"`
+}
+
+type ScenarioView struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Mode        string `json:"mode"`
+	Goal        string `json:"goal"`
+	Rounds      int    `json:"rounds"`
+}
+
+type ScenarioReport struct {
+	ScenarioID string `json:"scenarioId"`
+	Goal       string `json:"goal"`
+	Rounds     int    `json:"rounds"`
+	Round      int    `json:"round"`
+	Passed     bool   `json:"passed"`
+	HP         int    `json:"hp"`
+	OpponentHP int    `json:"opponentHp"`
+}
+
+type RatingChange struct {
+	PlayerID string `json:"playerId"`
+	Season   int    `json:"season"`
+	Rating   int    `json:"rating"`
+	Delta    int    `json:"delta"`
+	Division string `json:"division"`
+}
+
+type LadderEntry struct {
+	Rank     int    `json:"rank"`
+	PlayerID string `json:"playerId"`
+	Rating   int    `json:"rating"`
+	Division string `json:"division"`
+	Wins     int    `json:"wins"`
+	Losses   int    `json:"losses"`
+	Draws    int    `json:"draws"`
+}
+
+type LeaderboardView struct {
+	Season      int           `json:"season"`
+	SeasonEndMs int64         `json:"seasonEndMs"`
+	Page        int           `json:"page"`
+	Pages       int           `json:"pages"`
+	Entries     []LadderEntry `json:"entries"`
+	You         *LadderEntry  `json:"you,omitempty"`
+}
+
+type SeasonResult struct {
+	PlayerID  string `json:"playerId"`
+	Season    int    `json:"season"`
+	Rank      int    `json:"rank"`
+	Rating    int    `json:"rating"`
+	NewRating int    `json:"newRating"`
+	Division  string `json:"division"`
+	Coins     int    `json:"coins"`
+}
+
+type ProfileView struct {
+	PlayerID         string         `json:"playerId"`
+	Matches          int            `json:"matches"`
+	Wins             int            `json:"wins"`
+	Losses           int            `json:"losses"`
+	Draws            int            `json:"draws"`
+	WinRate          int            `json:"winRate"`
+	Elements         []ElementStats `json:"elements"`
+	Rounds           int            `json:"rounds"`
+	AvgDamageDealt   float64        `json:"avgDamageDealt"`
+	AvgDamageTaken   float64        `json:"avgDamageTaken"`
+	FavoriteCards    []CardCount    `json:"favoriteCards"`
+	CurrentStreak    int            `json:"currentStreak"`
+	LongestWinStreak int            `json:"longestWinStreak"`
+	AutoPlayRate     int            `json:"autoPlayRate"`
+	Rating           int            `json:"rating"`
+	Division         string         `json:"division"`
+}
+
+type ElementStats struct {
+	Element string `json:"element"`
+	Matches int    `json:"matches"`
+	Wins    int    `json:"wins"`
+	WinRate int    `json:"winRate"`
+}
+
+type CardCount struct {
+	CardID string `json:"cardId"`
+	Plays  int    `json:"plays"`
+}
+
+type StatusView struct {
+	Type     string `json:"type"`
+	Duration int    `json:"duration"`
+	Stacks   int    `json:"stacks"`
 }
 
 // Estrutura para informações das cartas (simulada do servidor)
@@ -59,19 +310,21 @@ type Card struct {
 	Element string `json:"element"`
 	ATK     int    `json:"atk"`
 	DEF     int    `json:"def"`
+	Cost    int    `json:"cost"`
+	Effect  string `json:"effect,omitempty"`
 }
 
 // Base de dados de cartas local (simulada - em um jogo real viria do servidor)
 var cardDB = map[string]Card{
-	"c_001": {ID: "c_001", Name: "Fire Dragon", Element: "FIRE", ATK: 8, DEF: 5},
-	"c_002": {ID: "c_002", Name: "Ice Mage", Element: "WATER", ATK: 6, DEF: 6},
-	"c_003": {ID: "c_003", Name: "Vine Beast", Element: "PLANT", ATK: 7, DEF: 4},
-	"c_004": {ID: "c_004", Name: "Flame Warrior", Element: "FIRE", ATK: 6, DEF: 7},
-	"c_005": {ID: "c_005", Name: "Water Serpent", Element: "WATER", ATK: 9, DEF: 3},
-	"c_006": {ID: "c_006", Name: "Forest Guardian", Element: "PLANT", ATK: 5, DEF: 8},
-	"c_007": {ID: "c_007", Name: "Inferno Titan", Element: "FIRE", ATK: 10, DEF: 2},
-	"c_008": {ID: "c_008", Name: "Frost Giant", Element: "WATER", ATK: 7, DEF: 7},
-	"c_009": {ID: "c_009", Name: "Nature Spirit", Element: "PLANT", ATK: 4, DEF: 9},
+	"c_001": {ID: "c_001", Name: "Fire Dragon", Element: "FIRE", ATK: 8, DEF: 5, Cost: 3, Effect: "BURN 2 rodadas"},
+	"c_002": {ID: "c_002", Name: "Ice Mage", Element: "WATER", ATK: 6, DEF: 6, Cost: 2, Effect: "FREEZE 1 rodada"},
+	"c_003": {ID: "c_003", Name: "Vine Beast", Element: "PLANT", ATK: 7, DEF: 4, Cost: 2, Effect: "POISON 3 rodadas"},
+	"c_004": {ID: "c_004", Name: "Flame Warrior", Element: "FIRE", ATK: 6, DEF: 7, Cost: 2},
+	"c_005": {ID: "c_005", Name: "Water Serpent", Element: "WATER", ATK: 9, DEF: 3, Cost: 3},
+	"c_006": {ID: "c_006", Name: "Forest Guardian", Element: "PLANT", ATK: 5, DEF: 8, Cost: 1},
+	"c_007": {ID: "c_007", Name: "Inferno Titan", Element: "FIRE", ATK: 10, DEF: 2, Cost: 4},
+	"c_008": {ID: "c_008", Name: "Frost Giant", Element: "WATER", ATK: 7, DEF: 7, Cost: 3},
+	"c_009": {ID: "c_009", Name: "Nature Spirit", Element: "PLANT", ATK: 4, DEF: 9, Cost: 1, Effect: "REGEN 2 rodadas"},
 }
 
 func main() {
@@ -92,7 +345,18 @@ var (
 	showPing    bool
 	pingMutex   sync.RWMutex
 	inMatch     bool
+	opponentID  string
 	currentHand []string
+	draftPack   []string // pacote atual do draft (vazio fora do draft)
+	inMulligan  bool     // mão inicial aguardando a decisão do mulligan
+	inLobby     bool     // aguardando jogadores em uma sala personalizada
+	challengeID string   // último desafio recebido (usado por /accept e /decline sem ID)
+	lastMatchID string
+	matchID     string          // partida em foco, enviada nas mensagens da partida (necessária com várias partidas assíncronas)
+	encounters  []EncounterView // encontros da última listagem da campanha (usados por /encounter <número>)
+	scenarios   []ScenarioView  // cenários da última listagem (usados por /scenario <número>)
+	replayPages [][]string      // replay carregado: preparação e uma página por rodada
+	replayPage  int
 	gameState   *ServerMsg
 )
 
@@ -121,9 +385,11 @@ func handleConn(conn net.Conn) {
 		log.Printf("[CLIENT] Servidor fechou a conexão")
 	}()
 
-	// Envia FIND_MATCH automaticamente
-	sendMessage(encoder, ClientMsg{T: "FIND_MATCH"})
-	fmt.Println("🔍 Procurando partida...")
+	// Identifica o jogador (necessário para partidas assíncronas) e envia FIND_MATCH automaticamente
+	if name := getEnv("PLAYER_NAME", ""); name != "" {
+		sendMessage(encoder, ClientMsg{T: "LOGIN", PlayerID: name, Token: getEnv("PLAYER_TOKEN", savedToken(name))})
+	}
+	findMatch(encoder, getEnv("MATCH_MODE", ""), getEnv("MATCH_TIME_CONTROL", ""), getEnv("MATCH_TERRAIN", "") == "true")
 
 	// Goroutine para enviar PINGs periódicos
 	go func() {
@@ -141,13 +407,42 @@ func handleConn(conn net.Conn) {
 		inputScanner := bufio.NewScanner(os.Stdin)
 		fmt.Println("\n=== ATTRIBUTE WAR CLIENT ===")
 		fmt.Println("Comandos disponíveis:")
-		fmt.Println("  /play <idx> - Jogar carta pelo índice (1-5)")
+		fmt.Println("  /play <idx> [alvo] - Escolher carta pelo índice (1-5), opcionalmente contra um jogador")
+		fmt.Println("  /lock [idx] [alvo] - Confirmar a carta escolhida (ou escolher e confirmar de uma vez)")
+		fmt.Println("  /unplay     - Retirar a carta escolhida antes de confirmar")
+		fmt.Println("  /defend <idx> - Defender com a carta (DEF dobrada, sem atacar)")
+		fmt.Println("  /cycle <idx> <idx> - Trocar duas cartas da mão (sem defesa na rodada)")
+		fmt.Println("  /pick <idx> - Escolher carta do pacote no draft")
+		fmt.Println("  /mulligan [idx...] - Devolver cartas da mão inicial (sem índices, mantém a mão)")
 		fmt.Println("  /hand       - Mostrar sua mão atual")
 		fmt.Println("  /ping       - Liga/desliga exibição de RTT")
 		fmt.Println("  /pack       - Abrir pacote de cartas")
+		fmt.Println("  /replay [matchId|next|prev] - Ver o replay de uma partida finalizada")
+		fmt.Println("  /forfeit    - Desistir da partida atual")
+		fmt.Println("  /draw [accept|decline] - Oferecer, aceitar ou recusar empate")
+		fmt.Println("  /find [modo] [tempo] [terreno] - Procurar partida (simultaneo | turnos | 2v2 | 2v2compartilhado | ffa | draft | raid; blitz | classico | correspondencia)")
+		fmt.Println("  /ranked [modo] [tempo] - Procurar partida ranqueada 1v1 (exige /login; vale rating)")
+		fmt.Println("  /leaderboard [página] - Ver o ranking da temporada e sua posição")
+		fmt.Println("  /profile [jogador] - Ver as estatísticas de um jogador (padrão: as suas)")
+		fmt.Println("  /create [modo] [opção=valor...] - Criar sala personalizada (hp, mao, bonus, tempo em s, deck=aleatorio|singleton|draft, ban=c_001,c_002, senha, espectadores=sim)")
+		fmt.Println("  /join <código> [senha] - Entrar em uma sala personalizada pelo código de convite")
+		fmt.Println("  /spectate <código> [senha] - Assistir à partida de uma sala que aceita espectadores")
+		fmt.Println("  /lobbies    - Listar as salas abertas e acompanhar novas salas")
+		fmt.Println("  /challenge <jogador> [modo] [tempo|assincrono] - Desafiar um jogador pelo ID para uma partida 1v1")
+		fmt.Println("  /accept [id] / /decline [id] - Aceitar ou recusar um desafio (padrão: o último recebido)")
+		fmt.Println("  /invite [modo] [tempo|assincrono] - Gerar um código de convite de uso único")
+		fmt.Println("  /redeem <código> - Resgatar um código de convite e jogar contra quem o criou")
+		fmt.Println("  /login <nome> [token] - Identificar-se com um nome fixo (necessário para partidas assíncronas; o token do primeiro login é guardado e reenviado)")
+		fmt.Println("  /async [modo] [terreno] - Procurar partida assíncrona (jogue quando puder; sobrevive à desconexão)")
+		fmt.Println("  /matches    - Listar suas partidas assíncronas em andamento")
+		fmt.Println("  /open <matchId> - Abrir uma partida assíncrona e receber o estado atual")
+		fmt.Println("  /campaign   - Ver a campanha contra bots (exige /login)")
+		fmt.Println("  /encounter <número|id> - Jogar um encontro desbloqueado da campanha")
+		fmt.Println("  /scenarios  - Ver os cenários de treino (puzzles)")
+		fmt.Println("  /scenario <número|id> - Jogar um cenário de treino")
 		fmt.Println("  /help       - Mostrar ajuda")
 		fmt.Println("  /quit       - Sair do jogo")
-		fmt.Println("  [1-5]       - Atalho para jogar carta")
+		fmt.Println("  [1-5]       - Atalho para escolher carta")
 		fmt.Println("  <mensagem>  - Enviar chat")
 		fmt.Println()
 
@@ -159,10 +454,14 @@ func handleConn(conn net.Conn) {
 
 			if strings.HasPrefix(text, "/") {
 				handleCommand(text, encoder)
+			} else if len(draftPack) > 0 && len(text) == 1 && text >= "1" && text <= "5" {
+				// Atalho para escolher carta do draft por índice
+				cardIndex, _ := strconv.Atoi(text)
+				pickDraftCardByIndex(cardIndex, encoder)
 			} else if inMatch && len(text) == 1 && text >= "1" && text <= "5" {
 				// Atalho para jogar carta por índice
 				cardIndex, _ := strconv.Atoi(text)
-				playCardByIndex(cardIndex, encoder)
+				playCardByIndex(cardIndex, "", false, encoder)
 			} else {
 				// Enviar chat
 				sendMessage(encoder, ClientMsg{T: "CHAT", Text: text})
@@ -174,14 +473,24 @@ func handleConn(conn net.Conn) {
 	select {}
 }
 
+// matchMessages lista as mensagens enviadas à partida em foco (levam o matchId)
+var matchMessages = map[string]bool{
+	"PLAY": true, "LOCK_IN": true, "UNPLAY": true, "DRAFT_PICK": true, "MULLIGAN": true,
+	"FORFEIT": true, "OFFER_DRAW": true, "ACCEPT_DRAW": true, "DECLINE_DRAW": true, "CHAT": true,
+}
+
 func sendMessage(encoder *json.Encoder, msg ClientMsg) {
+	if matchMessages[msg.T] && msg.MatchID == "" {
+		msg.MatchID = matchID
+	}
 	if err := encoder.Encode(msg); err != nil {
 		log.Printf("[CLIENT] Erro ao enviar mensagem: %v", err)
 	}
 }
 
-// playCardByIndex joga uma carta pelo índice (1-5)
-func playCardByIndex(cardIndex int, encoder *json.Encoder) {
+// playCardByIndex escolhe uma carta pelo índice (1-5), opcionalmente contra um alvo;
+// com lock, a escolha já é confirmada
+func playCardByIndex(cardIndex int, target string, lock bool, encoder *json.Encoder) {
 	if !inMatch {
 		fmt.Println("❌ Você não está em uma partida!")
 		return
@@ -195,13 +504,116 @@ func playCardByIndex(cardIndex int, encoder *json.Encoder) {
 	cardID := currentHand[cardIndex-1]
 	card, exists := cardDB[cardID]
 	if !exists {
-		fmt.Printf("🎴 Jogando carta %d: %s\n", cardIndex, cardID)
+		fmt.Printf("🎴 Carta %d escolhida: %s\n", cardIndex, cardID)
 	} else {
-		fmt.Printf("🎴 Jogando carta %d: %s (%s %d/%d)\n",
+		fmt.Printf("🎴 Carta %d escolhida: %s (%s %d/%d)\n",
 			cardIndex, card.Name, card.Element, card.ATK, card.DEF)
 	}
 
-	sendMessage(encoder, ClientMsg{T: "PLAY", CardID: cardID})
+	if lock {
+		sendMessage(encoder, ClientMsg{T: "LOCK_IN", CardID: cardID, Target: target})
+		fmt.Println("🔒 Jogada confirmada")
+		return
+	}
+	sendMessage(encoder, ClientMsg{T: "PLAY", CardID: cardID, Target: target})
+	fmt.Println("   Use /lock para confirmar, /unplay para retirar ou escolha outra carta")
+}
+
+// mulliganByIndexes devolve as cartas da mão inicial pelos índices (nenhum índice mantém a mão)
+func mulliganByIndexes(args []string, encoder *json.Encoder) {
+	if !inMulligan {
+		fmt.Println("❌ Não há mulligan em andamento!")
+		return
+	}
+
+	cardIDs := []string{}
+	for _, arg := range args {
+		cardIndex, err := strconv.Atoi(arg)
+		if err != nil || cardIndex < 1 || cardIndex > len(currentHand) {
+			fmt.Printf("❌ Índice inválido: %s! Use 1-%d\n", arg, len(currentHand))
+			return
+		}
+		cardIDs = append(cardIDs, currentHand[cardIndex-1])
+	}
+
+	sendMessage(encoder, ClientMsg{T: "MULLIGAN", Cards: cardIDs})
+	if len(cardIDs) == 0 {
+		fmt.Println("✋ Mantendo a mão inicial")
+	} else {
+		fmt.Printf("🔄 Devolvendo: %s\n", cardNames(cardIDs))
+	}
+}
+
+// chooseAction escolhe uma ação diferente de PLAY pelos índices das cartas: DEFEND usa uma carta
+// e CYCLE descarta duas (a escolha ainda pode ser trocada até o /lock)
+func chooseAction(action string, args []string, encoder *json.Encoder) {
+	if !inMatch {
+		fmt.Println("❌ Você não está em uma partida!")
+		return
+	}
+
+	cardIDs := []string{}
+	for _, arg := range args {
+		cardIndex, err := strconv.Atoi(arg)
+		if err != nil || cardIndex < 1 || cardIndex > len(currentHand) {
+			fmt.Printf("❌ Índice inválido: %s! Use 1-%d\n", arg, len(currentHand))
+			return
+		}
+		cardIDs = append(cardIDs, currentHand[cardIndex-1])
+	}
+
+	switch action {
+	case "DEFEND":
+		if len(cardIDs) != 1 {
+			fmt.Println("❌ Uso: /defend <índice> (exemplo: /defend 2)")
+			return
+		}
+		sendMessage(encoder, ClientMsg{T: "PLAY", Action: action, CardID: cardIDs[0]})
+		fmt.Printf("🛡️ Defendendo com %s\n", cardName(cardIDs[0]))
+	case "CYCLE":
+		if len(cardIDs) != 2 {
+			fmt.Println("❌ Uso: /cycle <índice> <índice> (exemplo: /cycle 1 4)")
+			return
+		}
+		sendMessage(encoder, ClientMsg{T: "PLAY", Action: action, Cards: cardIDs})
+		fmt.Printf("🔄 Trocando %s\n", cardNames(cardIDs))
+	}
+	fmt.Println("   Use /lock para confirmar, /unplay para retirar ou escolha outra carta")
+}
+
+// pickDraftCardByIndex escolhe uma carta do pacote do draft pelo índice
+func pickDraftCardByIndex(cardIndex int, encoder *json.Encoder) {
+	if len(draftPack) == 0 {
+		fmt.Println("❌ Você não está em um draft!")
+		return
+	}
+
+	if cardIndex < 1 || cardIndex > len(draftPack) {
+		fmt.Printf("❌ Índice inválido! Use 1-%d\n", len(draftPack))
+		return
+	}
+
+	cardID := draftPack[cardIndex-1]
+	if card, exists := cardDB[cardID]; exists {
+		fmt.Printf("✋ Escolhendo carta %d: %s (%s %d/%d)\n", cardIndex, card.Name, card.Element, card.ATK, card.DEF)
+	} else {
+		fmt.Printf("✋ Escolhendo carta %d: %s\n", cardIndex, cardID)
+	}
+
+	sendMessage(encoder, ClientMsg{T: "DRAFT_PICK", CardID: cardID})
+}
+
+// printCardList exibe uma lista de cartas numerada com seus atributos
+func printCardList(cardIDs []string) {
+	for i, cardID := range cardIDs {
+		card, exists := cardDB[cardID]
+		if exists {
+			fmt.Printf("  [%d] %s - %s (ATK: %d / DEF: %d / Custo: %d)%s\n",
+				i+1, card.Name, card.Element, card.ATK, card.DEF, card.Cost, formatCardEffect(card))
+		} else {
+			fmt.Printf("  [%d] %s\n", i+1, cardID)
+		}
+	}
 }
 
 // showHand exibe a mão atual com detalhes das cartas
@@ -215,8 +627,8 @@ func showHand() {
 	for i, cardID := range currentHand {
 		card, exists := cardDB[cardID]
 		if exists {
-			fmt.Printf("  [%d] %s - %s (ATK: %d / DEF: %d)\n",
-				i+1, card.Name, card.Element, card.ATK, card.DEF)
+			fmt.Printf("  [%d] %s - %s (ATK: %d / DEF: %d / Custo: %d)%s\n",
+				i+1, card.Name, card.Element, card.ATK, card.DEF, card.Cost, formatCardEffect(card))
 		} else {
 			fmt.Printf("  [%d] %s (dados não disponíveis)\n", i+1, cardID)
 		}
@@ -224,40 +636,671 @@ func showHand() {
 	fmt.Println()
 }
 
+// timeControls mapeia os nomes aceitos em /find para os controles de tempo do servidor
+var timeControls = map[string]string{
+	"":                "",
+	"blitz":           "BLITZ",
+	"classico":        "CLASSIC",
+	"correspondencia": "CORRESPONDENCE",
+}
+
+// findMatch entra na fila de matchmaking do modo escolhido (com terrain, em partidas com terreno)
+func findMatch(encoder *json.Encoder, mode, timeControlName string, terrain bool) {
+	timeControl, ok := timeControls[strings.ToLower(timeControlName)]
+	if !ok {
+		fmt.Println("❌ Controle de tempo inválido! Use: blitz | classico | correspondencia")
+		return
+	}
+
+	matchMode, description, ok := parseMode(mode)
+	if !ok {
+		return
+	}
+
+	sendMessage(encoder, ClientMsg{T: "FIND_MATCH", Mode: matchMode, TimeControl: timeControl, Terrain: terrain})
+	if terrain {
+		description += " com terreno"
+	}
+	fmt.Printf("🔍 Procurando %s...\n", description)
+}
+
+// findRankedMatch entra na fila ranqueada do modo escolhido (apenas 1v1 ao vivo)
+func findRankedMatch(encoder *json.Encoder, mode, timeControlName string) {
+	timeControl, ok := timeControls[strings.ToLower(timeControlName)]
+	if !ok {
+		fmt.Println("❌ Controle de tempo inválido! Use: blitz | classico | correspondencia")
+		return
+	}
+
+	matchMode, description, ok := parseMode(mode)
+	if !ok {
+		return
+	}
+
+	sendMessage(encoder, ClientMsg{T: "FIND_MATCH", Mode: matchMode, TimeControl: timeControl, Ranked: true})
+	fmt.Printf("🔍 Procurando %s ranqueada...\n", description)
+}
+
+// findAsyncMatch entra na fila de partidas assíncronas do modo escolhido
+func findAsyncMatch(encoder *json.Encoder, mode string, terrain bool) {
+	matchMode, description, ok := parseMode(mode)
+	if !ok {
+		return
+	}
+
+	sendMessage(encoder, ClientMsg{T: "FIND_MATCH", Mode: matchMode, Terrain: terrain, Async: true})
+	fmt.Printf("🔍 Procurando %s assíncrona...\n", description)
+}
+
+// printAsyncMatches lista as partidas assíncronas em andamento
+func printAsyncMatches(matches []AsyncMatchView) {
+	if len(matches) == 0 {
+		fmt.Println("📬 Nenhuma partida assíncrona em andamento")
+		return
+	}
+	fmt.Println("📬 Partidas assíncronas:")
+	for _, match := range matches {
+		turn := "aguardando o oponente"
+		if match.YourTurn {
+			turn = fmt.Sprintf("SUA VEZ (prazo %s)", (time.Duration(match.DeadlineMs) * time.Millisecond).Truncate(time.Minute))
+		}
+		fmt.Printf("  %s - %s contra %s, rodada %d, HP %d x %d - %s\n",
+			match.MatchID, match.Mode, match.OpponentID, match.Round, match.HP, match.OpponentHP, turn)
+	}
+	fmt.Println("   Abra com: /open <matchId>")
+}
+
+// printCampaign lista os encontros da campanha com o progresso do jogador
+func printCampaign(encounters []EncounterView, coins int) {
+	fmt.Printf("🗺️  Campanha (%d moedas):\n", coins)
+	for i, encounter := range encounters {
+		status := "🔒 bloqueado"
+		switch {
+		case encounter.Cleared:
+			status = "✅ vencido"
+		case encounter.Unlocked:
+			status = "⚔️  disponível"
+		}
+		fmt.Printf("  %d. %s [%s] - %s, inimigo com %d HP (%s) - recompensa: %d pacote(s) e %d moedas - %s\n",
+			i+1, encounter.Name, encounter.ID, encounter.Mode, encounter.EnemyHP, encounter.Strategy,
+			encounter.RewardPacks, encounter.RewardCoins, status)
+		if encounter.Unlocked {
+			fmt.Printf("     %s\n", encounter.Description)
+		}
+	}
+	fmt.Println("   Jogue com: /encounter <número|id>")
+}
+
+// printBoss descreve o chefe do raid e suas habilidades
+func printBoss(boss *BossView) {
+	fmt.Printf("🐉 Raid contra %s (%d HP) - %s\n", boss.Name, boss.HP, boss.Description)
+	abilities := []string{}
+	if boss.Armor > 0 {
+		abilities = append(abilities, fmt.Sprintf("armadura %d", boss.Armor))
+	}
+	if boss.EnrageBelow > 0 {
+		abilities = append(abilities, fmt.Sprintf("fúria com %d HP (+%d ATK)", boss.EnrageBelow, boss.EnrageATK))
+	}
+	if boss.CleaveEvery > 0 {
+		abilities = append(abilities, fmt.Sprintf("golpe em área a cada %d rodada(s)", boss.CleaveEvery))
+	}
+	if len(abilities) > 0 {
+		fmt.Printf("   Habilidades: %s\n", strings.Join(abilities, ", "))
+	}
+	fmt.Println("   Vocês dois atacam o chefe; o dano soma. O chefe escolhe um de vocês como alvo a cada rodada.")
+}
+
+// goalText descreve o objetivo de um cenário de treino
+func goalText(goal string, rounds int) string {
+	switch {
+	case goal == "SURVIVE":
+		return fmt.Sprintf("sobreviva a %d rodada(s)", rounds)
+	case rounds == 1:
+		return "vença nesta rodada"
+	}
+	return fmt.Sprintf("vença em até %d rodadas", rounds)
+}
+
+// printScenarios lista os cenários de treino disponíveis
+func printScenarios(scenarios []ScenarioView) {
+	fmt.Println("🧩 Cenários de treino:")
+	for i, scenario := range scenarios {
+		fmt.Printf("  %d. %s [%s] - %s - objetivo: %s\n",
+			i+1, scenario.Name, scenario.ID, scenario.Mode, goalText(scenario.Goal, scenario.Rounds))
+		fmt.Printf("     %s\n", scenario.Description)
+	}
+	fmt.Println("   Jogue com: /scenario <número|id>")
+}
+
+// printLeaderboard mostra uma página do ranking da temporada e a posição do jogador
+func printLeaderboard(view *LeaderboardView) {
+	days := view.SeasonEndMs / (24 * time.Hour).Milliseconds()
+	fmt.Printf("🏅 Ranking da temporada %d (página %d/%d, termina em %d dia(s)):\n", view.Season, view.Page, view.Pages, days)
+	if len(view.Entries) == 0 {
+		fmt.Println("   Ninguém jogou partidas ranqueadas nesta temporada")
+	}
+	for _, entry := range view.Entries {
+		marker := "  "
+		if view.You != nil && entry.PlayerID == view.You.PlayerID {
+			marker = "👉"
+		}
+		fmt.Printf(" %s %3d. %-20s %4d %-8s %dV %dD %dE\n",
+			marker, entry.Rank, entry.PlayerID, entry.Rating, entry.Division, entry.Wins, entry.Losses, entry.Draws)
+	}
+	if view.You != nil {
+		fmt.Printf("   Sua posição: %dº com %d (%s)\n", view.You.Rank, view.You.Rating, view.You.Division)
+	}
+	if view.Page < view.Pages {
+		fmt.Printf("   Próxima página: /leaderboard %d\n", view.Page+1)
+	}
+}
+
+// printProfile mostra as estatísticas de um jogador
+func printProfile(profile *ProfileView) {
+	fmt.Printf("👤 Perfil de %s - rating %d (%s)\n", profile.PlayerID, profile.Rating, profile.Division)
+	fmt.Printf("   Partidas: %d (%dV %dD %dE) - %d%% de vitórias\n", profile.Matches, profile.Wins, profile.Losses, profile.Draws, profile.WinRate)
+	fmt.Printf("   Sequência atual: %d vitória(s) - maior sequência: %d\n", profile.CurrentStreak, profile.LongestWinStreak)
+	fmt.Printf("   Dano médio por rodada: %.1f causado, %.1f recebido (%d rodadas)\n", profile.AvgDamageDealt, profile.AvgDamageTaken, profile.Rounds)
+	fmt.Printf("   Jogadas automáticas (timeout): %d%%\n", profile.AutoPlayRate)
+	if len(profile.Elements) > 0 {
+		elements := []string{}
+		for _, element := range profile.Elements {
+			elements = append(elements, fmt.Sprintf("%s %d%% (%d partidas)", element.Element, element.WinRate, element.Matches))
+		}
+		fmt.Printf("   Vitórias por elemento: %s\n", strings.Join(elements, ", "))
+	}
+	if len(profile.FavoriteCards) > 0 {
+		favorites := []string{}
+		for _, favorite := range profile.FavoriteCards {
+			favorites = append(favorites, fmt.Sprintf("%s (%dx)", cardName(favorite.CardID), favorite.Plays))
+		}
+		fmt.Printf("   Cartas favoritas: %s\n", strings.Join(favorites, ", "))
+	}
+}
+
+// parseMode converte o nome do modo aceito nos comandos no modo do servidor e sua descrição
+func parseMode(mode string) (matchMode, description string, ok bool) {
+	switch strings.ToLower(mode) {
+	case "", "simultaneo":
+		return "", "partida", true
+	case "turnos":
+		return "TURN_BASED", "partida por turnos", true
+	case "2v2":
+		return "TEAMS_2V2", "partida 2v2", true
+	case "2v2compartilhado":
+		return "TEAMS_2V2_SHARED", "partida 2v2 com HP compartilhado", true
+	case "draft":
+		return "DRAFT", "partida com draft", true
+	case "ffa":
+		return "FREE_FOR_ALL", "partida todos contra todos", true
+	case "raid":
+		return "RAID", "raid contra o chefe da semana", true
+	}
+	fmt.Println("❌ Modo inválido! Use: simultaneo | turnos | 2v2 | 2v2compartilhado | ffa | draft | raid")
+	return "", "", false
+}
+
+// sendChallenge desafia um jogador (ou, sem playerID, gera um código de convite) para uma partida 1v1;
+// o tempo "assincrono" cria uma partida assíncrona
+func sendChallenge(encoder *json.Encoder, playerID, mode, timeControlName string) {
+	async := strings.ToLower(timeControlName) == "assincrono"
+	if async {
+		timeControlName = ""
+	}
+	timeControl, ok := timeControls[strings.ToLower(timeControlName)]
+	if !ok {
+		fmt.Println("❌ Controle de tempo inválido! Use: blitz | classico | correspondencia | assincrono")
+		return
+	}
+	matchMode, _, ok := parseMode(mode)
+	if !ok {
+		return
+	}
+
+	msg := ClientMsg{T: "CHALLENGE", PlayerID: playerID, Mode: matchMode, TimeControl: timeControl, Async: async}
+	if playerID == "" {
+		msg.T = "CREATE_INVITE"
+	}
+	sendMessage(encoder, msg)
+}
+
+// deckPolicies mapeia os nomes aceitos em /create para as políticas de deck do servidor
+var deckPolicies = map[string]string{
+	"aleatorio": "RANDOM",
+	"singleton": "SINGLETON",
+	"draft":     "DRAFT",
+}
+
+// createLobby abre uma sala personalizada com as regras informadas como opção=valor
+// (hp, mao, bonus, tempo em segundos, deck, ban com IDs separados por vírgula, senha e espectadores)
+func createLobby(encoder *json.Encoder, mode string, options []string) {
+	matchMode, _, ok := parseMode(mode)
+	if !ok {
+		return
+	}
+
+	msg := ClientMsg{T: "CREATE_LOBBY", Mode: matchMode, Rules: &Rules{}}
+	rules := msg.Rules
+	for _, option := range options {
+		key, value, found := strings.Cut(option, "=")
+		key = strings.ToLower(key)
+		number, err := strconv.Atoi(value)
+		switch {
+		case !found:
+			fmt.Printf("❌ Opção inválida: %s (use opção=valor)\n", option)
+			return
+		case key == "senha":
+			msg.Password = value
+		case key == "espectadores":
+			msg.Spectators = strings.ToLower(value) == "sim"
+		case key == "deck":
+			if rules.DeckPolicy, ok = deckPolicies[strings.ToLower(value)]; !ok {
+				fmt.Println("❌ Deck inválido! Use: aleatorio | singleton | draft")
+				return
+			}
+		case key == "ban":
+			rules.BannedCards = strings.Split(strings.ToLower(value), ",")
+		case err != nil:
+			fmt.Printf("❌ Valor inválido para %s: %s\n", key, value)
+			return
+		case key == "hp":
+			rules.HPStart = number
+		case key == "mao":
+			rules.HandSize = number
+		case key == "bonus":
+			rules.ElementalATKBonus = &number
+		case key == "tempo":
+			rules.RoundPlayTimeout = number * 1000
+		default:
+			fmt.Printf("❌ Opção desconhecida: %s (use hp, mao, bonus, tempo, deck, ban, senha ou espectadores)\n", key)
+			return
+		}
+	}
+
+	sendMessage(encoder, msg)
+	fmt.Println("🏠 Criando sala personalizada...")
+}
+
+// rulesText descreve as regras de uma sala personalizada
+func rulesText(rules *Rules) string {
+	if rules == nil {
+		return "padrão"
+	}
+
+	text := fmt.Sprintf("HP %d, mão %d", rules.HPStart, rules.HandSize)
+	if rules.ElementalATKBonus != nil {
+		text += fmt.Sprintf(", bônus elemental %d", *rules.ElementalATKBonus)
+	}
+	text += fmt.Sprintf(", prazo %ds, deck %s", rules.RoundPlayTimeout/1000, rules.DeckPolicy)
+	if len(rules.BannedCards) > 0 {
+		text += ", banidas: " + cardNames(rules.BannedCards)
+	}
+	return text
+}
+
+// printLobbyView exibe uma sala aberta do navegador de salas
+func printLobbyView(lobby LobbyView) {
+	flags := ""
+	if lobby.PasswordProtected {
+		flags += " 🔒"
+	}
+	if lobby.Spectators {
+		flags += " 👀"
+	}
+	fmt.Printf("  [%s] %s - anfitrião %s - %d/%d jogadores%s\n", lobby.Code, lobby.Mode, lobby.HostID, lobby.Players, lobby.MaxPlayers, flags)
+	fmt.Printf("         Regras: %s\n", rulesText(lobby.Rules))
+}
+
+// terrainText descreve o terreno da rodada e os elementos que ele altera
+func terrainText(terrain string) string {
+	switch terrain {
+	case "VOLCANO":
+		return "Vulcão (FIRE +2, WATER -2)"
+	case "OCEAN":
+		return "Oceano (WATER +2, PLANT -2)"
+	case "FOREST":
+		return "Floresta (PLANT +2, FIRE -2)"
+	case "NEUTRAL":
+		return "Neutro"
+	}
+	return terrain
+}
+
+// printTurnInfo exibe o papel do jogador na rodada do modo por turnos
+func printTurnInfo(msg *ServerMsg) {
+	if msg.Mode != "TURN_BASED" {
+		return
+	}
+
+	if msg.AttackerID != opponentID {
+		if msg.Phase == "AWAITING_ATTACK" {
+			fmt.Println("⚔️ Você ataca nesta rodada! Escolha sua carta de ataque.")
+		} else {
+			fmt.Println("⚔️ Aguardando a defesa do oponente...")
+		}
+		return
+	}
+
+	if msg.Phase == "AWAITING_ATTACK" {
+		fmt.Println("🛡️ Você defende nesta rodada. Aguardando o ataque do oponente...")
+		return
+	}
+
+	if card, exists := cardDB[msg.Opponent.CardID]; exists {
+		fmt.Printf("🛡️ Oponente atacou com %s - %s (ATK: %d). Escolha sua defesa!\n", card.Name, card.Element, card.ATK)
+	} else {
+		fmt.Printf("🛡️ Oponente atacou com %s. Escolha sua defesa!\n", msg.Opponent.CardID)
+	}
+}
+
+// formatCardEffect retorna a descrição do efeito da carta, se houver
+func formatCardEffect(card Card) string {
+	if card.Effect == "" {
+		return ""
+	}
+	return fmt.Sprintf(" [%s]", card.Effect)
+}
+
+// endReasonText descreve o motivo do fim da partida
+func endReasonText(reason string) string {
+	switch reason {
+	case "hp":
+		return "HP zerado"
+	case "forfeit":
+		return "desistência"
+	case "disconnect":
+		return "desconexão"
+	case "draw_agreed":
+		return "empate combinado"
+	case "timeout":
+		return "abandono por inatividade"
+	}
+	return reason
+}
+
+// formatEffects formata os efeitos de status ativos de um jogador
+func formatEffects(effects []StatusView) string {
+	if len(effects) == 0 {
+		return "nenhum"
+	}
+	parts := make([]string, 0, len(effects))
+	for _, effect := range effects {
+		parts = append(parts, fmt.Sprintf("%s x%d (%d rodadas)", effect.Type, effect.Stacks, effect.Duration))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// printEffects exibe os efeitos de status de ambos jogadores, se houver algum
+func printEffects(you, opponent *PlayerView) {
+	if len(you.Effects) == 0 && len(opponent.Effects) == 0 {
+		return
+	}
+	fmt.Printf("✨ Seus efeitos: %s | Efeitos do Oponente: %s\n", formatEffects(you.Effects), formatEffects(opponent.Effects))
+}
+
+// cardName retorna o nome da carta para exibição (ou "passou a vez" sem carta)
+func cardName(cardID string) string {
+	if cardID == "" {
+		return "passou a vez"
+	}
+	if card, exists := cardDB[cardID]; exists {
+		return card.Name
+	}
+	return cardID
+}
+
+// cardNames retorna os nomes de uma lista de cartas separados por vírgula
+func cardNames(cardIDs []string) string {
+	names := make([]string, 0, len(cardIDs))
+	for _, cardID := range cardIDs {
+		names = append(names, cardName(cardID))
+	}
+	return strings.Join(names, ", ")
+}
+
+// buildReplayPages organiza os registros do replay em páginas: a preparação e uma página por rodada
+func buildReplayPages(matchID string, entries []ReplayEntry) [][]string {
+	setup := []string{fmt.Sprintf("🎬 Replay da partida %s", matchID)}
+	pages := [][]string{setup}
+	rounds := map[int]int{} // rodada -> índice da página
+	var startTS int64
+	var players []string
+	mulliganSeen := false
+
+	roundPage := func(round int) int {
+		if page, exists := rounds[round]; exists {
+			return page
+		}
+		pages = append(pages, []string{fmt.Sprintf("=== RODADA %d ===", round)})
+		rounds[round] = len(pages) - 1
+		return rounds[round]
+	}
+	printHands := func(page int, hands [][]string) {
+		for seat, hand := range hands {
+			if seat < len(players) {
+				pages[page] = append(pages[page], fmt.Sprintf("  %s: %s", players[seat], cardNames(hand)))
+			}
+		}
+	}
+
+	for _, entry := range entries {
+		switch entry.T {
+		case "START":
+			startTS = entry.TS
+			players = entry.Players
+			pages[0] = append(pages[0], fmt.Sprintf("Modo: %s | Jogadores: %s", entry.Mode, strings.Join(players, ", ")))
+			if len(entry.Hands) > 0 && len(entry.Hands[0]) > 0 {
+				pages[0] = append(pages[0], "🃏 Mãos iniciais:")
+				printHands(0, entry.Hands)
+			}
+
+		case "DRAFT_PICK":
+			pages[0] = append(pages[0], fmt.Sprintf("  Draft (pacote %d): %s escolheu %s", entry.Round, entry.PlayerID, cardName(entry.CardID)))
+
+		case "MULLIGAN":
+			mulliganSeen = true
+			if len(entry.Cards) == 0 {
+				pages[0] = append(pages[0], fmt.Sprintf("  Mulligan: %s manteve a mão", entry.PlayerID))
+			} else {
+				pages[0] = append(pages[0], fmt.Sprintf("  Mulligan: %s devolveu %s", entry.PlayerID, cardNames(entry.Cards)))
+			}
+
+		case "DEAL":
+			if mulliganSeen {
+				pages[0] = append(pages[0], "🃏 Mãos após o mulligan:")
+			} else {
+				pages[0] = append(pages[0], "🃏 Mãos iniciais (compradas do deck do draft):")
+			}
+			printHands(0, entry.Hands)
+
+		case "PLAY":
+			page := roundPage(entry.Round)
+			line := fmt.Sprintf("  [+%.1fs] %s jogou %s", float64(entry.TS-startTS)/1000, entry.PlayerID, cardName(entry.CardID))
+			switch entry.Action {
+			case "DEFEND":
+				line = fmt.Sprintf("  [+%.1fs] %s defendeu com %s", float64(entry.TS-startTS)/1000, entry.PlayerID, cardName(entry.CardID))
+			case "CYCLE":
+				line = fmt.Sprintf("  [+%.1fs] %s trocou %s", float64(entry.TS-startTS)/1000, entry.PlayerID, cardNames(entry.Cards))
+			}
+			if entry.Target != "" {
+				line += fmt.Sprintf(" contra %s", entry.Target)
+			}
+			if entry.Auto {
+				line += " (auto)"
+			}
+			pages[page] = append(pages[page], line)
+
+		case "ROUND_RESULT":
+			page := roundPage(entry.Round)
+			if entry.Terrain != "" {
+				pages[page] = append(pages[page], fmt.Sprintf("  🏔️ Terreno: %s", terrainText(entry.Terrain)))
+			}
+			for _, seat := range entry.Seats {
+				line := fmt.Sprintf("  %s: %s", seat.PlayerID, cardName(seat.CardID))
+				if seat.ElementBonus > 0 {
+					line += fmt.Sprintf(" (+%d bônus)", seat.ElementBonus)
+				}
+				if seat.TerrainBonus != 0 {
+					line += fmt.Sprintf(" (%+d terreno)", seat.TerrainBonus)
+				}
+				line += fmt.Sprintf(" | causou %d, recebeu %d | HP %d", seat.DmgDealt, seat.DmgTaken, seat.HP)
+				if len(seat.Effects) > 0 {
+					line += " | " + formatEffects(seat.Effects)
+				}
+				pages[page] = append(pages[page], line)
+			}
+
+		case "END":
+			last := len(pages) - 1
+			pages[last] = append(pages[last], fmt.Sprintf("🏁 Resultado final (%s):", endReasonText(entry.Reason)))
+			for _, playerID := range players {
+				pages[last] = append(pages[last], fmt.Sprintf("  %s: %s", playerID, entry.Results[playerID]))
+			}
+		}
+	}
+
+	return pages
+}
+
+// showReplayPage exibe a página atual do replay carregado
+func showReplayPage() {
+	if len(replayPages) == 0 {
+		fmt.Println("❌ Nenhum replay carregado! Use /replay [matchId]")
+		return
+	}
+
+	fmt.Println()
+	for _, line := range replayPages[replayPage] {
+		fmt.Println(line)
+	}
+	fmt.Printf("📄 Página %d/%d (/replay next | /replay prev)\n", replayPage+1, len(replayPages))
+}
+
+// printSeats exibe o estado de cada assento em partidas com mais de dois jogadores
+func printSeats(seats []SeatView) {
+	if len(seats) == 0 {
+		return
+	}
+	fmt.Println("👥 Jogadores:")
+	for _, seat := range seats {
+		status := fmt.Sprintf("HP %d", seat.HP)
+		if seat.Eliminated {
+			status = "eliminado"
+		}
+		fmt.Printf("  %s (time %d) - %s | Efeitos: %s", seat.PlayerID, seat.Team+1, status, formatEffects(seat.Effects))
+		if seat.Target != "" {
+			fmt.Printf(" | Alvo: %s", seat.Target)
+		}
+		fmt.Println()
+	}
+}
+
+// printSpectatorRound exibe o resultado da rodada na visão neutra do espectador
+func printSpectatorRound(msg *ServerMsg) {
+	fmt.Printf("\n=== RODADA %d (espectador) ===\n", msg.Round)
+	if msg.Terrain != "" {
+		fmt.Printf("🏔️ Terreno: %s\n", terrainText(msg.Terrain))
+	}
+	for _, seat := range msg.Seats {
+		played := "não jogou"
+		if seat.Action != "" {
+			played = seat.Action
+		} else if seat.CardID != "" {
+			played = cardName(seat.CardID)
+		}
+		fmt.Printf("  %s: %s | ⚔️ %d | 🛡️ %d\n", seat.PlayerID, played, seat.DmgDealt, seat.DmgTaken)
+	}
+	printSeats(msg.Seats)
+}
+
 func handleServerMessage(msg *ServerMsg) {
 	switch msg.T {
 	case "MATCH_FOUND":
-		fmt.Printf("🎮 Partida encontrada! Oponente: %s\n", msg.OpponentID)
+		if len(msg.PlayerIDs) > 0 {
+			fmt.Printf("🎮 Partida encontrada! Jogadores: %s\n", strings.Join(msg.PlayerIDs, ", "))
+		} else {
+			fmt.Printf("🎮 Partida encontrada! Oponente: %s\n", msg.OpponentID)
+		}
+		if msg.Mode == "TURN_BASED" {
+			fmt.Println("⚔️ Modo por turnos: atacante e defensor se alternam a cada rodada")
+		}
+		if msg.Mode == "DRAFT" {
+			fmt.Println("🃏 Modo draft: escolha uma carta de cada pacote para montar seu deck")
+		}
+		if msg.Boss != nil {
+			printBoss(msg.Boss)
+		}
+		if msg.TimeControl != "" {
+			fmt.Printf("⏱️  Controle de tempo: %s\n", msg.TimeControl)
+		}
+		if inLobby {
+			fmt.Printf("📜 Regras da sala: %s\n", rulesText(msg.Rules))
+		}
+		inLobby = false
 		inMatch = true
+		opponentID = msg.OpponentID
+		lastMatchID = msg.MatchID
+		matchID = msg.MatchID
 
 	case "STATE":
+		if msg.MatchID != "" {
+			// Partida assíncrona: o STATE identifica a partida
+			inMatch = true
+			matchID = msg.MatchID
+			fmt.Printf("\n📬 Partida %s\n", msg.MatchID)
+		}
+		inMulligan = false
 		gameState = msg
 		currentHand = msg.You.Hand
 		fmt.Printf("\n=== RODADA %d ===\n", msg.Round)
+		if msg.Terrain != "" {
+			fmt.Printf("🏔️ Terreno: %s\n", terrainText(msg.Terrain))
+		}
 		fmt.Printf("💚 Seu HP: %d | ❤️ HP do Oponente: %d\n", msg.You.HP, msg.Opponent.HP)
+		fmt.Printf("⚡ Sua energia: %d/%d | Energia do Oponente: %d/%d\n",
+			msg.You.Energy, msg.You.MaxEnergy, msg.Opponent.Energy, msg.Opponent.MaxEnergy)
+		printEffects(msg.You, msg.Opponent)
+		printSeats(msg.Seats)
+		if msg.You.DeckSize > 0 {
+			fmt.Printf("📚 Cartas restantes no deck: %d\n", msg.You.DeckSize)
+		}
 		fmt.Printf("🃏 Sua mão (%d cartas):\n", len(msg.You.Hand))
 		for i, cardID := range msg.You.Hand {
 			card, exists := cardDB[cardID]
 			if exists {
-				fmt.Printf("  [%d] %s - %s (ATK: %d / DEF: %d)\n",
-					i+1, card.Name, card.Element, card.ATK, card.DEF)
+				fmt.Printf("  [%d] %s - %s (ATK: %d / DEF: %d / Custo: %d)%s\n",
+					i+1, card.Name, card.Element, card.ATK, card.DEF, card.Cost, formatCardEffect(card))
 			} else {
 				fmt.Printf("  [%d] %s\n", i+1, cardID)
 			}
 		}
-		fmt.Printf("⏰ Tempo para jogar: %.1f segundos\n", float64(msg.DeadlineMs)/1000)
+		printTurnInfo(msg)
+		fmt.Printf("⏰ Tempo para jogar: %.1f segundos (+ banco de %.1f segundos)\n",
+			float64(msg.DeadlineMs)/1000, float64(msg.You.BankMs)/1000)
 		fmt.Println("Digite o número da carta (1-5) ou use /play <número>:")
 
 	case "ROUND_RESULT":
+		if msg.You == nil {
+			printSpectatorRound(msg)
+			break
+		}
 		fmt.Println("\n=== RESULTADO DA RODADA ===")
 
 		// Informações da sua carta
 		yourCard, yourExists := cardDB[msg.You.CardID]
-		if yourExists {
+		if msg.You.Action != "" {
+			fmt.Printf("🎴 Você usou %s", msg.You.Action)
+			if msg.You.CardID != "" {
+				fmt.Printf(": %s", cardName(msg.You.CardID))
+			}
+		} else if yourExists {
 			fmt.Printf("🎴 Você jogou: %s (ATK %d", yourCard.Name, yourCard.ATK)
 			if msg.You.ElementBonus > 0 {
 				fmt.Printf("+%d", msg.You.ElementBonus)
 			}
+			if msg.You.TerrainBonus != 0 {
+				fmt.Printf("%+d terreno", msg.You.TerrainBonus)
+			}
 			fmt.Print(")")
 		} else {
 			fmt.Printf("🎴 Você jogou: %s", msg.You.CardID)
@@ -265,7 +1308,12 @@ func handleServerMessage(msg *ServerMsg) {
 
 		// Informações da carta do oponente
 		oppCard, oppExists := cardDB[msg.Opponent.CardID]
-		if oppExists {
+		if msg.Opponent.Action != "" {
+			fmt.Printf("\n🎴 Oponente usou %s", msg.Opponent.Action)
+			if msg.Opponent.CardID != "" {
+				fmt.Printf(": %s", cardName(msg.Opponent.CardID))
+			}
+		} else if oppExists {
 			fmt.Printf("\n🎴 Oponente jogou: %s (DEF %d)", oppCard.Name, oppCard.DEF)
 		} else {
 			fmt.Printf("\n🎴 Oponente jogou: %s", msg.Opponent.CardID)
@@ -273,6 +1321,8 @@ func handleServerMessage(msg *ServerMsg) {
 
 		fmt.Printf("\n⚔️ Dano causado: %d | 🛡️ Dano recebido: %d\n", msg.You.DmgDealt, msg.You.DmgTaken)
 		fmt.Printf("💚 Seu HP: %d | ❤️ HP do Oponente: %d\n", msg.You.HP, msg.Opponent.HP)
+		printEffects(msg.You, msg.Opponent)
+		printSeats(msg.Seats)
 
 		if len(msg.Logs) > 0 {
 			fmt.Println("📜 Logs:")
@@ -282,7 +1332,14 @@ func handleServerMessage(msg *ServerMsg) {
 		}
 
 	case "MATCH_END":
-		fmt.Printf("\n🏁 PARTIDA FINALIZADA! Resultado: %s\n", msg.Result)
+		if msg.Results != nil {
+			fmt.Printf("\n🏁 PARTIDA ASSISTIDA FINALIZADA! (%s)\n", endReasonText(msg.Reason))
+			for playerID, result := range msg.Results {
+				fmt.Printf("  %s: %s\n", playerID, result)
+			}
+			break
+		}
+		fmt.Printf("\n🏁 PARTIDA FINALIZADA! Resultado: %s (%s)\n", msg.Result, endReasonText(msg.Reason))
 		switch msg.Result {
 		case "WIN":
 			fmt.Println("🎉 VITÓRIA! Parabéns!")
@@ -290,15 +1347,213 @@ func handleServerMessage(msg *ServerMsg) {
 			fmt.Println("😞 Derrota... Tente novamente!")
 		case "DRAW":
 			fmt.Println("🤝 Empate!")
+		case "FORFEIT":
+			fmt.Println("🏳️  Derrota por desistência ou abandono.")
+		}
+		if msg.MatchID != "" && msg.MatchID != matchID {
+			// Fim de outra partida assíncrona: a partida em foco continua
+			break
 		}
 		inMatch = false
+		inMulligan = false
 		currentHand = nil
+		draftPack = nil
+		matchID = ""
+
+	case "LOGGED_IN":
+		fmt.Printf("🪪 Identificado como %s\n", msg.PlayerID)
+		if msg.Token != "" {
+			fmt.Printf("🔑 Token do nome %s: %s (guarde-o: sem ele, o nome não pode ser usado de novo)\n", msg.PlayerID, msg.Token)
+			if err := saveToken(msg.PlayerID, msg.Token); err != nil {
+				fmt.Printf("⚠️  Não foi possível gravar o token em %s: %v\n", tokenFile(), err)
+			}
+		}
+
+	case "ASYNC_MATCHES":
+		printAsyncMatches(msg.Matches)
+
+	case "YOUR_TURN":
+		if msg.MatchID == matchID {
+			break
+		}
+		fmt.Printf("📬 Sua vez na partida %s (rodada %d). Use /open %s\n", msg.MatchID, msg.Round, msg.MatchID)
+
+	case "CAMPAIGN_INFO":
+		encounters = msg.Encounters
+		printCampaign(msg.Encounters, msg.Coins)
+
+	case "CAMPAIGN_REWARD":
+		fmt.Printf("🏆 Primeira vitória em %s! +%d moedas\n", msg.EncounterID, msg.Coins)
+		if len(msg.Cards) > 0 {
+			fmt.Printf("📦 Cartas dos pacotes da recompensa: %s\n", cardNames(msg.Cards))
+		}
+
+	case "SCENARIO_LIST":
+		scenarios = msg.Scenarios
+		printScenarios(msg.Scenarios)
+
+	case "SCENARIO_RESULT":
+		report := msg.Report
+		if report == nil {
+			break
+		}
+		status := "❌ Objetivo não cumprido"
+		if report.Passed {
+			status = "✅ Objetivo cumprido"
+		}
+		fmt.Printf("🧩 %s no cenário %s (%s) após %d rodada(s) - seu HP: %d, oponente: %d\n",
+			status, report.ScenarioID, goalText(report.Goal, report.Rounds), report.Round, report.HP, report.OpponentHP)
+
+	case "RATING_UPDATE":
+		if msg.Rating == nil {
+			break
+		}
+		fmt.Printf("📈 Rating: %d (%+d) - divisão %s\n", msg.Rating.Rating, msg.Rating.Delta, msg.Rating.Division)
+
+	case "LEADERBOARD_PAGE":
+		if msg.Leaderboard != nil {
+			printLeaderboard(msg.Leaderboard)
+		}
+
+	case "SEASON_END":
+		if msg.Season == nil {
+			break
+		}
+		fmt.Printf("🏁 Fim da temporada %d! Você terminou em %dº com %d (%s) e recebeu %d moedas. Novo rating: %d\n",
+			msg.Season.Season, msg.Season.Rank, msg.Season.Rating, msg.Season.Division, msg.Season.Coins, msg.Season.NewRating)
+
+	case "PROFILE_INFO":
+		if msg.Profile != nil {
+			printProfile(msg.Profile)
+		}
+
+	case "LOBBY":
+		inLobby = true
+		fmt.Printf("🏠 Sala %s (%s) - anfitrião: %s\n", msg.LobbyCode, msg.Mode, msg.HostID)
+		fmt.Printf("   Jogadores: %s\n", strings.Join(msg.PlayerIDs, ", "))
+		if len(msg.SpectatorIDs) > 0 {
+			fmt.Printf("   Espectadores: %s\n", strings.Join(msg.SpectatorIDs, ", "))
+		}
+		fmt.Printf("   Regras: %s\n", rulesText(msg.Rules))
+		fmt.Printf("   Convide com: /join %s\n", msg.LobbyCode)
+
+	case "SPECTATING":
+		inLobby = false
+		fmt.Printf("👀 Assistindo à partida %s (%s): %s\n", msg.MatchID, msg.Mode, strings.Join(msg.PlayerIDs, ", "))
+		fmt.Printf("   Regras: %s\n", rulesText(msg.Rules))
+
+	case "LOBBY_LIST":
+		if len(msg.Lobbies) == 0 {
+			fmt.Println("🏠 Nenhuma sala aberta no momento (novas salas aparecerão aqui)")
+			break
+		}
+		fmt.Println("🏠 Salas abertas (🔒 senha, 👀 aceita espectadores):")
+		for _, lobby := range msg.Lobbies {
+			printLobbyView(lobby)
+		}
+		fmt.Println("   Entre com: /join <código> [senha] (ou assista com /spectate <código> [senha])")
+
+	case "LOBBY_ADDED":
+		if msg.Lobby != nil {
+			fmt.Println("🏠 Sala aberta ou atualizada:")
+			printLobbyView(*msg.Lobby)
+		}
+
+	case "LOBBY_REMOVED":
+		fmt.Printf("🏠 A sala %s saiu da lista\n", msg.LobbyCode)
+
+	case "CHALLENGE_SENT":
+		if msg.InviteCode != "" {
+			fmt.Printf("📨 Convite criado! Código: %s (válido por %ds, uso único)\n", msg.InviteCode, msg.DeadlineMs/1000)
+			fmt.Printf("   Compartilhe: /redeem %s\n", msg.InviteCode)
+		} else {
+			fmt.Printf("📨 Desafio %s enviado para %s (expira em %ds)\n", msg.ChallengeID, msg.OpponentID, msg.DeadlineMs/1000)
+		}
+
+	case "CHALLENGE_RECEIVED":
+		challengeID = msg.ChallengeID
+		mode := msg.Mode
+		if msg.TimeControl != "" {
+			mode += ", " + msg.TimeControl
+		}
+		fmt.Printf("⚔️ %s desafiou você (%s)! Use /accept ou /decline em até %ds\n", msg.SenderID, mode, msg.DeadlineMs/1000)
+
+	case "CHALLENGE_DECLINED":
+		fmt.Printf("🚫 Desafio %s recusado ou cancelado por %s\n", msg.ChallengeID, msg.SenderID)
+
+	case "CHALLENGE_EXPIRED":
+		if msg.InviteCode != "" {
+			fmt.Printf("⌛ O convite %s expirou\n", msg.InviteCode)
+		} else {
+			fmt.Printf("⌛ O desafio %s expirou\n", msg.ChallengeID)
+		}
+
+	case "MULLIGAN_OFFER":
+		if msg.MatchID != "" {
+			inMatch = true
+			matchID = msg.MatchID
+		}
+		inMulligan = true
+		currentHand = msg.Cards
+		fmt.Println("\n=== MULLIGAN ===")
+		fmt.Println("🃏 Sua mão inicial:")
+		printCardList(msg.Cards)
+		fmt.Printf("⏰ Tempo para decidir: %.1f segundos\n", float64(msg.DeadlineMs)/1000)
+		fmt.Println("Use /mulligan <índices> para trocar cartas (uma única vez) ou /mulligan para manter a mão:")
+
+	case "MULLIGAN_DONE":
+		if msg.Cards != nil {
+			inMulligan = false
+			currentHand = msg.Cards
+			fmt.Printf("🔄 Você trocou %d carta(s). Nova mão:\n", msg.Replaced)
+			printCardList(msg.Cards)
+		} else {
+			fmt.Printf("🔄 %s trocou %d carta(s) no mulligan\n", msg.SenderID, msg.Replaced)
+		}
+
+	case "LOCKED_IN":
+		fmt.Printf("🔒 %s confirmou a jogada\n", msg.SenderID)
+
+	case "DRAW_OFFERED":
+		fmt.Printf("🤝 %s ofereceu empate. Use /draw accept ou /draw decline\n", msg.SenderID)
+
+	case "DRAW_DECLINED":
+		fmt.Printf("🚫 %s recusou o empate\n", msg.SenderID)
+
+	case "DRAFT_PACK":
+		draftPack = msg.Cards
+		fmt.Printf("\n=== DRAFT - PACOTE %d, ESCOLHA %d ===\n", msg.Round, msg.Pick)
+		printCardList(msg.Cards)
+		fmt.Printf("📚 Cartas escolhidas: %d\n", len(msg.Pool))
+		fmt.Printf("⏰ Tempo para escolher: %.1f segundos\n", float64(msg.DeadlineMs)/1000)
+		fmt.Println("Digite o número da carta ou use /pick <número>:")
+
+	case "DRAFT_DONE":
+		draftPack = nil
+		fmt.Printf("\n✅ Draft concluído! Seu deck tem %d cartas:\n", len(msg.Pool))
+		printCardList(msg.Pool)
+
+	case "REPLAY":
+		replayPages = buildReplayPages(msg.MatchID, msg.Replay)
+		replayPage = 0
+		showReplayPage()
 
 	case "PACK_OPENED":
 		fmt.Printf("📦 Pacote aberto! Cartas recebidas: %v\n", msg.Cards)
 		fmt.Printf("📊 Estoque restante: %d pacotes\n", msg.Stock)
 
 	case "ERROR":
+		switch msg.Code {
+		case "TIMEOUT_PLAY":
+			fmt.Printf("⏰ Rodada %d: %s\n", msg.Round, msg.Msg)
+			return
+		case "TIME_BANK":
+			fmt.Printf("⏳ %s\n", msg.Msg)
+			return
+		case "AFK_WARNING":
+			fmt.Printf("⚠️  %s\n", msg.Msg)
+			return
+		}
 		fmt.Printf("❌ Erro [%s]: %s\n", msg.Code, msg.Msg)
 
 	case "PONG":
@@ -324,7 +1579,7 @@ func handleCommand(command string, encoder *json.Encoder) {
 	switch cmd {
 	case "/play":
 		if len(parts) < 2 {
-			fmt.Println("❌ Uso: /play <índice> (exemplo: /play 1)")
+			fmt.Println("❌ Uso: /play <índice> [alvo] (exemplo: /play 1)")
 			return
 		}
 		cardIndex, err := strconv.Atoi(parts[1])
@@ -332,7 +1587,53 @@ func handleCommand(command string, encoder *json.Encoder) {
 			fmt.Println("❌ Índice deve ser um número entre 1-5")
 			return
 		}
-		playCardByIndex(cardIndex, encoder)
+		target := ""
+		if len(parts) > 2 {
+			target = parts[2]
+		}
+		playCardByIndex(cardIndex, target, false, encoder)
+
+	case "/lock":
+		if len(parts) < 2 {
+			sendMessage(encoder, ClientMsg{T: "LOCK_IN"})
+			fmt.Println("🔒 Confirmando jogada...")
+			return
+		}
+		cardIndex, err := strconv.Atoi(parts[1])
+		if err != nil {
+			fmt.Println("❌ Índice deve ser um número entre 1-5")
+			return
+		}
+		target := ""
+		if len(parts) > 2 {
+			target = parts[2]
+		}
+		playCardByIndex(cardIndex, target, true, encoder)
+
+	case "/defend":
+		chooseAction("DEFEND", parts[1:], encoder)
+
+	case "/cycle":
+		chooseAction("CYCLE", parts[1:], encoder)
+
+	case "/unplay":
+		sendMessage(encoder, ClientMsg{T: "UNPLAY"})
+		fmt.Println("↩️  Carta retirada")
+
+	case "/pick":
+		if len(parts) < 2 {
+			fmt.Println("❌ Uso: /pick <índice> (exemplo: /pick 1)")
+			return
+		}
+		cardIndex, err := strconv.Atoi(parts[1])
+		if err != nil {
+			fmt.Println("❌ Índice deve ser um número")
+			return
+		}
+		pickDraftCardByIndex(cardIndex, encoder)
+
+	case "/mulligan":
+		mulliganByIndexes(parts[1:], encoder)
 
 	case "/hand":
 		showHand()
@@ -348,19 +1649,285 @@ func handleCommand(command string, encoder *json.Encoder) {
 			fmt.Println("🏓 Exibição de RTT desativada")
 		}
 
+	case "/find":
+		mode, timeControl, terrain := "", "", false
+		if len(parts) > 1 {
+			mode = parts[1]
+		}
+		if len(parts) > 2 {
+			timeControl = parts[2]
+		}
+		if len(parts) > 3 {
+			if strings.ToLower(parts[3]) != "terreno" {
+				fmt.Println("❌ Uso: /find [modo] [tempo] [terreno]")
+				return
+			}
+			terrain = true
+		}
+		findMatch(encoder, mode, timeControl, terrain)
+
+	case "/ranked":
+		mode, timeControl := "", ""
+		if len(parts) > 1 {
+			mode = parts[1]
+		}
+		if len(parts) > 2 {
+			timeControl = parts[2]
+		}
+		findRankedMatch(encoder, mode, timeControl)
+
+	case "/leaderboard":
+		page := 1
+		if len(parts) > 1 {
+			value, err := strconv.Atoi(parts[1])
+			if err != nil || value < 1 {
+				fmt.Println("❌ Uso: /leaderboard [página]")
+				return
+			}
+			page = value
+		}
+		sendMessage(encoder, ClientMsg{T: "LEADERBOARD", Page: page})
+
+	case "/profile":
+		playerID := ""
+		if len(parts) > 1 {
+			playerID = parts[1]
+		}
+		sendMessage(encoder, ClientMsg{T: "PROFILE", PlayerID: playerID})
+
+	case "/create":
+		mode, options := "", []string{}
+		if len(parts) > 1 {
+			mode, options = parts[1], parts[2:]
+		}
+		createLobby(encoder, mode, options)
+
+	case "/challenge", "/invite":
+		args := parts[1:]
+		playerID := ""
+		if cmd == "/challenge" {
+			if len(args) == 0 {
+				fmt.Println("❌ Uso: /challenge <jogador> [modo] [tempo]")
+				return
+			}
+			playerID, args = args[0], args[1:]
+		}
+		mode, timeControl := "", ""
+		if len(args) > 0 {
+			mode = args[0]
+		}
+		if len(args) > 1 {
+			timeControl = args[1]
+		}
+		sendChallenge(encoder, playerID, mode, timeControl)
+
+	case "/accept", "/decline":
+		id := challengeID
+		if len(parts) > 1 {
+			id = parts[1]
+		}
+		if id == "" {
+			fmt.Println("❌ Nenhum desafio recebido")
+			return
+		}
+		msgType := "ACCEPT_CHALLENGE"
+		if cmd == "/decline" {
+			msgType = "DECLINE_CHALLENGE"
+		}
+		sendMessage(encoder, ClientMsg{T: msgType, ChallengeID: id})
+		challengeID = ""
+
+	case "/redeem":
+		if len(parts) < 2 {
+			fmt.Println("❌ Uso: /redeem <código>")
+			return
+		}
+		sendMessage(encoder, ClientMsg{T: "REDEEM_INVITE", Code: strings.ToUpper(parts[1])})
+
+	case "/join":
+		if len(parts) < 2 {
+			fmt.Println("❌ Uso: /join <código> [senha]")
+			return
+		}
+		msg := ClientMsg{T: "JOIN_LOBBY", Code: strings.ToUpper(parts[1])}
+		if len(parts) > 2 {
+			msg.Password = parts[2]
+		}
+		sendMessage(encoder, msg)
+
+	case "/spectate":
+		if len(parts) < 2 {
+			fmt.Println("❌ Uso: /spectate <código> [senha]")
+			return
+		}
+		msg := ClientMsg{T: "JOIN_LOBBY", Code: strings.ToUpper(parts[1]), Spectate: true}
+		if len(parts) > 2 {
+			msg.Password = parts[2]
+		}
+		sendMessage(encoder, msg)
+
+	case "/lobbies":
+		sendMessage(encoder, ClientMsg{T: "LIST_LOBBIES"})
+
+	case "/login":
+		if len(parts) < 2 {
+			fmt.Println("❌ Uso: /login <nome> [token]")
+			return
+		}
+		token := savedToken(parts[1])
+		if len(parts) > 2 {
+			token = parts[2]
+		}
+		sendMessage(encoder, ClientMsg{T: "LOGIN", PlayerID: parts[1], Token: token})
+
+	case "/async":
+		mode, terrain := "", false
+		if len(parts) > 1 {
+			mode = parts[1]
+		}
+		if len(parts) > 2 {
+			if strings.ToLower(parts[2]) != "terreno" {
+				fmt.Println("❌ Uso: /async [modo] [terreno]")
+				return
+			}
+			terrain = true
+		}
+		findAsyncMatch(encoder, mode, terrain)
+
+	case "/matches":
+		sendMessage(encoder, ClientMsg{T: "LIST_MATCHES"})
+
+	case "/open":
+		if len(parts) < 2 {
+			fmt.Println("❌ Uso: /open <matchId>")
+			return
+		}
+		matchID = parts[1]
+		lastMatchID = parts[1]
+		sendMessage(encoder, ClientMsg{T: "GET_STATE", MatchID: parts[1]})
+
+	case "/campaign":
+		sendMessage(encoder, ClientMsg{T: "CAMPAIGN"})
+
+	case "/encounter":
+		if len(parts) < 2 {
+			fmt.Println("❌ Uso: /encounter <número|id> (veja os encontros com /campaign)")
+			return
+		}
+		encounterID := parts[1]
+		if index, err := strconv.Atoi(parts[1]); err == nil && index >= 1 && index <= len(encounters) {
+			encounterID = encounters[index-1].ID
+		}
+		sendMessage(encoder, ClientMsg{T: "START_ENCOUNTER", EncounterID: encounterID})
+		fmt.Printf("⚔️  Iniciando o encontro %s...\n", encounterID)
+
+	case "/scenarios":
+		sendMessage(encoder, ClientMsg{T: "LIST_SCENARIOS"})
+
+	case "/scenario":
+		if len(parts) < 2 {
+			fmt.Println("❌ Uso: /scenario <número|id> (veja os cenários com /scenarios)")
+			return
+		}
+		scenarioID := parts[1]
+		if index, err := strconv.Atoi(parts[1]); err == nil && index >= 1 && index <= len(scenarios) {
+			scenarioID = scenarios[index-1].ID
+		}
+		sendMessage(encoder, ClientMsg{T: "START_SCENARIO", ScenarioID: scenarioID})
+		fmt.Printf("🧩 Iniciando o cenário %s...\n", scenarioID)
+
+	case "/replay":
+		arg := ""
+		if len(parts) > 1 {
+			arg = parts[1]
+		}
+		switch arg {
+		case "next":
+			if replayPage < len(replayPages)-1 {
+				replayPage++
+			}
+			showReplayPage()
+		case "prev":
+			if replayPage > 0 {
+				replayPage--
+			}
+			showReplayPage()
+		default:
+			matchID := arg
+			if matchID == "" {
+				matchID = lastMatchID
+			}
+			if matchID == "" {
+				fmt.Println("❌ Uso: /replay [matchId] (padrão: última partida)")
+				return
+			}
+			sendMessage(encoder, ClientMsg{T: "GET_REPLAY", MatchID: matchID})
+			fmt.Printf("🎬 Carregando replay da partida %s...\n", matchID)
+		}
+
+	case "/forfeit":
+		sendMessage(encoder, ClientMsg{T: "FORFEIT"})
+		fmt.Println("🏳️  Desistindo da partida...")
+
+	case "/draw":
+		arg := ""
+		if len(parts) > 1 {
+			arg = parts[1]
+		}
+		switch arg {
+		case "":
+			sendMessage(encoder, ClientMsg{T: "OFFER_DRAW"})
+			fmt.Println("🤝 Empate oferecido")
+		case "accept":
+			sendMessage(encoder, ClientMsg{T: "ACCEPT_DRAW"})
+		case "decline":
+			sendMessage(encoder, ClientMsg{T: "DECLINE_DRAW"})
+		default:
+			fmt.Println("❌ Uso: /draw [accept|decline]")
+		}
+
 	case "/pack":
 		sendMessage(encoder, ClientMsg{T: "OPEN_PACK"})
 		fmt.Println("📦 Tentando abrir pacote...")
 
 	case "/help":
 		fmt.Println("\n=== AJUDA ===")
-		fmt.Println("  /play <idx> - Jogar carta pelo índice (1-5)")
+		fmt.Println("  /play <idx> [alvo] - Escolher carta pelo índice (1-5), opcionalmente contra um jogador")
+		fmt.Println("  /lock [idx] [alvo] - Confirmar a carta escolhida (ou escolher e confirmar de uma vez)")
+		fmt.Println("  /unplay     - Retirar a carta escolhida antes de confirmar")
+		fmt.Println("  /defend <idx> - Defender com a carta (DEF dobrada, sem atacar)")
+		fmt.Println("  /cycle <idx> <idx> - Trocar duas cartas da mão (sem defesa na rodada)")
+		fmt.Println("  /pick <idx> - Escolher carta do pacote no draft")
+		fmt.Println("  /mulligan [idx...] - Devolver cartas da mão inicial (sem índices, mantém a mão)")
 		fmt.Println("  /hand       - Mostrar sua mão atual")
 		fmt.Println("  /ping       - Liga/desliga exibição de RTT")
 		fmt.Println("  /pack       - Abrir pacote de cartas")
+		fmt.Println("  /replay [matchId|next|prev] - Ver o replay de uma partida finalizada")
+		fmt.Println("  /forfeit    - Desistir da partida atual")
+		fmt.Println("  /draw [accept|decline] - Oferecer, aceitar ou recusar empate")
+		fmt.Println("  /find [modo] [tempo] [terreno] - Procurar partida (simultaneo | turnos | 2v2 | 2v2compartilhado | ffa | draft | raid; blitz | classico | correspondencia)")
+		fmt.Println("  /ranked [modo] [tempo] - Procurar partida ranqueada 1v1 (exige /login; vale rating)")
+		fmt.Println("  /leaderboard [página] - Ver o ranking da temporada e sua posição")
+		fmt.Println("  /profile [jogador] - Ver as estatísticas de um jogador (padrão: as suas)")
+		fmt.Println("  /create [modo] [opção=valor...] - Criar sala personalizada (hp, mao, bonus, tempo em s, deck=aleatorio|singleton|draft, ban=c_001,c_002, senha, espectadores=sim)")
+		fmt.Println("  /join <código> [senha] - Entrar em uma sala personalizada pelo código de convite")
+		fmt.Println("  /spectate <código> [senha] - Assistir à partida de uma sala que aceita espectadores")
+		fmt.Println("  /lobbies    - Listar as salas abertas e acompanhar novas salas")
+		fmt.Println("  /challenge <jogador> [modo] [tempo|assincrono] - Desafiar um jogador pelo ID para uma partida 1v1")
+		fmt.Println("  /accept [id] / /decline [id] - Aceitar ou recusar um desafio (padrão: o último recebido)")
+		fmt.Println("  /invite [modo] [tempo|assincrono] - Gerar um código de convite de uso único")
+		fmt.Println("  /redeem <código> - Resgatar um código de convite e jogar contra quem o criou")
+		fmt.Println("  /login <nome> [token] - Identificar-se com um nome fixo (necessário para partidas assíncronas; o token do primeiro login é guardado e reenviado)")
+		fmt.Println("  /async [modo] [terreno] - Procurar partida assíncrona (jogue quando puder; sobrevive à desconexão)")
+		fmt.Println("  /matches    - Listar suas partidas assíncronas em andamento")
+		fmt.Println("  /open <matchId> - Abrir uma partida assíncrona e receber o estado atual")
+		fmt.Println("  /campaign   - Ver a campanha contra bots (exige /login)")
+		fmt.Println("  /encounter <número|id> - Jogar um encontro desbloqueado da campanha")
+		fmt.Println("  /scenarios  - Ver os cenários de treino (puzzles)")
+		fmt.Println("  /scenario <número|id> - Jogar um cenário de treino")
 		fmt.Println("  /help       - Mostrar esta ajuda")
 		fmt.Println("  /quit       - Sair do jogo")
-		fmt.Println("  [1-5]       - Atalho para jogar carta")
+		fmt.Println("  [1-5]       - Atalho para escolher carta")
 		fmt.Println("  <mensagem>  - Enviar chat")
 		fmt.Println()
 
@@ -374,6 +1941,39 @@ func handleCommand(command string, encoder *json.Encoder) {
 	}
 }
 
+// tokenFile retorna o arquivo onde o cliente guarda os tokens recebidos no LOGIN (nome -> token)
+func tokenFile() string {
+	return getEnv("TOKEN_FILE", "tokens.json")
+}
+
+// loadTokens lê os tokens guardados (arquivo inexistente ou inválido = nenhum token)
+func loadTokens() map[string]string {
+	tokens := map[string]string{}
+	if data, err := os.ReadFile(tokenFile()); err == nil {
+		if err := json.Unmarshal(data, &tokens); err != nil {
+			return map[string]string{}
+		}
+	}
+	return tokens
+}
+
+// savedToken retorna o token guardado para o nome (vazio se nenhum)
+func savedToken(name string) string {
+	return loadTokens()[name]
+}
+
+// saveToken guarda o token emitido para o nome no primeiro LOGIN
+func saveToken(name, token string) error {
+	tokens := loadTokens()
+	tokens[name] = token
+
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(tokenFile(), data, 0o600)
+}
+
 func getEnv(k, def string) string {
 	if v := os.Getenv(k); v != "" {
 		return v
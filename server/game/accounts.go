@@ -0,0 +1,92 @@
+package game
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// TokenBytes é o tamanho (em bytes aleatórios) do token emitido no primeiro LOGIN de um nome
+const TokenBytes = 16
+
+var (
+	ErrInvalidToken = errors.New("token ausente ou incorreto para este nome")
+)
+
+// AccountStore liga cada nome usado no LOGIN a um token secreto, emitido no primeiro LOGIN do nome.
+// Só o SHA-256 do token é gravado no arquivo JSON: quem perde o token perde o nome
+type AccountStore struct {
+	path   string
+	hashes map[string]string // nome -> SHA-256 (hex) do token
+	mu     sync.Mutex
+}
+
+// LoadAccounts abre as contas gravadas no arquivo (inexistente = nenhum nome registrado)
+func LoadAccounts(path string) (*AccountStore, error) {
+	store := &AccountStore{path: path, hashes: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler contas: %w", err)
+	}
+	if err := json.Unmarshal(data, &store.hashes); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar contas: %w", err)
+	}
+	return store, nil
+}
+
+// Claim confere o token de um nome já registrado (issued vazio) ou, no primeiro LOGIN do nome, registra
+// e retorna o token emitido, que o jogador deve guardar para voltar a usar o nome
+func (s *AccountStore) Claim(name, token string) (issued string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if hash, ok := s.hashes[name]; ok {
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(hashToken(token))) != 1 {
+			return "", ErrInvalidToken
+		}
+		return "", nil
+	}
+
+	secret := make([]byte, TokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("erro ao gerar token: %w", err)
+	}
+	issued = hex.EncodeToString(secret)
+
+	s.hashes[name] = hashToken(issued)
+	if err := s.save(); err != nil {
+		delete(s.hashes, name)
+		return "", err
+	}
+	return issued, nil
+}
+
+// hashToken calcula o SHA-256 (hex) do token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// save grava as contas de forma atômica (deve ser chamado com o lock adquirido)
+func (s *AccountStore) save() error {
+	data, err := json.MarshalIndent(s.hashes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao codificar contas: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("erro ao gravar contas: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,42 @@
+package game
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestAccountTokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	accounts, err := LoadAccounts(path)
+	if err != nil {
+		t.Fatalf("Erro ao abrir contas: %v", err)
+	}
+
+	// Primeiro LOGIN do nome: o token é emitido
+	token, err := accounts.Claim("ana", "")
+	if err != nil || len(token) != 2*TokenBytes {
+		t.Fatalf("Token de %d caracteres esperado, obtido %q (%v)", 2*TokenBytes, token, err)
+	}
+
+	// Depois, o nome exige o token, inclusive com as contas relidas do arquivo
+	reloaded, err := LoadAccounts(path)
+	if err != nil {
+		t.Fatalf("Erro ao reabrir contas: %v", err)
+	}
+	for _, store := range []*AccountStore{accounts, reloaded} {
+		for _, wrong := range []string{"", "0123456789abcdef0123456789abcdef"} {
+			if _, err := store.Claim("ana", wrong); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Esperado ErrInvalidToken com o token %q, obtido %v", wrong, err)
+			}
+		}
+		if issued, err := store.Claim("ana", token); err != nil || issued != "" {
+			t.Errorf("Token correto deveria ser aceito sem emitir outro, obtido %q (%v)", issued, err)
+		}
+	}
+
+	// O arquivo guarda apenas o hash do token
+	if reloaded.hashes["ana"] == token || reloaded.hashes["ana"] != hashToken(token) {
+		t.Errorf("Arquivo deveria guardar o SHA-256 do token, guarda %q", reloaded.hashes["ana"])
+	}
+}
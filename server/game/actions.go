@@ -0,0 +1,137 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidAction     = errors.New("ação inválida")
+	ErrActionUnavailable = errors.New("ação indisponível no modo por turnos")
+	ErrCycleCards        = fmt.Errorf("o CYCLE descarta exatamente %d cartas", CycleCards)
+)
+
+// Action representa a ação escolhida por um jogador na rodada
+type Action string
+
+const (
+	ActionPlay   Action = "PLAY"   // ataca e defende com a carta escolhida (padrão)
+	ActionDefend Action = "DEFEND" // não ataca; a DEF da carta escolhida é multiplicada
+	ActionCycle  Action = "CYCLE"  // descarta duas cartas e compra duas, sem defesa na rodada
+)
+
+// RoundAction guarda a ação diferente de PLAY escolhida por um jogador na rodada
+type RoundAction struct {
+	Type  Action
+	Cards []string // cartas descartadas no CYCLE
+}
+
+// validateAction valida as cartas usadas pela ação da jogada (deve ser chamado com o lock adquirido)
+func (m *Match) validateAction(playerIndex int, play Play) error {
+	if play.Action != ActionPlay && m.Mode == ModeTurnBased {
+		return ErrActionUnavailable
+	}
+
+	switch play.Action {
+	case ActionPlay, ActionDefend:
+		if !m.inHand(playerIndex, play.CardID) {
+			return ErrNotInHand
+		}
+
+		// Valida se a carta existe no CardDB
+		card, exists := m.CardDB.GetCard(play.CardID)
+		if !exists {
+			return fmt.Errorf("carta inválida")
+		}
+
+		// Valida se o jogador tem energia para pagar a carta
+		if card.Cost > m.Energy[playerIndex] {
+			return ErrNotEnoughEnergy
+		}
+
+	case ActionCycle:
+		if len(play.Cards) != CycleCards {
+			return ErrCycleCards
+		}
+
+		// Cada cópia de uma carta na mão pode ser descartada uma vez
+		hand := append(Hand{}, m.Hands[playerIndex]...)
+		for _, cardID := range play.Cards {
+			found := false
+			for i, handCardID := range hand {
+				if handCardID == cardID {
+					hand = append(hand[:i], hand[i+1:]...)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return ErrNotInHand
+			}
+		}
+
+	default:
+		return ErrInvalidAction
+	}
+
+	return nil
+}
+
+// inHand verifica se a carta está na mão do jogador
+func (m *Match) inHand(playerIndex int, cardID string) bool {
+	for _, handCardID := range m.Hands[playerIndex] {
+		if handCardID == cardID {
+			return true
+		}
+	}
+	return false
+}
+
+// actionOf retorna a ação escolhida pelo assento na rodada atual
+func (m *Match) actionOf(seat int) Action {
+	if action, ok := m.Actions[m.Players[seat]]; ok {
+		return action.Type
+	}
+	return ActionPlay
+}
+
+// defenseOf retorna a DEF efetiva do assento na rodada (dobrada ao defender, somada à armadura do chefe)
+func (m *Match) defenseOf(seat int, card Card) int {
+	if m.actionOf(seat) == ActionDefend {
+		return card.DEF*DefendMultiplier + m.bossArmor(seat)
+	}
+	return card.DEF + m.bossArmor(seat)
+}
+
+// discardCycled descarta as cartas trocadas pelo assento com CYCLE; a reposição da mão
+// compra as substitutas
+func (m *Match) discardCycled(seat int) {
+	if m.actionOf(seat) != ActionCycle {
+		return
+	}
+	for _, cardID := range m.Actions[m.Players[seat]].Cards {
+		m.discardPlayed(seat, cardID)
+	}
+}
+
+// actionLog descreve a ação do assento na rodada na perspectiva do jogador viewer
+func (m *Match) actionLog(viewer, seat int, card Card, bonus int) string {
+	label := m.seatLabel(viewer, seat)
+
+	switch m.actionOf(seat) {
+	case ActionDefend:
+		return fmt.Sprintf("%s defendeu com %s (DEF %d × %d).", label, card.Name, card.DEF, DefendMultiplier)
+	case ActionCycle:
+		return fmt.Sprintf("%s trocou %d cartas e ficou sem defesa.", label, CycleCards)
+	}
+
+	if card.ID == "" {
+		return fmt.Sprintf("%s passou a vez (congelado ou sem energia).", label)
+	}
+
+	bonusText := ""
+	if bonus > 0 {
+		bonusText = fmt.Sprintf(" (+%d bônus elemental)", bonus)
+	}
+	return fmt.Sprintf("%s jogou %s (ATK %d%s).", label, card.Name, card.ATK, bonusText)
+}
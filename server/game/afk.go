@@ -0,0 +1,132 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"pingpong/server/protocol"
+	"time"
+)
+
+// markActive registra uma ação do próprio jogador, zerando a contagem de auto-plays consecutivos
+func (m *Match) markActive(seat int) {
+	m.autoplays[seat] = 0
+	m.lastAction[seat] = time.Now()
+}
+
+// checkAFK contabiliza um timeout do jogador (deve ser chamado com o lock adquirido).
+// Retorna true se o jogador perdeu a partida por abandono: AFKAutoplayLimit timeouts
+// consecutivos ou TimeControl.IdleMs sem nenhuma ação própria.
+func (m *Match) checkAFK(seat int) bool {
+	m.autoplays[seat]++
+
+	idle := time.Since(m.lastAction[seat])
+	if m.autoplays[seat] >= AFKAutoplayLimit || idle >= time.Duration(m.TimeControl.IdleMs)*time.Millisecond {
+		log.Printf("[MATCH %s] %s inativo (%d timeouts seguidos, %s sem ação)", m.ID, m.Players[seat], m.autoplays[seat], idle.Round(time.Second))
+		m.forfeit(seat)
+		return true
+	}
+	return false
+}
+
+// idleLimit retorna o tempo sem nenhuma ação própria antes do abandono por inatividade
+func (m *Match) idleLimit() time.Duration {
+	return time.Duration(m.TimeControl.IdleMs) * time.Millisecond
+}
+
+// lastActivity retorna a última ação própria de qualquer assento ativo
+func (m *Match) lastActivity() time.Time {
+	var last time.Time
+	for _, seat := range m.activeSeats() {
+		if m.lastAction[seat].After(last) {
+			last = m.lastAction[seat]
+		}
+	}
+	return last
+}
+
+// armIdleTimer agenda a verificação de inatividade da partida inteira, que não depende do prazo
+// da fase atual (deve ser chamado com o lock adquirido)
+func (m *Match) armIdleTimer(wait time.Duration) {
+	if m.idleTimer != nil {
+		m.idleTimer.Stop()
+	}
+	m.idleTimer = time.AfterFunc(wait, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if m.State != StateEnded {
+			m.checkMatchIdle()
+		}
+	})
+}
+
+// checkMatchIdle encerra a espera de uma partida parada: sem nenhuma ação própria de qualquer
+// jogador por TimeControl.IdleMs, todos que a partida aguarda perdem por abandono de uma vez.
+// Com atividade mais recente, reagenda a verificação (deve ser chamado com o lock adquirido)
+func (m *Match) checkMatchIdle() {
+	idle := time.Since(m.lastActivity())
+	if idle < m.idleLimit() {
+		m.armIdleTimer(m.idleLimit() - idle)
+		return
+	}
+	awaited := m.awaitedSeats()
+	if len(awaited) == 0 {
+		m.armIdleTimer(m.idleLimit())
+		return
+	}
+
+	log.Printf("[MATCH %s] Partida parada há %s: %d assento(s) perdem por abandono", m.ID, idle.Round(time.Second), len(awaited))
+	for _, seat := range awaited {
+		m.Forfeited[seat] = true
+		m.dropSeat(seat, "OPPONENT_FORFEITED", fmt.Sprintf("O jogador %s abandonou a partida por inatividade", m.Players[seat]))
+	}
+	if m.EndIfGameOver(protocol.END_TIMEOUT) {
+		return
+	}
+
+	m.advancePhase()
+	m.armIdleTimer(m.idleLimit())
+}
+
+// awaitedSeats retorna os assentos ativos dos quais a fase atual aguarda uma ação
+// (deve ser chamado com o lock adquirido)
+func (m *Match) awaitedSeats() []int {
+	switch {
+	case m.awaitingPlays():
+		return m.pendingSeats()
+	case m.State == StateDrafting:
+		seats := []int{}
+		for _, seat := range m.activeSeats() {
+			if !m.draft.picked[seat] {
+				seats = append(seats, seat)
+			}
+		}
+		return seats
+	case m.State == StateMulligan:
+		seats := []int{}
+		for _, seat := range m.activeSeats() {
+			if !m.mulliganed[seat] {
+				seats = append(seats, seat)
+			}
+		}
+		return seats
+	}
+	return nil
+}
+
+// warnAFK avisa o jogador que recebeu auto-play de quantos timeouts restam até o abandono
+func (m *Match) warnAFK(seat int) {
+	remaining := AFKAutoplayLimit - m.autoplays[seat]
+	m.emit(m.Players[seat], protocol.ServerMsg{
+		T:    protocol.ERROR,
+		Code: protocol.AFK_WARNING,
+		Msg:  fmt.Sprintf("Inatividade detectada: mais %d timeout(s) seguidos e você perde a partida por abandono", remaining),
+	})
+}
+
+// forfeit retira o jogador da partida por abandono; o resultado dele é FORFEIT em vez de LOSE
+// (deve ser chamado com o lock adquirido)
+func (m *Match) forfeit(seat int) {
+	m.Forfeited[seat] = true
+	m.removeSeat(seat, protocol.END_TIMEOUT, "OPPONENT_FORFEITED", fmt.Sprintf("O jogador %s abandonou a partida por inatividade", m.Players[seat]))
+}
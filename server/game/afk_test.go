@@ -0,0 +1,64 @@
+package game
+
+import (
+	"pingpong/server/protocol"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newIdleMatch cria uma partida com limite de inatividade curto e prazos de fase longos, já na
+// primeira rodada: só a verificação da partida inteira pode encerrá-la
+func newIdleMatch(t *testing.T, idleMs int) (*Match, map[string]string) {
+	t.Helper()
+
+	match := NewMatch("m_idle", []string{"p1", "p2"}, testCards(t), ModeSimultaneous, 1, DefaultRules())
+	match.SetTimeControl(TimeControl{Name: "TEST", BaseMs: 60_000, BankMs: 0, IdleMs: idleMs})
+	results := watchEnd(match)
+	t.Cleanup(func() {
+		match.mu.Lock()
+		defer match.mu.Unlock()
+		match.stopClock()
+		match.idleTimer.Stop()
+	})
+
+	match.Start()
+	keepAll(t, match)
+	return match, results
+}
+
+// waitDone aguarda o fim da partida, falhando o teste após o prazo
+func waitDone(t *testing.T, match *Match, timeout time.Duration) {
+	t.Helper()
+
+	select {
+	case <-match.Done():
+	case <-time.After(timeout):
+		t.Fatalf("Partida não terminou em %s", timeout)
+	}
+}
+
+func TestMatchIdleForfeitsAwaitedSeat(t *testing.T) {
+	match, results := newIdleMatch(t, 100)
+
+	// p1 jogou e aguarda p2, que não faz nada: o prazo da rodada (60 s) nunca chega a correr
+	mustPlay(t, match, "p1", match.Hands[0][0])
+	waitDone(t, match, 2*time.Second)
+
+	want := map[string]string{"p1": protocol.WIN, "p2": protocol.FORFEIT}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("Resultados esperados %v, obtidos %v", want, results)
+	}
+}
+
+func TestMatchIdleForfeitsEveryone(t *testing.T) {
+	match, results := newIdleMatch(t, 100)
+
+	// Ninguém joga: os dois perdem por abandono, sem vencedor pela ordem dos assentos
+	waitDone(t, match, 2*time.Second)
+
+	want := map[string]string{"p1": protocol.FORFEIT, "p2": protocol.FORFEIT}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("Resultados esperados %v, obtidos %v", want, results)
+	}
+}
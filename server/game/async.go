@@ -0,0 +1,395 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"pingpong/server/protocol"
+	"strings"
+	"time"
+)
+
+// AsyncModeAllowed verifica se o modo aceita partidas assíncronas (1v1 sem draft: o draft exige
+// escolhas em sequência dos dois jogadores; o chefe do raid não é restaurado com a partida)
+func AsyncModeAllowed(mode MatchMode) bool {
+	config := ModeConfigs[mode]
+	return config.MaxPlayers == 2 && !config.Draft && !config.Boss
+}
+
+// countingSource conta os números gerados pela fonte da partida, para restaurar o gerador no mesmo
+// ponto da sequência. Implementa apenas rand.Source: o rand.Rand usa Int63 em todos os sorteios
+type countingSource struct {
+	src   rand.Source
+	draws int64
+}
+
+// newCountingSource cria a fonte da semente informada já avançada draws números
+func newCountingSource(seed, draws int64) *countingSource {
+	source := &countingSource{src: rand.NewSource(seed)}
+	for source.draws < draws {
+		source.Int63()
+	}
+	return source
+}
+
+// Int63 gera o próximo número da sequência
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+// Seed reinicia a sequência
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.draws = 0
+}
+
+// asyncSnapshot é o estado de uma partida assíncrona gravado em disco
+type asyncSnapshot struct {
+	ID              string
+	Mode            MatchMode
+	Players         []string
+	Teams           []int
+	HP              []int
+	MaxHP           []int
+	Hands           []Hand
+	Decks           [][]string
+	Discard         [][]string
+	Effects         [][]StatusEffect
+	Energy          []int
+	MaxEnergy       []int
+	Targets         []int
+	Left            []bool
+	Forfeited       []bool
+	EliminatedRound []int
+	Round           int
+	State           MatchState
+	Waiting         map[string]string
+	Tentative       map[string]string
+	Actions         map[string]RoundAction
+	Deadline        time.Time
+	BankStart       time.Time
+	TimeControl     TimeControl
+	Rules           Rules
+	Bank            []int
+	Terrain         Terrain
+	TerrainEnabled  bool
+	Seed            int64
+	Draws           int64 // números já gerados pelo gerador da partida
+	Mulliganed      []bool
+	Autoplays       []int
+	LastAction      []time.Time
+	DrawVotes       []bool
+}
+
+// AsyncStore grava as partidas assíncronas em disco (um arquivo JSON por partida), para que
+// sobrevivam à desconexão dos jogadores e ao reinício do servidor
+type AsyncStore struct {
+	dir string
+}
+
+// NewAsyncStore cria o store no diretório informado
+func NewAsyncStore(dir string) (*AsyncStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de partidas assíncronas: %w", err)
+	}
+	return &AsyncStore{dir: dir}, nil
+}
+
+// path retorna o caminho do arquivo de uma partida
+func (s *AsyncStore) path(matchID string) string {
+	return filepath.Join(s.dir, matchID+".json")
+}
+
+// save grava o estado da partida, substituindo o anterior de uma só vez
+func (s *AsyncStore) save(snapshot asyncSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("erro ao codificar partida: %w", err)
+	}
+
+	tmp := s.path(snapshot.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("erro ao gravar partida: %w", err)
+	}
+	return os.Rename(tmp, s.path(snapshot.ID))
+}
+
+// remove apaga o arquivo de uma partida finalizada
+func (s *AsyncStore) remove(matchID string) error {
+	if err := os.Remove(s.path(matchID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("erro ao apagar partida: %w", err)
+	}
+	return nil
+}
+
+// LoadAll restaura as partidas assíncronas em andamento gravadas no store. As partidas voltam
+// sem observers e sem prazo: o chamador inscreve os observers e chama Resume
+func (s *AsyncStore) LoadAll(cardDB *CardDB) ([]*Match, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler diretório de partidas assíncronas: %w", err)
+	}
+
+	matches := []*Match{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler partida %s: %w", entry.Name(), err)
+		}
+		var snapshot asyncSnapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return nil, fmt.Errorf("erro ao decodificar partida %s: %w", entry.Name(), err)
+		}
+
+		match := restoreMatch(snapshot, cardDB)
+		match.store = s
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// restoreMatch recria a partida a partir do estado gravado
+func restoreMatch(snapshot asyncSnapshot, cardDB *CardDB) *Match {
+	seats := len(snapshot.Players)
+	source := newCountingSource(snapshot.Seed, snapshot.Draws)
+
+	match := &Match{
+		ID:              snapshot.ID,
+		Mode:            snapshot.Mode,
+		Players:         snapshot.Players,
+		Teams:           snapshot.Teams,
+		HP:              snapshot.HP,
+		MaxHP:           snapshot.MaxHP,
+		Hands:           snapshot.Hands,
+		Decks:           snapshot.Decks,
+		Discard:         snapshot.Discard,
+		Effects:         snapshot.Effects,
+		Energy:          snapshot.Energy,
+		MaxEnergy:       snapshot.MaxEnergy,
+		Targets:         snapshot.Targets,
+		Left:            snapshot.Left,
+		Forfeited:       snapshot.Forfeited,
+		EliminatedRound: snapshot.EliminatedRound,
+		Round:           snapshot.Round,
+		State:           snapshot.State,
+		Waiting:         snapshot.Waiting,
+		Tentative:       snapshot.Tentative,
+		Actions:         snapshot.Actions,
+		Deadline:        snapshot.Deadline,
+		TimeControl:     snapshot.TimeControl,
+		Rules:           snapshot.Rules,
+		Bank:            snapshot.Bank,
+		Terrain:         snapshot.Terrain,
+		CardDB:          cardDB,
+		Seed:            snapshot.Seed,
+		done:            make(chan bool, 1),
+		statusLogs:      make([][]string, seats),
+		mulliganed:      snapshot.Mulliganed,
+		clock:           roundClock{bankStart: snapshot.BankStart},
+		autoplays:       snapshot.Autoplays,
+		lastAction:      snapshot.LastAction,
+		drawVotes:       snapshot.DrawVotes,
+		terrainEnabled:  snapshot.TerrainEnabled,
+		cardPool:        snapshot.Rules.cardPool(cardDB),
+		source:          source,
+		rng:             rand.New(source),
+	}
+
+	log.Printf("[MATCH %s] Restaurada na rodada %d (%s)", match.ID, match.Round, match.State)
+	return match
+}
+
+// Persist torna a partida assíncrona: o estado é gravado no store a cada jogada e a cada fase, o
+// mulligan tem o prazo base do controle de tempo e quem está na vez recebe YOUR_TURN (antes de Start)
+func (m *Match) Persist(store *AsyncStore) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.store = store
+	m.persist()
+}
+
+// IsAsync verifica se a partida é assíncrona (continua após a desconexão dos jogadores)
+func (m *Match) IsAsync() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.store != nil
+}
+
+// persist grava o estado da partida assíncrona ou, após o fim, apaga o arquivo
+// (deve ser chamado com o lock adquirido)
+func (m *Match) persist() {
+	if m.store == nil {
+		return
+	}
+
+	var err error
+	if m.State == StateEnded {
+		err = m.store.remove(m.ID)
+	} else {
+		err = m.store.save(m.snapshot())
+	}
+	if err != nil {
+		log.Printf("[MATCH %s] Erro ao gravar partida assíncrona: %v", m.ID, err)
+	}
+}
+
+// snapshot copia o estado da partida para gravação (deve ser chamado com o lock adquirido)
+func (m *Match) snapshot() asyncSnapshot {
+	return asyncSnapshot{
+		ID:              m.ID,
+		Mode:            m.Mode,
+		Players:         m.Players,
+		Teams:           m.Teams,
+		HP:              m.HP,
+		MaxHP:           m.MaxHP,
+		Hands:           m.Hands,
+		Decks:           m.Decks,
+		Discard:         m.Discard,
+		Effects:         m.Effects,
+		Energy:          m.Energy,
+		MaxEnergy:       m.MaxEnergy,
+		Targets:         m.Targets,
+		Left:            m.Left,
+		Forfeited:       m.Forfeited,
+		EliminatedRound: m.EliminatedRound,
+		Round:           m.Round,
+		State:           m.State,
+		Waiting:         m.Waiting,
+		Tentative:       m.Tentative,
+		Actions:         m.Actions,
+		Deadline:        m.Deadline,
+		BankStart:       m.clock.bankStart,
+		TimeControl:     m.TimeControl,
+		Rules:           m.Rules,
+		Bank:            m.Bank,
+		Terrain:         m.Terrain,
+		TerrainEnabled:  m.terrainEnabled,
+		Seed:            m.Seed,
+		Draws:           m.source.draws,
+		Mulliganed:      m.mulliganed,
+		Autoplays:       m.autoplays,
+		LastAction:      m.lastAction,
+		DrawVotes:       m.drawVotes,
+	}
+}
+
+// Resume reabre o prazo da fase em que a partida restaurada parou e a verificação de inatividade;
+// um prazo que venceu com o servidor desligado expira imediatamente
+func (m *Match) Resume() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.armIdleTimer(time.Until(m.lastActivity().Add(m.idleLimit())))
+	switch {
+	case m.State == StateMulligan:
+		m.armTimer(time.Until(m.Deadline), m.finishMulligan)
+	case m.awaitingPlays() && !m.clock.bankStart.IsZero():
+		m.onPlayDeadline()
+	case m.awaitingPlays():
+		m.armTimer(time.Until(m.Deadline), m.onPlayDeadline)
+	}
+}
+
+// mulliganTimeout retorna o prazo do mulligan (o prazo base do controle de tempo nas partidas assíncronas)
+func (m *Match) mulliganTimeout() int {
+	if m.store != nil {
+		return m.TimeControl.BaseMs
+	}
+	return MulliganTimeout
+}
+
+// notifyTurn avisa os jogadores da partida assíncrona que precisam jogar na fase atual
+// (deve ser chamado com o lock adquirido)
+func (m *Match) notifyTurn() {
+	if m.store == nil {
+		return
+	}
+
+	deadlineMs := m.remainingMs()
+	for _, seat := range m.pendingSeats() {
+		m.emit(m.Players[seat], protocol.ServerMsg{
+			T:          protocol.YOUR_TURN,
+			MatchID:    m.ID,
+			Round:      m.Round,
+			DeadlineMs: deadlineMs,
+		})
+	}
+}
+
+// remainingMs retorna o tempo restante do prazo da fase atual (0 = vencido ou sem prazo)
+func (m *Match) remainingMs() int64 {
+	if remaining := time.Until(m.Deadline).Milliseconds(); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// yourTurn verifica se o assento precisa agir na fase atual (deve ser chamado com o lock adquirido)
+func (m *Match) yourTurn(seat int) bool {
+	if !m.isActive(seat) {
+		return false
+	}
+	switch {
+	case m.State == StateMulligan:
+		return !m.mulliganed[seat]
+	case m.awaitingPlays():
+		_, played := m.Waiting[m.Players[seat]]
+		return !played && m.isPlayersTurn(seat)
+	}
+	return false
+}
+
+// AsyncView resume a partida na perspectiva do jogador para a lista de partidas assíncronas
+func (m *Match) AsyncView(playerID string) protocol.AsyncMatchView {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seat := m.GetPlayerIndex(playerID)
+	target := m.Targets[seat]
+	return protocol.AsyncMatchView{
+		MatchID:    m.ID,
+		Mode:       string(m.Mode),
+		OpponentID: m.Players[target],
+		Round:      m.Round,
+		YourTurn:   m.yourTurn(seat),
+		DeadlineMs: m.remainingMs(),
+		HP:         m.HP[seat],
+		OpponentHP: m.HP[target],
+	}
+}
+
+// SyncState reenvia ao jogador a fase atual da partida (a oferta de mulligan ou o STATE), para quem
+// volta a uma partida assíncrona
+func (m *Match) SyncState(playerID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seat, err := m.activeSeatOf(playerID)
+	if err != nil {
+		return err
+	}
+
+	if m.State == StateMulligan {
+		if !m.mulliganed[seat] {
+			m.emit(playerID, protocol.ServerMsg{
+				T:          protocol.MULLIGAN_OFFER,
+				Cards:      append([]string{}, m.Hands[seat]...),
+				DeadlineMs: m.remainingMs(),
+			})
+		}
+		return nil
+	}
+
+	m.sendState(seat)
+	return nil
+}
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+// stopTimers cancela os prazos da partida ao fim do teste
+func stopTimers(t *testing.T, match *Match) {
+	t.Cleanup(func() {
+		match.mu.Lock()
+		defer match.mu.Unlock()
+		match.stopClock()
+		if match.idleTimer != nil {
+			match.idleTimer.Stop()
+		}
+	})
+}
+
+func TestAsyncStoreRestoresMatch(t *testing.T) {
+	cardDB := testCards(t)
+	store, err := NewAsyncStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Erro ao criar store: %v", err)
+	}
+
+	match := NewMatch("m_async", []string{"p1", "p2"}, cardDB, ModeSimultaneous, 1, DefaultRules())
+	match.SetTimeControl(TimeControls[TimeCorrespondence])
+	match.Persist(store)
+	stopTimers(t, match)
+	match.Start()
+	keepAll(t, match)
+
+	// Uma rodada completa e a jogada de p1 na segunda, aguardando p2
+	mustPlay(t, match, "p1", match.affordableCards(0)[0])
+	mustPlay(t, match, "p2", match.affordableCards(1)[0])
+	mustPlay(t, match, "p1", match.affordableCards(0)[0])
+
+	matches, err := store.LoadAll(cardDB)
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("Uma partida restaurada esperada, obtidas %d (%v)", len(matches), err)
+	}
+	restored := matches[0]
+	stopTimers(t, restored)
+	restored.Resume()
+
+	if restored.ID != match.ID || restored.Round != 2 || restored.State != match.State || !restored.IsAsync() {
+		t.Fatalf("Partida restaurada na rodada %d (%s), esperada a rodada 2 (%s)", restored.Round, restored.State, match.State)
+	}
+	for name, fields := range map[string][2]any{
+		"HP":       {match.HP, restored.HP},
+		"mãos":     {match.Hands, restored.Hands},
+		"decks":    {match.Decks, restored.Decks},
+		"energia":  {match.Energy, restored.Energy},
+		"jogadas":  {match.Waiting, restored.Waiting},
+		"controle": {match.TimeControl, restored.TimeControl},
+	} {
+		if !reflect.DeepEqual(fields[0], fields[1]) {
+			t.Errorf("%s diferentes após restaurar: %v, obtido %v", name, fields[0], fields[1])
+		}
+	}
+
+	// O gerador volta ao mesmo ponto: a mesma jogada compra as mesmas cartas nas duas partidas
+	cardID := match.affordableCards(1)[0]
+	mustPlay(t, match, "p2", cardID)
+	mustPlay(t, restored, "p2", cardID)
+	if !reflect.DeepEqual(match.Hands, restored.Hands) || !reflect.DeepEqual(match.HP, restored.HP) {
+		t.Errorf("Partidas divergiram após restaurar: mãos %v / %v, HP %v / %v", match.Hands, restored.Hands, match.HP, restored.HP)
+	}
+
+	// Partida finalizada sai do store
+	restored.Forfeit("p1")
+	if matches, err := store.LoadAll(cardDB); err != nil || len(matches) != 0 {
+		t.Errorf("Partida finalizada deveria ser apagada do store, restam %d (%v)", len(matches), err)
+	}
+}
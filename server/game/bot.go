@@ -0,0 +1,225 @@
+package game
+
+import (
+	"log"
+	"math/rand"
+	"pingpong/server/protocol"
+	"sort"
+)
+
+// BotStrategy define como um assento controlado pelo servidor escolhe suas jogadas
+type BotStrategy string
+
+const (
+	BotRandom     BotStrategy = "RANDOM"     // carta pagável aleatória
+	BotAggressive BotStrategy = "AGGRESSIVE" // maior ATK pagável
+	BotDefensive  BotStrategy = "DEFENSIVE"  // maior DEF; usa DEFEND quando está com menos HP que o oponente
+	BotElemental  BotStrategy = "ELEMENTAL"  // aproveita o terreno e evita a carta que perde para o ataque revelado
+	BotScripted   BotStrategy = "SCRIPTED"   // segue as jogadas do roteiro de um cenário de treino
+)
+
+// BotPrefix identifica os assentos controlados pelo servidor (o caractere não é aceito no LOGIN
+// nem aparece nos endereços das conexões)
+const BotPrefix = "bot#"
+
+// ValidBotStrategy verifica se a estratégia é conhecida (SCRIPTED exige roteiro e não é aceita aqui)
+func ValidBotStrategy(strategy BotStrategy) bool {
+	switch strategy {
+	case BotRandom, BotAggressive, BotDefensive, BotElemental:
+		return true
+	}
+	return false
+}
+
+// Bot joga por um assento da partida: é um observer que responde às mensagens do próprio assento
+// chamando a partida em outra goroutine (OnEvent roda com o lock da partida adquirido)
+type Bot struct {
+	match    *Match
+	playerID string
+	strategy BotStrategy
+	rng      *rand.Rand
+	script   []ScriptedPlay // jogadas por rodada (estratégia SCRIPTED)
+	played   int            // última rodada jogada pelo roteiro
+}
+
+// ScriptedPlay é a jogada de uma rodada no roteiro de um cenário de treino (carta vazia e ação
+// diferente de CYCLE = não joga na rodada, por exemplo quando o assento está congelado)
+type ScriptedPlay struct {
+	CardID string   `json:"cardId,omitempty"`
+	Action Action   `json:"action,omitempty"` // PLAY (padrão), DEFEND ou CYCLE
+	Cards  []string `json:"cards,omitempty"`  // cartas descartadas no CYCLE
+}
+
+// empty verifica se a jogada do roteiro deixa a rodada passar
+func (p ScriptedPlay) empty() bool {
+	return p.CardID == "" && p.Action != ActionCycle
+}
+
+// play converte a jogada do roteiro em uma jogada confirmada do assento
+func (p ScriptedPlay) play(playerID string) Play {
+	return Play{PlayerID: playerID, CardID: p.CardID, Action: p.Action, Cards: p.Cards, Lock: true}
+}
+
+// NewBot cria o bot do assento e o registra na partida (antes de Start)
+func NewBot(match *Match, playerID string, strategy BotStrategy) *Bot {
+	bot := &Bot{
+		match:    match,
+		playerID: playerID,
+		strategy: strategy,
+		rng:      rand.New(rand.NewSource(match.Seed)),
+	}
+	match.Subscribe(bot)
+	return bot
+}
+
+// NewScriptedBot cria o bot que repete o roteiro de jogadas (uma por rodada) e o registra na partida
+func NewScriptedBot(match *Match, playerID string, script []ScriptedPlay) *Bot {
+	bot := NewBot(match, playerID, BotScripted)
+	bot.script = script
+	return bot
+}
+
+// OnEvent mantém a mão inicial, recusa ofertas de empate e joga a cada STATE em que está na vez
+func (b *Bot) OnEvent(event Event) {
+	if event.PlayerID != b.playerID {
+		return
+	}
+
+	msg := event.Msg
+	switch msg.T {
+	case protocol.MULLIGAN_OFFER:
+		go b.match.Mulligan(b.playerID, nil)
+	case protocol.DRAW_OFFERED:
+		go b.match.DeclineDraw(b.playerID)
+	case protocol.STATE:
+		if !b.yourTurn(msg) {
+			return
+		}
+		play, ok := b.choose(msg)
+		if !ok {
+			return
+		}
+		go func() {
+			if err := b.match.Apply(play); err != nil {
+				log.Printf("[MATCH %s] Jogada do bot %s rejeitada: %v", event.MatchID, b.playerID, err)
+			}
+		}()
+	}
+}
+
+// yourTurn verifica pelo STATE se o bot joga na fase atual (no modo por turnos, apenas quem está na vez)
+func (b *Bot) yourTurn(msg protocol.ServerMsg) bool {
+	switch MatchState(msg.Phase) {
+	case StateAwaitingAttack:
+		return msg.AttackerID == b.playerID
+	case StateAwaitingDefense:
+		return msg.AttackerID != b.playerID
+	}
+	return true
+}
+
+// choose escolhe a jogada do bot a partir da própria visão do STATE (o oponente só é visto pelo HP e,
+// na defesa do modo por turnos, pela carta revelada do atacante) e, com mais de dois assentos, o alvo
+func (b *Bot) choose(msg protocol.ServerMsg) (Play, bool) {
+	if b.strategy == BotScripted {
+		return b.scripted(msg.Round)
+	}
+
+	cardDB := b.match.CardDB
+	affordable := []Card{}
+	for _, cardID := range msg.You.Hand {
+		if card, ok := cardDB.GetCard(cardID); ok && card.Cost <= msg.You.Energy {
+			affordable = append(affordable, card)
+		}
+	}
+	if len(affordable) == 0 {
+		return Play{}, false
+	}
+
+	play := Play{PlayerID: b.playerID, Lock: true}
+	switch b.strategy {
+	case BotAggressive:
+		sortCards(affordable, func(card Card) int { return card.ATK })
+	case BotDefensive:
+		sortCards(affordable, func(card Card) int { return card.DEF })
+		if msg.Phase == "" && msg.You.HP < msg.Opponent.HP {
+			play.Action = ActionDefend
+		}
+	case BotElemental:
+		revealed, _ := cardDB.GetCard(msg.Opponent.CardID)
+		terrain := Terrain(msg.Terrain)
+		sortCards(affordable, func(card Card) int {
+			score := card.ATK + card.DEF + TerrainBonus(terrain, card.Element)
+			if revealed.ID != "" {
+				score -= 2 * b.match.Rules.ElementalBonus(revealed.Element, card.Element)
+			}
+			return score
+		})
+	default:
+		b.rng.Shuffle(len(affordable), func(i, j int) {
+			affordable[i], affordable[j] = affordable[j], affordable[i]
+		})
+	}
+
+	play.CardID = affordable[0].ID
+	play.Target = b.target(msg)
+	return play, true
+}
+
+// target escolhe o adversário atacado em partidas com mais de dois jogadores: o de menor HP (o de
+// maior HP na estratégia DEFENSIVE, um qualquer na RANDOM); vazio mantém o alvo atual
+func (b *Bot) target(msg protocol.ServerMsg) string {
+	team := -1
+	for _, seat := range msg.Seats {
+		if seat.PlayerID == b.playerID {
+			team = seat.Team
+		}
+	}
+
+	candidates := []protocol.SeatView{}
+	for _, seat := range msg.Seats {
+		if seat.Team != team && !seat.Eliminated {
+			candidates = append(candidates, seat)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	switch b.strategy {
+	case BotRandom:
+		return candidates[b.rng.Intn(len(candidates))].PlayerID
+	case BotDefensive:
+		sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].HP > candidates[j].HP })
+	default:
+		sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].HP < candidates[j].HP })
+	}
+	return candidates[0].PlayerID
+}
+
+// scripted retorna a jogada do roteiro para a rodada, uma única vez (os STATE seguintes da mesma
+// rodada são ignorados)
+func (b *Bot) scripted(round int) (Play, bool) {
+	if round <= b.played || round > len(b.script) {
+		return Play{}, false
+	}
+	b.played = round
+
+	scripted := b.script[round-1]
+	if scripted.empty() {
+		return Play{}, false
+	}
+	return scripted.play(b.playerID), true
+}
+
+// sortCards ordena as cartas pela pontuação, da maior para a menor (empates mantêm a ordem da mão)
+func sortCards(cards []Card, score func(card Card) int) {
+	sort.SliceStable(cards, func(i, j int) bool {
+		return score(cards[i]) > score(cards[j])
+	})
+}
+
+// BotID retorna o ID do assento controlado pelo servidor com o nome informado
+func BotID(name string) string {
+	return BotPrefix + name
+}
@@ -0,0 +1,279 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"pingpong/server/protocol"
+	"sync"
+)
+
+var (
+	ErrEncounterNotFound = errors.New("encontro não encontrado")
+	ErrEncounterLocked   = errors.New("encontro ainda bloqueado: vença o encontro anterior")
+)
+
+// Encounter é um encontro da campanha: uma partida 1v1 contra um bot com deck fixo, HP próprio,
+// regras especiais e estratégia definidos no arquivo da campanha
+type Encounter struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Mode        MatchMode       `json:"mode"`      // SIMULTANEOUS (padrão) ou TURN_BASED
+	EnemyHP     int             `json:"enemyHp"`   // HP do bot (0 = HP inicial das regras)
+	EnemyDeck   []string        `json:"enemyDeck"` // deck fixo do bot, embaralhado a cada partida
+	Strategy    BotStrategy     `json:"strategy"`
+	Rules       *protocol.Rules `json:"rules,omitempty"` // regras especiais (omitidas = padrão)
+	Terrain     bool            `json:"terrain,omitempty"`
+	Reward      Reward          `json:"reward"` // concedida apenas na primeira vitória
+
+	rules Rules // regras validadas
+}
+
+// Reward é a recompensa da primeira vitória em um encontro
+type Reward struct {
+	Packs int `json:"packs"` // pacotes abertos do estoque global
+	Coins int `json:"coins"` // moedas somadas à conta do jogador
+}
+
+// Campaign é a sequência de encontros da campanha; cada encontro é desbloqueado ao vencer o anterior
+type Campaign struct {
+	Encounters []Encounter
+}
+
+// LoadCampaign carrega e valida os encontros do arquivo JSON da campanha
+func LoadCampaign(filename string, cardDB *CardDB) (*Campaign, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo da campanha: %w", err)
+	}
+
+	var encounters []Encounter
+	if err := json.Unmarshal(data, &encounters); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar JSON da campanha: %w", err)
+	}
+
+	seen := make(map[string]bool, len(encounters))
+	for i := range encounters {
+		encounter := &encounters[i]
+		if err := encounter.validate(cardDB); err != nil {
+			return nil, fmt.Errorf("encontro %q: %w", encounter.ID, err)
+		}
+		if seen[encounter.ID] {
+			return nil, fmt.Errorf("encontro %q repetido", encounter.ID)
+		}
+		seen[encounter.ID] = true
+	}
+
+	return &Campaign{Encounters: encounters}, nil
+}
+
+// validate confere o encontro e converte suas regras
+func (e *Encounter) validate(cardDB *CardDB) error {
+	if e.ID == "" {
+		return errors.New("ID vazio")
+	}
+	if e.Mode == "" {
+		e.Mode = ModeSimultaneous
+	}
+	if config, ok := ModeConfigs[e.Mode]; !ok || config.MaxPlayers != 2 || config.Draft || config.Boss {
+		return fmt.Errorf("modo %q não é 1v1 sem draft", e.Mode)
+	}
+	if !ValidBotStrategy(e.Strategy) {
+		return fmt.Errorf("estratégia desconhecida %q", e.Strategy)
+	}
+	if e.EnemyHP < 0 || e.EnemyHP > MaxRulesHP {
+		return fmt.Errorf("HP do inimigo deve ficar entre 0 e %d", MaxRulesHP)
+	}
+	if e.Reward.Packs < 0 || e.Reward.Coins < 0 {
+		return errors.New("recompensa negativa")
+	}
+
+	rules, err := ParseRules(e.Rules, cardDB)
+	if err != nil {
+		return err
+	}
+	if rules.DeckPolicy == DeckDraft {
+		return errors.New("encontros não aceitam deck de draft")
+	}
+	e.rules = rules
+
+	if len(e.EnemyDeck) < rules.HandSize {
+		return fmt.Errorf("deck do inimigo precisa de ao menos %d cartas", rules.HandSize)
+	}
+	for _, cardID := range e.EnemyDeck {
+		if !cardDB.ValidateCard(cardID) {
+			return fmt.Errorf("carta desconhecida %q no deck do inimigo", cardID)
+		}
+	}
+	return nil
+}
+
+// Find retorna o encontro e sua posição na campanha
+func (c *Campaign) Find(encounterID string) (Encounter, int, bool) {
+	for i, encounter := range c.Encounters {
+		if encounter.ID == encounterID {
+			return encounter, i, true
+		}
+	}
+	return Encounter{}, -1, false
+}
+
+// BotID retorna o ID do assento do inimigo do encontro
+func (e Encounter) BotID() string {
+	return BotID(e.ID)
+}
+
+// NewMatch cria a partida do encontro: o jogador no primeiro assento e o bot no segundo, com o HP e
+// o deck fixo do inimigo (os observers do jogador devem ser registrados antes de Start)
+func (e Encounter) NewMatch(id, playerID string, cardDB *CardDB, seed int64) *Match {
+	match := NewMatch(id, []string{playerID, e.BotID()}, cardDB, e.Mode, seed, e.rules)
+	match.SetTerrain(e.Terrain)
+	match.SetSeat(e.BotID(), e.EnemyHP, e.EnemyDeck)
+	NewBot(match, e.BotID(), e.Strategy)
+	return match
+}
+
+// campaignAccount é o progresso de um jogador na campanha
+type campaignAccount struct {
+	Cleared []string `json:"cleared"` // encontros vencidos, na ordem da primeira vitória
+	Coins   int      `json:"coins"`
+}
+
+// CampaignProgress guarda o progresso de cada conta (jogador identificado com LOGIN) em um
+// arquivo JSON, regravado a cada primeira vitória
+type CampaignProgress struct {
+	path     string
+	accounts map[string]*campaignAccount
+	mu       sync.Mutex
+}
+
+// LoadCampaignProgress abre o progresso gravado no arquivo (inexistente = nenhum progresso)
+func LoadCampaignProgress(path string) (*CampaignProgress, error) {
+	progress := &CampaignProgress{path: path, accounts: make(map[string]*campaignAccount)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return progress, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler progresso da campanha: %w", err)
+	}
+	if err := json.Unmarshal(data, &progress.accounts); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar progresso da campanha: %w", err)
+	}
+	return progress, nil
+}
+
+// CheckUnlocked verifica se o jogador pode enfrentar o encontro: o primeiro está sempre liberado e
+// os demais exigem a vitória no anterior
+func (p *CampaignProgress) CheckUnlocked(campaign *Campaign, playerID, encounterID string) error {
+	_, index, ok := campaign.Find(encounterID)
+	if !ok {
+		return ErrEncounterNotFound
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if index > 0 && !p.cleared(playerID, campaign.Encounters[index-1].ID) {
+		return ErrEncounterLocked
+	}
+	return nil
+}
+
+// Clear registra a vitória do jogador no encontro e soma as moedas da recompensa; retorna false
+// (sem recompensa) se o encontro já tinha sido vencido
+func (p *CampaignProgress) Clear(playerID string, encounter Encounter) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cleared(playerID, encounter.ID) {
+		return false, nil
+	}
+
+	account, ok := p.accounts[playerID]
+	if !ok {
+		account = &campaignAccount{Cleared: []string{}}
+		p.accounts[playerID] = account
+	}
+	account.Cleared = append(account.Cleared, encounter.ID)
+	account.Coins += encounter.Reward.Coins
+	return true, p.save()
+}
+
+// AddCoins soma moedas ganhas fora da campanha (como a recompensa de fim de temporada) à conta do jogador
+func (p *CampaignProgress) AddCoins(playerID string, coins int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	account, ok := p.accounts[playerID]
+	if !ok {
+		account = &campaignAccount{Cleared: []string{}}
+		p.accounts[playerID] = account
+	}
+	account.Coins += coins
+	return p.save()
+}
+
+// View descreve os encontros da campanha com o progresso do jogador e suas moedas
+func (p *CampaignProgress) View(campaign *Campaign, playerID string) ([]protocol.EncounterView, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	views := make([]protocol.EncounterView, len(campaign.Encounters))
+	for i, encounter := range campaign.Encounters {
+		enemyHP := encounter.EnemyHP
+		if enemyHP == 0 {
+			enemyHP = encounter.rules.HPStart
+		}
+		views[i] = protocol.EncounterView{
+			ID:          encounter.ID,
+			Name:        encounter.Name,
+			Description: encounter.Description,
+			Mode:        string(encounter.Mode),
+			EnemyHP:     enemyHP,
+			Strategy:    string(encounter.Strategy),
+			RewardPacks: encounter.Reward.Packs,
+			RewardCoins: encounter.Reward.Coins,
+			Unlocked:    i == 0 || p.cleared(playerID, campaign.Encounters[i-1].ID),
+			Cleared:     p.cleared(playerID, encounter.ID),
+		}
+	}
+
+	coins := 0
+	if account, ok := p.accounts[playerID]; ok {
+		coins = account.Coins
+	}
+	return views, coins
+}
+
+// cleared verifica se o jogador já venceu o encontro (deve ser chamado com o lock adquirido)
+func (p *CampaignProgress) cleared(playerID, encounterID string) bool {
+	account, ok := p.accounts[playerID]
+	if !ok {
+		return false
+	}
+	for _, clearedID := range account.Cleared {
+		if clearedID == encounterID {
+			return true
+		}
+	}
+	return false
+}
+
+// save grava o progresso de todas as contas, substituindo o arquivo de uma só vez
+// (deve ser chamado com o lock adquirido)
+func (p *CampaignProgress) save() error {
+	data, err := json.MarshalIndent(p.accounts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao codificar progresso da campanha: %w", err)
+	}
+
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("erro ao gravar progresso da campanha: %w", err)
+	}
+	return os.Rename(tmp, p.path)
+}
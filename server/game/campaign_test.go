@@ -0,0 +1,56 @@
+package game
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestCampaignProgressFile(t *testing.T) {
+	campaign, err := LoadCampaign("../campaign.json", testCards(t))
+	if err != nil {
+		t.Fatalf("Erro ao carregar campanha: %v", err)
+	}
+	first, second := campaign.Encounters[0], campaign.Encounters[1]
+
+	path := filepath.Join(t.TempDir(), "campaign_progress.json")
+	progress, err := LoadCampaignProgress(path)
+	if err != nil {
+		t.Fatalf("Erro ao abrir progresso: %v", err)
+	}
+	if err := progress.CheckUnlocked(campaign, "ana", second.ID); !errors.Is(err, ErrEncounterLocked) {
+		t.Fatalf("Segundo encontro deveria estar bloqueado, obtido %v", err)
+	}
+
+	// Só a primeira vitória rende moedas
+	for i, wantReward := range []bool{true, false} {
+		rewarded, err := progress.Clear("ana", first)
+		if err != nil || rewarded != wantReward {
+			t.Fatalf("Vitória %d: recompensa esperada %t, obtida %t (%v)", i+1, wantReward, rewarded, err)
+		}
+	}
+	if err := progress.AddCoins("ana", 25); err != nil {
+		t.Fatalf("Erro ao somar moedas: %v", err)
+	}
+
+	// O progresso volta do arquivo: o segundo encontro liberado e as moedas somadas
+	reloaded, err := LoadCampaignProgress(path)
+	if err != nil {
+		t.Fatalf("Erro ao reabrir progresso: %v", err)
+	}
+	if err := reloaded.CheckUnlocked(campaign, "ana", second.ID); err != nil {
+		t.Errorf("Segundo encontro deveria estar liberado após reabrir: %v", err)
+	}
+	views, coins := reloaded.View(campaign, "ana")
+	if want := first.Reward.Coins + 25; coins != want {
+		t.Errorf("Moedas esperadas %d, obtidas %d", want, coins)
+	}
+	if !views[0].Cleared || !views[1].Unlocked || views[1].Cleared {
+		t.Errorf("Visão da campanha inconsistente após reabrir: %+v", views[:2])
+	}
+
+	// Outras contas não são afetadas
+	if _, coins := reloaded.View(campaign, "bia"); coins != 0 {
+		t.Errorf("bia não deveria ter moedas, tem %d", coins)
+	}
+}
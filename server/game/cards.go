@@ -56,8 +56,8 @@ func (db *CardDB) GetCard(id string) (Card, bool) {
 	return card, exists
 }
 
-// GetRandomCard retorna uma carta aleatória do pool
-func (db *CardDB) GetRandomCard() string {
+// GetRandomCard retorna uma carta aleatória do pool usando o gerador informado
+func (db *CardDB) GetRandomCard(rng *rand.Rand) string {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 
@@ -65,14 +65,46 @@ func (db *CardDB) GetRandomCard() string {
 		return ""
 	}
 
-	return db.pool[rand.Intn(len(db.pool))]
+	return db.pool[rng.Intn(len(db.pool))]
+}
+
+// CardIDs retorna os IDs das cartas na ordem usada nos sorteios
+func (db *CardDB) CardIDs() []string {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return append([]string{}, db.pool...)
+}
+
+// Subset cria uma base com apenas as cartas informadas, na ordem de sorteio recebida
+func (db *CardDB) Subset(ids []string) *CardDB {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	subset := NewCardDB()
+	for _, id := range ids {
+		if card, exists := db.cards[id]; exists {
+			subset.cards[id] = card
+			subset.pool = append(subset.pool, id)
+		}
+	}
+	return subset
+}
+
+// GeneratePack sorteia as cartas de um pacote (mesmo sorteio de OpenPack, sem consumir estoque)
+func (db *CardDB) GeneratePack(size int, rng *rand.Rand) []string {
+	cards := make([]string, size)
+	for i := 0; i < size; i++ {
+		cards[i] = db.GetRandomCard(rng)
+	}
+	return cards
 }
 
 // GenerateHand gera uma mão inicial com cartas aleatórias
-func (db *CardDB) GenerateHand(size int) Hand {
+func (db *CardDB) GenerateHand(size int, rng *rand.Rand) Hand {
 	hand := make(Hand, size)
 	for i := 0; i < size; i++ {
-		hand[i] = db.GetRandomCard()
+		hand[i] = db.GetRandomCard(rng)
 	}
 	return hand
 }
@@ -146,10 +178,7 @@ func (ps *PackSystem) OpenPack(playerID string) ([]string, error) {
 	ps.stock--
 
 	// Sorteia cartas
-	cards := make([]string, ps.config.CardsPerPack)
-	for i := 0; i < ps.config.CardsPerPack; i++ {
-		cards[i] = ps.cardDB.GetRandomCard()
-	}
+	cards := ps.cardDB.GeneratePack(ps.config.CardsPerPack, ps.rng)
 
 	// Log de auditoria
 	packID := fmt.Sprintf("pack_%d_%d", time.Now().Unix(), ps.rng.Int63())
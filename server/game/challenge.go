@@ -0,0 +1,182 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	ErrChallengeNotFound = errors.New("desafio não encontrado")
+	ErrChallengeExpired  = errors.New("o desafio expirou")
+	ErrSelfChallenge     = errors.New("você não pode desafiar a si mesmo")
+)
+
+// Challenge representa um desafio 1v1 pendente: direto a um jogador ou por código de convite,
+// resgatável uma única vez por qualquer jogador
+type Challenge struct {
+	ID           string
+	ChallengerID string
+	TargetID     string // vazio no convite por código até o resgate
+	Code         string // código de convite (vazio no desafio direto)
+	Mode         MatchMode
+	TimeControl  string
+	Terrain      bool
+	Async        bool // partida assíncrona (exige LOGIN dos dois jogadores)
+	ExpiresAt    time.Time
+}
+
+// ChallengeManager guarda os desafios pendentes, fora da fila de matchmaking
+type ChallengeManager struct {
+	challenges map[string]*Challenge // ID -> desafio
+	codes      map[string]string     // código de convite -> ID do desafio
+	nextID     int
+	rng        *rand.Rand
+	mu         sync.Mutex
+}
+
+// NewChallengeManager cria o gerenciador de desafios (seed 0 = semente aleatória para os códigos)
+func NewChallengeManager(seed int64) *ChallengeManager {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	return &ChallengeManager{
+		challenges: make(map[string]*Challenge),
+		codes:      make(map[string]string),
+		rng:        rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Send registra o desafio com ID e prazo; sem TargetID, gera um código de convite
+func (cm *ChallengeManager) Send(challenge Challenge) (Challenge, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if challenge.TargetID == challenge.ChallengerID {
+		return Challenge{}, ErrSelfChallenge
+	}
+
+	cm.nextID++
+	challenge.ID = fmt.Sprintf("ch_%d", cm.nextID)
+	timeout := ChallengeTimeout
+	if challenge.TargetID == "" {
+		timeout = InviteTimeout
+		challenge.Code = randomCode(cm.rng, func(code string) bool {
+			_, taken := cm.codes[code]
+			return taken
+		})
+		cm.codes[challenge.Code] = challenge.ID
+	}
+	challenge.ExpiresAt = time.Now().Add(time.Duration(timeout) * time.Millisecond)
+
+	cm.challenges[challenge.ID] = &challenge
+	return challenge, nil
+}
+
+// Accept retira o desafio direto aceito pelo desafiado, que deve criar a partida
+func (cm *ChallengeManager) Accept(challengeID, playerID string) (Challenge, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	challenge, ok := cm.challenges[challengeID]
+	if !ok || challenge.Code != "" || challenge.TargetID != playerID {
+		return Challenge{}, ErrChallengeNotFound
+	}
+	return cm.take(challenge)
+}
+
+// Decline retira o desafio recusado pelo desafiado (ou cancelado pelo desafiante)
+func (cm *ChallengeManager) Decline(challengeID, playerID string) (Challenge, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	challenge, ok := cm.challenges[challengeID]
+	if !ok || (challenge.TargetID != playerID && challenge.ChallengerID != playerID) {
+		return Challenge{}, ErrChallengeNotFound
+	}
+	cm.remove(challenge)
+	return *challenge, nil
+}
+
+// Redeem consome o código de convite: quem o resgata passa a ser o desafiado
+func (cm *ChallengeManager) Redeem(code, playerID string) (Challenge, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	challenge, ok := cm.challenges[cm.codes[code]]
+	if !ok {
+		return Challenge{}, ErrChallengeNotFound
+	}
+	if challenge.ChallengerID == playerID {
+		return Challenge{}, ErrSelfChallenge
+	}
+
+	challenge.TargetID = playerID
+	return cm.take(challenge)
+}
+
+// Restore devolve um desafio retirado por Accept ou Redeem cuja partida não pôde começar; o
+// convite por código volta a aceitar qualquer jogador. O prazo continua o original
+func (cm *ChallengeManager) Restore(challenge Challenge) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if challenge.Code != "" {
+		if _, taken := cm.codes[challenge.Code]; taken {
+			return
+		}
+		challenge.TargetID = ""
+		cm.codes[challenge.Code] = challenge.ID
+	}
+	cm.challenges[challenge.ID] = &challenge
+}
+
+// Expire retira e retorna os desafios cujo prazo acabou até now
+func (cm *ChallengeManager) Expire(now time.Time) []Challenge {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	expired := []Challenge{}
+	for _, challenge := range cm.challenges {
+		if now.After(challenge.ExpiresAt) {
+			cm.remove(challenge)
+			expired = append(expired, *challenge)
+		}
+	}
+	return expired
+}
+
+// RemovePlayer retira e retorna os desafios pendentes enviados ou recebidos pelo jogador
+func (cm *ChallengeManager) RemovePlayer(playerID string) []Challenge {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	removed := []Challenge{}
+	for _, challenge := range cm.challenges {
+		if challenge.ChallengerID == playerID || challenge.TargetID == playerID {
+			cm.remove(challenge)
+			removed = append(removed, *challenge)
+		}
+	}
+	return removed
+}
+
+// take retira o desafio aceito, recusando-o se o prazo já acabou (deve ser chamado com o lock adquirido)
+func (cm *ChallengeManager) take(challenge *Challenge) (Challenge, error) {
+	cm.remove(challenge)
+	if time.Now().After(challenge.ExpiresAt) {
+		return Challenge{}, ErrChallengeExpired
+	}
+	return *challenge, nil
+}
+
+// remove apaga o desafio e seu código de convite (deve ser chamado com o lock adquirido)
+func (cm *ChallengeManager) remove(challenge *Challenge) {
+	delete(cm.challenges, challenge.ID)
+	if challenge.Code != "" {
+		delete(cm.codes, challenge.Code)
+	}
+}
@@ -0,0 +1,202 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"pingpong/server/protocol"
+	"time"
+)
+
+// roundClock controla o prazo da fase atual da partida (jogada, defesa ou escolha do draft).
+// Cada fase recebe um ID; um timer só age se a rodada e a fase para as quais foi armado ainda estiverem abertas.
+type roundClock struct {
+	phaseID   int         // ID da fase atual (incrementa a cada prazo armado ou cancelado)
+	timer     *time.Timer // timer da fase atual (nil = nenhum prazo ativo)
+	bankStart time.Time   // fim do prazo base da fase; a partir daí quem não jogou consome o banco (zero = prazo base)
+}
+
+// armClock cancela o prazo anterior e abre um novo prazo para a fase atual; ao expirar,
+// onTimeout é chamado com o lock adquirido (deve ser chamado com o lock adquirido)
+func (m *Match) armClock(timeoutMs int, onTimeout func()) {
+	m.stopClock()
+
+	timeout := time.Duration(timeoutMs) * time.Millisecond
+	m.Deadline = time.Now().Add(timeout)
+	m.armTimer(timeout, onTimeout)
+}
+
+// armTimer agenda onTimeout para a fase atual sem alterar o prazo anunciado (Deadline)
+func (m *Match) armTimer(timeout time.Duration, onTimeout func()) {
+	m.clock.phaseID++
+	phaseID, round := m.clock.phaseID, m.Round
+
+	m.clock.timer = time.AfterFunc(timeout, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		// Timer de uma fase que já foi resolvida (a rodada avançou ou a partida terminou)
+		if phaseID != m.clock.phaseID || round != m.Round || m.State == StateEnded {
+			return
+		}
+		m.clock.timer = nil
+
+		log.Printf("[MATCH %s] Prazo da rodada %d expirou", m.ID, round)
+		onTimeout()
+	})
+}
+
+// stopClock cancela o prazo da fase atual, se houver (deve ser chamado com o lock adquirido)
+func (m *Match) stopClock() {
+	if m.clock.timer != nil {
+		m.clock.timer.Stop()
+		m.clock.timer = nil
+	}
+	// Invalida um timer que já disparou e aguarda o lock
+	m.clock.phaseID++
+	m.clock.bankStart = time.Time{}
+	m.Deadline = time.Time{}
+}
+
+// startPlayClock abre o prazo base de jogada da fase atual; ao expirar, quem não jogou passa a
+// consumir o próprio banco de tempo
+func (m *Match) startPlayClock() {
+	m.armClock(m.TimeControl.BaseMs, m.onPlayDeadline)
+}
+
+// onPlayDeadline trata o fim do prazo base e de cada banco de tempo: auto-play para quem esgotou
+// o banco, novo timer para o menor banco restante (deve ser chamado com o lock adquirido)
+func (m *Match) onPlayDeadline() {
+	if !m.awaitingPlays() {
+		return
+	}
+
+	now := time.Now()
+	if m.clock.bankStart.IsZero() {
+		m.clock.bankStart = now
+		for _, seat := range m.pendingSeats() {
+			if m.Bank[seat] > 0 {
+				m.emit(m.Players[seat], protocol.ServerMsg{
+					T:      protocol.ERROR,
+					Code:   protocol.TIME_BANK,
+					Msg:    fmt.Sprintf("Prazo da rodada esgotado: usando seu banco de tempo (%.1fs)", float64(m.Bank[seat])/1000),
+					Round:  m.Round,
+					BankMs: int64(m.Bank[seat]),
+				})
+			}
+		}
+	}
+
+	elapsed := int(now.Sub(m.clock.bankStart).Milliseconds())
+	exhausted := []int{}
+	nextExpiry := 0
+	for _, seat := range m.pendingSeats() {
+		remaining := m.Bank[seat] - elapsed
+		if remaining <= 0 {
+			m.Bank[seat] = 0
+			exhausted = append(exhausted, seat)
+		} else if nextExpiry == 0 || remaining < nextExpiry {
+			nextExpiry = remaining
+		}
+	}
+
+	// Quem ainda tem banco continua pensando; o auto-play dos demais pode abrir uma nova fase,
+	// que cancela este timer
+	if nextExpiry > 0 {
+		m.armTimer(time.Duration(nextExpiry)*time.Millisecond, m.onPlayDeadline)
+	}
+	if len(exhausted) > 0 {
+		m.autoplay(exhausted)
+	}
+}
+
+// spendBank desconta do banco do assento o tempo usado após o fim do prazo base
+// (deve ser chamado com o lock adquirido)
+func (m *Match) spendBank(seat int, at time.Time) {
+	if m.clock.bankStart.IsZero() {
+		return
+	}
+	m.Bank[seat] = max(0, m.Bank[seat]-int(at.Sub(m.clock.bankStart).Milliseconds()))
+}
+
+// pendingSeats retorna os assentos que ainda precisam confirmar a jogada na fase atual
+// (no modo por turnos, apenas quem está na vez)
+func (m *Match) pendingSeats() []int {
+	seats := []int{}
+	for _, seat := range m.activeSeats() {
+		if _, played := m.Waiting[m.Players[seat]]; !played && m.isPlayersTurn(seat) {
+			seats = append(seats, seat)
+		}
+	}
+	return seats
+}
+
+// AutoplayIfNeeded executa auto-play para todos os jogadores que não jogaram, encerrando o prazo
+// atual sem considerar o banco de tempo
+func (m *Match) AutoplayIfNeeded() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stopClock()
+	if m.awaitingPlays() {
+		m.autoplay(m.pendingSeats())
+	}
+}
+
+// autoplay confirma a jogada dos assentos que não confirmaram a tempo (a carta escolhida ou,
+// sem escolha, uma carta aleatória) e avisa o jogador com ERROR TIMEOUT_PLAY
+// (deve ser chamado com o lock adquirido)
+func (m *Match) autoplay(playersToAutoplay []int) {
+	// Executa auto-play (quem passou do limite de inatividade perde a partida por abandono)
+	for _, playerIndex := range playersToAutoplay {
+		playerID := m.Players[playerIndex]
+
+		// Carta escolhida e não confirmada: o jogador agiu, apenas não confirmou a tempo
+		if cardID, chosen := m.Tentative[playerID]; chosen {
+			card, _ := m.CardDB.GetCard(cardID)
+			choice := card.Name
+			switch m.actionOf(playerIndex) {
+			case ActionDefend:
+				choice = "DEFEND com " + card.Name
+			case ActionCycle:
+				choice = string(ActionCycle)
+			}
+			m.confirmPlay(playerIndex, time.Now(), true)
+			m.emit(playerID, protocol.ServerMsg{
+				T:     protocol.ERROR,
+				Code:  protocol.TIMEOUT_PLAY,
+				Msg:   fmt.Sprintf("Tempo esgotado: sua escolha (%s) foi confirmada automaticamente", choice),
+				Round: m.Round,
+			})
+			continue
+		}
+
+		if m.checkAFK(playerIndex) {
+			if m.State == StateEnded {
+				return
+			}
+			continue
+		}
+
+		affordable := m.affordableCards(playerIndex)
+		cardID := ""
+		notice := "Tempo esgotado: você passou a vez (sem cartas pagáveis)"
+		if len(affordable) > 0 {
+			// Escolhe carta aleatória entre as que o jogador pode pagar
+			cardID = affordable[m.rng.Intn(len(affordable))]
+			card, _ := m.CardDB.GetCard(cardID)
+			notice = fmt.Sprintf("Tempo esgotado: %s foi jogada automaticamente", card.Name)
+			log.Printf("[MATCH %s] Auto-play para %s: %s", m.ID, playerID, cardID)
+		} else {
+			log.Printf("[MATCH %s] Auto-pass para %s (sem cartas pagáveis)", m.ID, playerID)
+		}
+
+		m.Tentative[playerID] = cardID
+		delete(m.Actions, playerID)
+		m.confirmPlay(playerIndex, time.Now(), true)
+		m.emit(playerID, protocol.ServerMsg{T: protocol.ERROR, Code: protocol.TIMEOUT_PLAY, Msg: notice, Round: m.Round})
+		m.warnAFK(playerIndex)
+	}
+
+	// Avança para a defesa ou resolve a rodada se todos jogaram (incluindo auto-play)
+	m.advanceRound()
+}
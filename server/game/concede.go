@@ -0,0 +1,130 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"pingpong/server/protocol"
+)
+
+var (
+	ErrNoDrawOffer = errors.New("não há oferta de empate pendente")
+)
+
+// activeSeatOf retorna o assento do jogador se ele ainda estiver ativo em uma partida em andamento
+func (m *Match) activeSeatOf(playerID string) (int, error) {
+	seat := m.GetPlayerIndex(playerID)
+	if seat < 0 || !m.isActive(seat) || m.State == StateEnded {
+		return -1, errors.New("jogador não está nesta partida")
+	}
+	return seat, nil
+}
+
+// Forfeit encerra a participação do jogador com uma derrota imediata (resultado FORFEIT)
+func (m *Match) Forfeit(playerID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seat, err := m.activeSeatOf(playerID)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[MATCH %s] %s desistiu", m.ID, playerID)
+
+	m.Forfeited[seat] = true
+	m.leave(seat, protocol.END_FORFEIT, "OPPONENT_FORFEITED", fmt.Sprintf("O jogador %s desistiu da partida", playerID))
+	return nil
+}
+
+// OfferDraw propõe um empate aos demais jogadores; com uma oferta pendente, vale como aceite.
+// A oferta vale até o fim da rodada atual.
+func (m *Match) OfferDraw(playerID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seat, err := m.activeSeatOf(playerID)
+	if err != nil {
+		return err
+	}
+
+	if m.drawVotes == nil {
+		m.drawVotes = make([]bool, len(m.Players))
+		log.Printf("[MATCH %s] %s ofereceu empate", m.ID, playerID)
+
+		for _, other := range m.activeSeats() {
+			if other != seat {
+				m.emit(m.Players[other], protocol.ServerMsg{T: protocol.DRAW_OFFERED, SenderID: playerID})
+			}
+		}
+	}
+
+	m.voteDraw(seat)
+	return nil
+}
+
+// AcceptDraw aceita a oferta de empate pendente; o empate acontece quando todos os ativos aceitam
+func (m *Match) AcceptDraw(playerID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seat, err := m.activeSeatOf(playerID)
+	if err != nil {
+		return err
+	}
+	if m.drawVotes == nil {
+		return ErrNoDrawOffer
+	}
+
+	m.voteDraw(seat)
+	return nil
+}
+
+// DeclineDraw recusa a oferta de empate pendente e avisa os demais jogadores
+func (m *Match) DeclineDraw(playerID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seat, err := m.activeSeatOf(playerID)
+	if err != nil {
+		return err
+	}
+	if m.drawVotes == nil {
+		return ErrNoDrawOffer
+	}
+
+	m.drawVotes = nil
+	log.Printf("[MATCH %s] %s recusou o empate", m.ID, playerID)
+
+	for _, other := range m.activeSeats() {
+		if other != seat {
+			m.emit(m.Players[other], protocol.ServerMsg{T: protocol.DRAW_DECLINED, SenderID: playerID})
+		}
+	}
+	return nil
+}
+
+// voteDraw registra o aceite do assento e encerra a partida empatada se todos os ativos aceitaram
+// (deve ser chamado com o lock adquirido)
+func (m *Match) voteDraw(seat int) {
+	m.drawVotes[seat] = true
+	for _, other := range m.activeSeats() {
+		if !m.drawVotes[other] {
+			return
+		}
+	}
+
+	// Empate combinado: os ativos empatam, quem já saiu mantém a derrota
+	results := make(map[string]string, len(m.Players))
+	for other, playerID := range m.Players {
+		switch {
+		case m.isActive(other):
+			results[playerID] = protocol.DRAW
+		case m.Forfeited[other]:
+			results[playerID] = protocol.FORFEIT
+		default:
+			results[playerID] = protocol.LOSE
+		}
+	}
+	m.finish(protocol.END_DRAW_AGREED, results)
+}
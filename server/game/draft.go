@@ -0,0 +1,235 @@
+package game
+
+import (
+	"errors"
+	"log"
+	"pingpong/server/protocol"
+	"time"
+)
+
+var (
+	ErrAlreadyPicked = errors.New("você já escolheu uma carta deste pacote")
+	ErrNotInPack     = errors.New("carta não está no pacote")
+)
+
+// draftState guarda o andamento do draft de uma partida
+type draftState struct {
+	packNumber int         // pacote em andamento (1..DraftPacks)
+	pick       int         // escolha em andamento no pacote atual
+	packs      [][]string  // pacote atualmente nas mãos de cada assento
+	pools      [][]string  // cartas já escolhidas por cada assento
+	picked     []bool      // assento já escolheu na vez atual
+	boosters   *PackSystem // pacotes do draft (estoque próprio da partida)
+}
+
+// startDraft abre o primeiro pacote de cada jogador (deve ser chamado com o lock adquirido).
+// Os pacotes saem de um PackSystem da própria partida, com as cartas permitidas pelas regras e
+// estoque para o draft inteiro, semeado pelo gerador da partida
+func (m *Match) startDraft() {
+	config := PackConfig{
+		CardsPerPack: DraftPackSize,
+		Stock:        DraftPacks * len(m.Players),
+		RNGSeed:      m.rng.Int63(),
+	}
+	m.draft = &draftState{
+		pools:    make([][]string, len(m.Players)),
+		picked:   make([]bool, len(m.Players)),
+		boosters: NewPackSystem(config, m.CardDB.Subset(m.cardPool)),
+	}
+	for seat := range m.Players {
+		m.draft.pools[seat] = []string{}
+	}
+
+	m.openDraftPacks()
+}
+
+// openDraftPacks abre um novo pacote para cada assento ativo e inicia a primeira escolha
+func (m *Match) openDraftPacks() {
+	m.draft.packNumber++
+	m.draft.pick = 0
+	m.draft.packs = make([][]string, len(m.Players))
+	for _, seat := range m.activeSeats() {
+		pack, err := m.draft.boosters.OpenPack(m.Players[seat])
+		if err != nil {
+			log.Printf("[MATCH %s] Erro ao abrir pacote do draft: %v", m.ID, err)
+			pack = m.randomCards(DraftPackSize)
+		}
+		m.draft.packs[seat] = pack
+	}
+
+	m.nextDraftPick()
+}
+
+// nextDraftPick abre o prazo da próxima escolha e envia os pacotes aos jogadores
+func (m *Match) nextDraftPick() {
+	m.draft.pick++
+	for seat := range m.draft.picked {
+		m.draft.picked[seat] = false
+	}
+
+	m.armClock(DraftPickTimeout, m.autoPickPending)
+	m.broadcastDraftPacks()
+}
+
+// DraftPick registra a carta escolhida pelo jogador no pacote que está com ele
+func (m *Match) DraftPick(playerID, cardID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	playerIndex := m.GetPlayerIndex(playerID)
+	if playerIndex < 0 || !m.isActive(playerIndex) {
+		return errors.New("jogador não está nesta partida")
+	}
+
+	if m.State != StateDrafting {
+		return ErrNotYourTurn
+	}
+
+	if m.draft.picked[playerIndex] {
+		return ErrAlreadyPicked
+	}
+
+	if !m.takeFromPack(playerIndex, cardID) {
+		return ErrNotInPack
+	}
+	m.markActive(playerIndex)
+	m.record(protocol.ReplayEntry{T: RecordDraftPick, Round: m.draft.packNumber, PlayerID: playerID, CardID: cardID})
+
+	m.advanceDraft()
+
+	return nil
+}
+
+// takeFromPack move a carta do pacote do assento para o seu pool
+func (m *Match) takeFromPack(playerIndex int, cardID string) bool {
+	pack := m.draft.packs[playerIndex]
+	for i, packCardID := range pack {
+		if packCardID == cardID {
+			m.draft.packs[playerIndex] = append(pack[:i], pack[i+1:]...)
+			m.draft.pools[playerIndex] = append(m.draft.pools[playerIndex], cardID)
+			m.draft.picked[playerIndex] = true
+			return true
+		}
+	}
+	return false
+}
+
+// advanceDraft passa os pacotes adiante quando todos escolheram (deve ser chamado com o lock adquirido)
+func (m *Match) advanceDraft() {
+	for _, seat := range m.activeSeats() {
+		if !m.draft.picked[seat] {
+			return
+		}
+	}
+
+	// Pacotes ímpares giram para o próximo assento ativo e pacotes pares para o anterior; o pacote
+	// de quem saiu da partida fica fora da roda
+	active := m.activeSeats()
+	direction := 1
+	if m.draft.packNumber%2 == 0 {
+		direction = len(active) - 1
+	}
+	rotated := make([][]string, len(m.Players))
+	for i, seat := range active {
+		rotated[active[(i+direction)%len(active)]] = m.draft.packs[seat]
+	}
+	m.draft.packs = rotated
+
+	switch {
+	case len(m.draft.packs[active[0]]) > 0:
+		m.nextDraftPick()
+	case m.draft.packNumber < DraftPacks:
+		m.openDraftPacks()
+	default:
+		m.finishDraft()
+	}
+}
+
+// finishDraft transforma o pool de cada jogador em seu deck e abre o mulligan das mãos compradas
+func (m *Match) finishDraft() {
+	for seat, playerID := range m.Players {
+		pool := m.draft.pools[seat]
+		m.emit(playerID, protocol.ServerMsg{T: protocol.DRAFT_DONE, Pool: pool})
+
+		m.Decks[seat] = append([]string{}, pool...)
+		m.rng.Shuffle(len(m.Decks[seat]), func(i, j int) {
+			m.Decks[seat][i], m.Decks[seat][j] = m.Decks[seat][j], m.Decks[seat][i]
+		})
+		m.Hands[seat] = Hand{}
+	}
+	m.draft = nil
+
+	log.Printf("[MATCH %s] Draft finalizado", m.ID)
+
+	m.refillHands()
+	m.record(protocol.ReplayEntry{T: RecordDeal, Hands: m.handsSnapshot()})
+	m.startMulligan()
+}
+
+// autoPickPending escolhe uma carta aleatória para quem não escolheu dentro do prazo (deve ser chamado com o lock adquirido)
+func (m *Match) autoPickPending() {
+	if m.State != StateDrafting {
+		return
+	}
+
+	for _, seat := range m.activeSeats() {
+		pack := m.draft.packs[seat]
+		if m.draft.picked[seat] || len(pack) == 0 {
+			continue
+		}
+		if m.checkAFK(seat) {
+			if m.State == StateEnded {
+				return
+			}
+			continue
+		}
+		cardID := pack[m.rng.Intn(len(pack))]
+		m.takeFromPack(seat, cardID)
+		m.record(protocol.ReplayEntry{T: RecordDraftPick, Round: m.draft.packNumber, PlayerID: m.Players[seat], CardID: cardID, Auto: true})
+
+		log.Printf("[MATCH %s] Auto-pick para %s: %s", m.ID, m.Players[seat], cardID)
+		m.warnAFK(seat)
+	}
+
+	m.advanceDraft()
+}
+
+// broadcastDraftPacks envia a cada jogador o pacote atual e o pool escolhido até agora
+func (m *Match) broadcastDraftPacks() {
+	deadlineMs := time.Until(m.Deadline).Milliseconds()
+
+	for seat, playerID := range m.Players {
+		m.emit(playerID, protocol.ServerMsg{
+			T:          protocol.DRAFT_PACK,
+			Cards:      m.draft.packs[seat],
+			Pool:       m.draft.pools[seat],
+			Round:      m.draft.packNumber,
+			Pick:       m.draft.pick,
+			DeadlineMs: deadlineMs,
+		})
+	}
+}
+
+// drawCard compra a próxima carta do deck do jogador; sem deck (política RANDOM), sorteia entre as
+// cartas permitidas.
+// Com o deck vazio, o descarte é embaralhado e volta a ser o deck.
+func (m *Match) drawCard(playerIndex int) string {
+	if m.Decks[playerIndex] == nil {
+		return m.randomCard()
+	}
+
+	if len(m.Decks[playerIndex]) == 0 {
+		m.Decks[playerIndex] = m.Discard[playerIndex]
+		m.Discard[playerIndex] = []string{}
+		m.rng.Shuffle(len(m.Decks[playerIndex]), func(i, j int) {
+			m.Decks[playerIndex][i], m.Decks[playerIndex][j] = m.Decks[playerIndex][j], m.Decks[playerIndex][i]
+		})
+	}
+	if len(m.Decks[playerIndex]) == 0 {
+		return ""
+	}
+
+	cardID := m.Decks[playerIndex][0]
+	m.Decks[playerIndex] = m.Decks[playerIndex][1:]
+	return cardID
+}
@@ -0,0 +1,88 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+// newDraftMatch cria uma partida todos contra todos com deck em draft, parada na primeira escolha
+func newDraftMatch(t *testing.T, players ...string) *Match {
+	t.Helper()
+
+	rules := DefaultRules()
+	rules.DeckPolicy = DeckDraft
+	match := NewMatch("m_draft", players, testCards(t), ModeFreeForAll, 1, rules)
+	t.Cleanup(func() {
+		match.mu.Lock()
+		defer match.mu.Unlock()
+		match.stopClock()
+	})
+
+	match.Start()
+	return match
+}
+
+// pickFirst faz cada assento ativo que ainda não escolheu pegar a primeira carta do pacote
+func pickFirst(t *testing.T, match *Match) {
+	t.Helper()
+
+	for _, seat := range match.activeSeats() {
+		if match.draft.picked[seat] {
+			continue
+		}
+		if err := match.DraftPick(match.Players[seat], match.draft.packs[seat][0]); err != nil {
+			t.Fatalf("Escolha de %s rejeitada: %v", match.Players[seat], err)
+		}
+		if match.State != StateDrafting {
+			return
+		}
+	}
+}
+
+func TestDraftPassesPacks(t *testing.T) {
+	match := newDraftMatch(t, "p1", "p2", "p3")
+
+	if match.State != StateDrafting || match.draft.boosters.GetStock() != (DraftPacks-1)*3 {
+		t.Fatalf("Draft deveria abrir um pacote por assento (estado %s)", match.State)
+	}
+	before := make([][]string, 3)
+	for seat, pack := range match.draft.packs {
+		if len(pack) != DraftPackSize {
+			t.Fatalf("Pacote de %d cartas, esperado %d", len(pack), DraftPackSize)
+		}
+		before[seat] = append([]string{}, pack[1:]...)
+	}
+
+	// O primeiro pacote gira para o próximo assento, sem a carta escolhida
+	pickFirst(t, match)
+	for seat := range match.Players {
+		if !reflect.DeepEqual(match.draft.packs[(seat+1)%3], before[seat]) {
+			t.Errorf("Pacote de p%d não passou para o próximo assento: %v", seat+1, match.draft.packs[(seat+1)%3])
+		}
+	}
+}
+
+func TestDraftContinuesAfterSeatLeaves(t *testing.T) {
+	match := newDraftMatch(t, "p1", "p2", "p3")
+
+	pickFirst(t, match)
+	match.RemovePlayer("p1")
+
+	// Os pacotes passam só entre os assentos ativos até o fim do draft
+	for i := 0; match.State == StateDrafting; i++ {
+		if i > DraftPacks*DraftPackSize {
+			t.Fatalf("Draft travou no pacote %d, escolha %d", match.draft.packNumber, match.draft.pick)
+		}
+		pickFirst(t, match)
+	}
+
+	if match.State != StateMulligan {
+		t.Fatalf("Estado esperado %s, obtido %s", StateMulligan, match.State)
+	}
+	for seat := 1; seat < 3; seat++ {
+		if len(match.Decks[seat])+len(match.Hands[seat]) != DraftPacks*DraftPackSize {
+			t.Errorf("p%d deveria ter %d cartas do draft, tem %d", seat+1, DraftPacks*DraftPackSize,
+				len(match.Decks[seat])+len(match.Hands[seat]))
+		}
+	}
+}
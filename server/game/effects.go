@@ -0,0 +1,132 @@
+package game
+
+import (
+	"fmt"
+	"pingpong/server/protocol"
+)
+
+// hasStatus verifica se o jogador possui um efeito de status ativo
+func (m *Match) hasStatus(playerIndex int, statusType StatusType) bool {
+	for _, effect := range m.Effects[playerIndex] {
+		if effect.Type == statusType {
+			return true
+		}
+	}
+	return false
+}
+
+// applyCardEffect aplica o efeito da carta jogada (REGEN no próprio jogador, os demais no alvo)
+func (m *Match) applyCardEffect(playerIndex int, card Card) {
+	if card.Effect == nil {
+		return
+	}
+
+	target := m.Targets[playerIndex]
+	if card.Effect.Type == REGEN {
+		target = playerIndex
+	}
+
+	// Alvo eliminado nesta rodada não recebe novos efeitos
+	if m.HP[target] <= 0 {
+		return
+	}
+
+	// Jogador que acabou de pular a jogada fica imune a um novo congelamento
+	if card.Effect.Type == FREEZE && m.skippedPlay(target) {
+		m.logStatus(target, "%s resistiu ao congelamento.")
+		return
+	}
+
+	m.Effects[target] = addStatus(m.Effects[target], *card.Effect)
+
+	if target == playerIndex {
+		m.logStatus(target, "%s recebeu %s (%d rodadas).", card.Effect.Type, card.Effect.Duration)
+	} else {
+		m.logStatus(target, "%s foi afetado por %s (%d rodadas).", card.Effect.Type, card.Effect.Duration)
+	}
+}
+
+// addStatus adiciona um efeito à lista, acumulando stacks se o tipo já estiver ativo
+func addStatus(effects []StatusEffect, effect Effect) []StatusEffect {
+	for i := range effects {
+		if effects[i].Type == effect.Type {
+			effects[i].Stacks = min(effects[i].Stacks+effect.Stacks, MaxStatusStacks)
+			effects[i].Duration = max(effects[i].Duration, effect.Duration)
+			return effects
+		}
+	}
+
+	return append(effects, StatusEffect{
+		Type:     effect.Type,
+		Duration: effect.Duration,
+		Stacks:   min(effect.Stacks, MaxStatusStacks),
+	})
+}
+
+// tickStatusEffects aplica o efeito por rodada de cada status e decrementa sua duração
+func (m *Match) tickStatusEffects(playing []int) {
+	for _, playerIndex := range playing {
+		remaining := []StatusEffect{}
+
+		for _, effect := range m.Effects[playerIndex] {
+			switch effect.Type {
+			case BURN:
+				dmg := BurnDamagePerStack * effect.Stacks
+				m.damage(playerIndex, dmg)
+				m.logStatus(playerIndex, "%s sofreu %d de dano de queimadura!", dmg)
+			case POISON:
+				dmg := PoisonDamagePerStack * effect.Stacks
+				m.damage(playerIndex, dmg)
+				m.logStatus(playerIndex, "%s sofreu %d de dano de veneno!", dmg)
+			case REGEN:
+				if heal := m.heal(playerIndex, RegenHealPerStack*effect.Stacks); heal > 0 {
+					m.logStatus(playerIndex, "%s regenerou %d de HP!", heal)
+				}
+			}
+
+			effect.Duration--
+			if effect.Duration > 0 {
+				remaining = append(remaining, effect)
+			}
+		}
+
+		m.Effects[playerIndex] = remaining
+	}
+}
+
+// skippedPlay verifica se o jogador pulou a jogada da rodada atual por estar congelado
+func (m *Match) skippedPlay(playerIndex int) bool {
+	cardID, played := m.Waiting[m.Players[playerIndex]]
+	return played && cardID == "" && m.actionOf(playerIndex) != ActionCycle
+}
+
+// passFrozenPlayers registra uma jogada vazia (auto-pass) para jogadores congelados
+func (m *Match) passFrozenPlayers() {
+	for _, playerIndex := range m.activeSeats() {
+		if m.hasStatus(playerIndex, FREEZE) {
+			m.Waiting[m.Players[playerIndex]] = ""
+		}
+	}
+}
+
+// logStatus registra uma mensagem de status na perspectiva de cada jogador; o formato
+// começa com o sujeito (%s), que vira "Você" para o afetado e o rótulo do assento para os demais
+func (m *Match) logStatus(playerIndex int, format string, args ...any) {
+	for viewer := range m.Players {
+		msg := fmt.Sprintf(format, append([]any{m.seatLabel(viewer, playerIndex)}, args...)...)
+		m.statusLogs[viewer] = append(m.statusLogs[viewer], msg)
+	}
+}
+
+// statusViews converte os efeitos ativos do jogador para o formato do protocolo
+func (m *Match) statusViews(playerIndex int) []protocol.StatusView {
+	views := []protocol.StatusView{}
+	for _, effect := range m.Effects[playerIndex] {
+		views = append(views, protocol.StatusView{
+			Type:     string(effect.Type),
+			Duration: effect.Duration,
+			Stacks:   effect.Stacks,
+		})
+	}
+	return views
+}
@@ -0,0 +1,80 @@
+package game
+
+import "testing"
+
+func TestBurnTicksAndExpires(t *testing.T) {
+	match := newRoundMatch(t, ModeSimultaneous,
+		Hand{"c_001", "c_004", "c_004", "c_004", "c_004"}, // Fire Dragon (BURN 2 rodadas)
+		Hand{"c_004", "c_004", "c_004", "c_004", "c_004"}, // Flame Warrior (ATK 6 / DEF 7)
+	)
+
+	// Rodada 1: o BURN é aplicado depois do tick e só age a partir da próxima rodada
+	mustPlay(t, match, "p1", "c_001")
+	mustPlay(t, match, "p2", "c_004")
+	if len(match.Effects[1]) != 1 || match.Effects[1][0].Type != BURN || match.Effects[1][0].Duration != 2 {
+		t.Fatalf("p2 deveria estar com BURN por 2 rodadas, está com %+v", match.Effects[1])
+	}
+
+	// Rodadas 2 e 3: Flame Warrior contra Flame Warrior não causa dano, só a queimadura age
+	for round := 2; round <= 3; round++ {
+		hp := match.HP[1]
+		mustPlay(t, match, "p1", "c_004")
+		mustPlay(t, match, "p2", "c_004")
+		if lost := hp - match.HP[1]; lost != BurnDamagePerStack {
+			t.Errorf("Rodada %d: BURN deveria causar %d de dano, causou %d", round, BurnDamagePerStack, lost)
+		}
+	}
+	if len(match.Effects[1]) != 0 {
+		t.Fatalf("BURN deveria ter expirado, p2 está com %+v", match.Effects[1])
+	}
+
+	// Rodada 4: efeito expirado não causa mais dano
+	hp := match.HP[1]
+	mustPlay(t, match, "p1", "c_004")
+	mustPlay(t, match, "p2", "c_004")
+	if match.HP[1] != hp {
+		t.Errorf("BURN expirado ainda causou %d de dano", hp-match.HP[1])
+	}
+}
+
+func TestFreezeSkipsOneRound(t *testing.T) {
+	match := newRoundMatch(t, ModeSimultaneous,
+		Hand{"c_002", "c_002", "c_004", "c_004", "c_004"}, // Ice Mage (FREEZE 1 rodada)
+		Hand{"c_004", "c_004", "c_004", "c_004", "c_004"},
+	)
+
+	mustPlay(t, match, "p1", "c_002")
+	mustPlay(t, match, "p2", "c_004")
+
+	// Rodada 2: p2 congelado passa a vez; p1 tenta congelar de novo quem acabou de pular
+	if err := match.Apply(Play{PlayerID: "p2", CardID: "c_004", Lock: true}); err == nil {
+		t.Fatal("Jogador congelado conseguiu jogar")
+	}
+	mustPlay(t, match, "p1", "c_002")
+	if match.Round != 3 {
+		t.Fatalf("Rodada deveria avançar só com a jogada de p1, está na rodada %d", match.Round)
+	}
+	if match.hasStatus(1, FREEZE) {
+		t.Error("Jogador que acabou de pular a jogada não deveria ser congelado de novo")
+	}
+
+	// Rodada 3: o congelamento expirou e p2 volta a jogar
+	mustPlay(t, match, "p2", "c_004")
+}
+
+func TestStatusStacksAreCapped(t *testing.T) {
+	effects := []StatusEffect{}
+	for i := 0; i < MaxStatusStacks+2; i++ {
+		effects = addStatus(effects, Effect{Type: POISON, Duration: 1 + i%2, Stacks: 1})
+	}
+
+	if len(effects) != 1 {
+		t.Fatalf("Efeitos do mesmo tipo deveriam acumular em um só, obtidos %+v", effects)
+	}
+	if effects[0].Stacks != MaxStatusStacks {
+		t.Errorf("Stacks esperados %d, obtidos %d", MaxStatusStacks, effects[0].Stacks)
+	}
+	if effects[0].Duration != 2 {
+		t.Errorf("A duração deveria ser a maior aplicada (2), obtida %d", effects[0].Duration)
+	}
+}
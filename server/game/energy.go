@@ -0,0 +1,45 @@
+package game
+
+import "errors"
+
+var (
+	ErrNotEnoughEnergy = errors.New("energia insuficiente para jogar esta carta")
+)
+
+// gainEnergy aumenta a energia máxima e recupera energia no início de uma nova rodada
+func (m *Match) gainEnergy() {
+	for _, playerIndex := range m.activeSeats() {
+		m.MaxEnergy[playerIndex] = min(m.MaxEnergy[playerIndex]+EnergyMaxGrowth, EnergyCap)
+		m.Energy[playerIndex] = min(m.Energy[playerIndex]+EnergyRegen, m.MaxEnergy[playerIndex])
+	}
+}
+
+// spendEnergy desconta o custo da carta jogada
+func (m *Match) spendEnergy(playerIndex int, card Card) {
+	m.Energy[playerIndex] -= card.Cost
+}
+
+// affordableCards retorna as cartas da mão que o jogador consegue pagar
+func (m *Match) affordableCards(playerIndex int) []string {
+	affordable := []string{}
+	for _, cardID := range m.Hands[playerIndex] {
+		card, exists := m.CardDB.GetCard(cardID)
+		if exists && card.Cost <= m.Energy[playerIndex] {
+			affordable = append(affordable, cardID)
+		}
+	}
+	return affordable
+}
+
+// passBrokePlayers registra uma jogada vazia (auto-pass) para jogadores sem energia para nenhuma carta
+func (m *Match) passBrokePlayers() {
+	for _, playerIndex := range m.activeSeats() {
+		playerID := m.Players[playerIndex]
+		if _, played := m.Waiting[playerID]; played {
+			continue
+		}
+		if len(m.affordableCards(playerIndex)) == 0 {
+			m.Waiting[playerID] = ""
+		}
+	}
+}
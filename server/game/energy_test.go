@@ -0,0 +1,66 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEnergyCostAndRegen(t *testing.T) {
+	match := newRoundMatch(t, ModeSimultaneous,
+		Hand{"c_007", "c_007", "c_007", "c_007", "c_007"}, // Inferno Titan (custo 4)
+		Hand{"c_006", "c_006", "c_006", "c_006", "c_006"}, // Forest Guardian (custo 1)
+	)
+	// Reposições também custam 4: sem energia, p1 não tem carta pagável
+	match.Decks[0] = []string{"c_007", "c_007", "c_007", "c_007", "c_007"}
+
+	// Rodada 1: p1 gasta toda a energia inicial e recupera EnergyRegen
+	mustPlay(t, match, "p1", "c_007")
+	mustPlay(t, match, "p2", "c_006")
+	if match.Energy[0] != EnergyRegen || match.MaxEnergy[0] != EnergyStart+EnergyMaxGrowth {
+		t.Fatalf("Energia esperada %d/%d, obtida %d/%d",
+			EnergyRegen, EnergyStart+EnergyMaxGrowth, match.Energy[0], match.MaxEnergy[0])
+	}
+
+	// Rodada 2: a carta não é pagável e p1 passa a vez automaticamente
+	err := match.Apply(Play{PlayerID: "p1", CardID: "c_007", Lock: true})
+	if !errors.Is(err, ErrNotEnoughEnergy) {
+		t.Fatalf("Esperado ErrNotEnoughEnergy, obtido %v", err)
+	}
+	mustPlay(t, match, "p2", "c_006")
+	if match.Round != 3 {
+		t.Fatalf("p1 sem energia deveria passar a vez, partida na rodada %d", match.Round)
+	}
+
+	// Rodadas 3 a 5: p1 volta a pagar a carta e a energia máxima para de crescer no limite
+	for round := 3; round <= 5; round++ {
+		if round != 4 {
+			mustPlay(t, match, "p1", "c_007")
+		}
+		mustPlay(t, match, "p2", "c_006")
+		if want := min(EnergyStart+round*EnergyMaxGrowth, EnergyCap); match.MaxEnergy[1] != want {
+			t.Errorf("Rodada %d: energia máxima esperada %d, obtida %d", round, want, match.MaxEnergy[1])
+		}
+	}
+	if match.State == StateEnded {
+		t.Fatal("Partida terminou antes do esperado")
+	}
+}
+
+func TestAffordableCards(t *testing.T) {
+	match := newRoundMatch(t, ModeSimultaneous,
+		Hand{"c_007", "c_001", "c_004", "c_006", "c_007"},
+		Hand{"c_004", "c_004", "c_004", "c_004", "c_004"},
+	)
+
+	match.Energy[0] = 2
+	affordable := match.affordableCards(0)
+	if len(affordable) != 2 || affordable[0] != "c_004" || affordable[1] != "c_006" {
+		t.Errorf("Com 2 de energia, só c_004 e c_006 são pagáveis; obtido %v", affordable)
+	}
+
+	// O auto-play só escolhe cartas pagáveis
+	match.AutoplayIfNeeded()
+	if cardID := match.Discard[0][0]; cardID != "c_004" && cardID != "c_006" {
+		t.Errorf("Auto-play jogou carta não pagável: %s", cardID)
+	}
+}
@@ -0,0 +1,42 @@
+package game
+
+import "pingpong/server/protocol"
+
+// Event é uma mensagem emitida pela partida para um jogador ou um registro da partida (replays)
+type Event struct {
+	MatchID  string
+	PlayerID string // destinatário (assento que recebe a visão); vazio em registros
+	Msg      protocol.ServerMsg
+	Record   *protocol.ReplayEntry // registro da partida; nil em mensagens para jogadores
+}
+
+// Observer recebe os eventos de uma partida (rede, bots, espectadores, replays).
+// OnEvent é chamado com o lock da partida adquirido: o observer não deve chamar a partida de volta
+// na mesma goroutine.
+type Observer interface {
+	OnEvent(event Event)
+}
+
+// ObserverFunc adapta uma função para a interface Observer
+type ObserverFunc func(event Event)
+
+// OnEvent chama a função adaptada
+func (f ObserverFunc) OnEvent(event Event) {
+	f(event)
+}
+
+// Subscribe registra um observer para os próximos eventos da partida
+func (m *Match) Subscribe(observer Observer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.observers = append(m.observers, observer)
+}
+
+// emit entrega uma mensagem destinada a um jogador a todos os observers
+func (m *Match) emit(playerID string, msg protocol.ServerMsg) {
+	event := Event{MatchID: m.ID, PlayerID: playerID, Msg: msg}
+	for _, observer := range m.observers {
+		observer.OnEvent(event)
+	}
+}
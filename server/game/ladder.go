@@ -0,0 +1,373 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"pingpong/server/protocol"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Constantes do ranking
+const (
+	InitialRating       = 1200                // rating de quem ainda não jogou partidas ranqueadas
+	EloK                = 32                  // variação máxima de rating por partida
+	LeaderboardPageSize = 10                  // jogadores por página do LEADERBOARD
+	SeasonLength        = 28 * 24 * time.Hour // duração padrão de uma temporada
+)
+
+// Division é a divisão do ranking, definida pela faixa de rating
+type Division string
+
+const (
+	DivisionBronze   Division = "BRONZE"
+	DivisionSilver   Division = "SILVER"
+	DivisionGold     Division = "GOLD"
+	DivisionPlatinum Division = "PLATINUM"
+	DivisionDiamond  Division = "DIAMOND"
+)
+
+// divisions lista as divisões da maior para a menor, com o rating mínimo e as moedas recebidas ao fim
+// da temporada
+var divisions = []struct {
+	division  Division
+	minRating int
+	coins     int
+}{
+	{DivisionDiamond, 1700, 500},
+	{DivisionPlatinum, 1500, 300},
+	{DivisionGold, 1300, 200},
+	{DivisionSilver, 1100, 100},
+	{DivisionBronze, 0, 50},
+}
+
+// DivisionOf retorna a divisão do rating
+func DivisionOf(rating int) Division {
+	for _, tier := range divisions {
+		if rating >= tier.minRating {
+			return tier.division
+		}
+	}
+	return DivisionBronze
+}
+
+// seasonCoins retorna as moedas da recompensa de fim de temporada da divisão
+func seasonCoins(division Division) int {
+	for _, tier := range divisions {
+		if tier.division == division {
+			return tier.coins
+		}
+	}
+	return 0
+}
+
+// ladderAccount é o rating de um jogador e seu desempenho na temporada atual
+type ladderAccount struct {
+	Rating int `json:"rating"`
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Draws  int `json:"draws"`
+}
+
+// played retorna quantas partidas ranqueadas o jogador jogou na temporada
+func (a *ladderAccount) played() int {
+	return a.Wins + a.Losses + a.Draws
+}
+
+// ladderState é o estado gravado do ranking
+type ladderState struct {
+	Season      int                       `json:"season"`
+	SeasonStart time.Time                 `json:"seasonStart"`
+	SeasonEnd   time.Time                 `json:"seasonEnd"`
+	Accounts    map[string]*ladderAccount `json:"accounts"`
+}
+
+// ladderResult é o registro durável (linha JSONL) do resultado de uma partida ranqueada
+type ladderResult struct {
+	TS      int64             `json:"ts"`
+	MatchID string            `json:"matchId"`
+	Season  int               `json:"season"`
+	Reason  string            `json:"reason"`
+	Results map[string]string `json:"results"`
+	Before  map[string]int    `json:"before"`
+	After   map[string]int    `json:"after"`
+}
+
+// Ladder é o ranking Elo persistente das partidas ranqueadas 1v1, com temporadas. O estado fica em
+// ladder.json no diretório e cada resultado é acrescentado a results.jsonl antes de alterar o rating
+type Ladder struct {
+	dir          string
+	seasonLength time.Duration
+	state        ladderState
+	mu           sync.Mutex
+}
+
+// OpenLadder abre o ranking gravado no diretório (inexistente = primeira temporada começando agora)
+func OpenLadder(dir string, seasonLength time.Duration, now time.Time) (*Ladder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório do ranking: %w", err)
+	}
+	if seasonLength <= 0 {
+		seasonLength = SeasonLength
+	}
+
+	ladder := &Ladder{
+		dir:          dir,
+		seasonLength: seasonLength,
+		state: ladderState{
+			Season:      1,
+			SeasonStart: now,
+			SeasonEnd:   now.Add(seasonLength),
+			Accounts:    make(map[string]*ladderAccount),
+		},
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "ladder.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return ladder, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler ranking: %w", err)
+	}
+	if err := json.Unmarshal(data, &ladder.state); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar ranking: %w", err)
+	}
+	if ladder.state.Accounts == nil {
+		ladder.state.Accounts = make(map[string]*ladderAccount)
+	}
+	return ladder, nil
+}
+
+// RankedModeAllowed verifica se o modo aceita partidas ranqueadas (1v1 entre dois jogadores)
+func RankedModeAllowed(mode MatchMode) bool {
+	config := ModeConfigs[mode]
+	return config.MaxPlayers == 2 && !config.Boss
+}
+
+// Record registra o resultado de uma partida ranqueada (os resultados do registro END) e atualiza o
+// rating Elo dos dois jogadores; retorna a variação de cada um
+func (l *Ladder) Record(matchID, reason string, results map[string]string, now time.Time) ([]protocol.RatingChange, error) {
+	if len(results) != 2 {
+		return nil, fmt.Errorf("partida ranqueada com %d jogadores", len(results))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	playerIDs := make([]string, 0, 2)
+	for playerID := range results {
+		playerIDs = append(playerIDs, playerID)
+	}
+	sort.Strings(playerIDs)
+	a, b := l.account(playerIDs[0]), l.account(playerIDs[1])
+
+	// A variação é calculada para o primeiro jogador; o segundo recebe a oposta
+	expected := 1 / (1 + math.Pow(10, float64(b.Rating-a.Rating)/400))
+	delta := int(math.Round(EloK * (score(results[playerIDs[0]]) - expected)))
+
+	entry := ladderResult{
+		TS:      now.UnixMilli(),
+		MatchID: matchID,
+		Season:  l.state.Season,
+		Reason:  reason,
+		Results: results,
+		Before:  map[string]int{playerIDs[0]: a.Rating, playerIDs[1]: b.Rating},
+		After:   map[string]int{playerIDs[0]: a.Rating + delta, playerIDs[1]: b.Rating - delta},
+	}
+	if err := l.appendResult(entry); err != nil {
+		return nil, err
+	}
+
+	changes := make([]protocol.RatingChange, 0, 2)
+	for i, account := range []*ladderAccount{a, b} {
+		playerID := playerIDs[i]
+		account.Rating = entry.After[playerID]
+		switch results[playerID] {
+		case protocol.WIN:
+			account.Wins++
+		case protocol.DRAW:
+			account.Draws++
+		default:
+			account.Losses++
+		}
+		changes = append(changes, protocol.RatingChange{
+			PlayerID: playerID,
+			Season:   l.state.Season,
+			Rating:   account.Rating,
+			Delta:    account.Rating - entry.Before[playerID],
+			Division: string(DivisionOf(account.Rating)),
+		})
+	}
+	return changes, l.save()
+}
+
+// score converte o resultado da partida na pontuação do Elo
+func score(result string) float64 {
+	switch result {
+	case protocol.WIN:
+		return 1
+	case protocol.DRAW:
+		return 0.5
+	}
+	return 0
+}
+
+// Leaderboard retorna uma página do ranking da temporada (a partir de 1; além da última, a última)
+// e a posição do jogador, se já jogou partidas ranqueadas na temporada
+func (l *Ladder) Leaderboard(playerID string, page int, now time.Time) protocol.LeaderboardView {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := l.standings()
+	pages := max(1, (len(entries)+LeaderboardPageSize-1)/LeaderboardPageSize)
+	page = min(max(page, 1), pages)
+
+	remaining := l.state.SeasonEnd.Sub(now).Milliseconds()
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	view := protocol.LeaderboardView{
+		Season:      l.state.Season,
+		SeasonEndMs: remaining,
+		Page:        page,
+		Pages:       pages,
+		Entries:     entries[min((page-1)*LeaderboardPageSize, len(entries)):min(page*LeaderboardPageSize, len(entries))],
+	}
+	for i := range entries {
+		if entries[i].PlayerID == playerID {
+			view.You = &entries[i]
+		}
+	}
+	return view
+}
+
+// EndSeasonIfDue encerra a temporada vencida: quem jogou nela recebe a recompensa da divisão final, e
+// todos os ratings se aproximam do inicial pela metade (soft reset). Retorna os resultados da
+// temporada encerrada (nenhum se ainda não terminou)
+func (l *Ladder) EndSeasonIfDue(now time.Time) ([]protocol.SeasonResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Before(l.state.SeasonEnd) {
+		return nil, nil
+	}
+
+	results := []protocol.SeasonResult{}
+	for _, entry := range l.standings() {
+		division := Division(entry.Division)
+		results = append(results, protocol.SeasonResult{
+			PlayerID: entry.PlayerID,
+			Season:   l.state.Season,
+			Rank:     entry.Rank,
+			Rating:   entry.Rating,
+			Division: entry.Division,
+			Coins:    seasonCoins(division),
+		})
+	}
+
+	for playerID, account := range l.state.Accounts {
+		account.Rating = InitialRating + (account.Rating-InitialRating)/2
+		account.Wins, account.Losses, account.Draws = 0, 0, 0
+		for i := range results {
+			if results[i].PlayerID == playerID {
+				results[i].NewRating = account.Rating
+			}
+		}
+	}
+
+	l.state.Season++
+	l.state.SeasonStart = now
+	l.state.SeasonEnd = now.Add(l.seasonLength)
+	return results, l.save()
+}
+
+// Rating retorna o rating atual do jogador (InitialRating se ainda não jogou partidas ranqueadas)
+func (l *Ladder) Rating(playerID string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if account, ok := l.state.Accounts[playerID]; ok {
+		return account.Rating
+	}
+	return InitialRating
+}
+
+// standings ordena quem jogou na temporada por rating (empates pelo ID) e numera as posições
+// (deve ser chamado com o lock adquirido)
+func (l *Ladder) standings() []protocol.LadderEntry {
+	entries := []protocol.LadderEntry{}
+	for playerID, account := range l.state.Accounts {
+		if account.played() == 0 {
+			continue
+		}
+		entries = append(entries, protocol.LadderEntry{
+			PlayerID: playerID,
+			Rating:   account.Rating,
+			Division: string(DivisionOf(account.Rating)),
+			Wins:     account.Wins,
+			Losses:   account.Losses,
+			Draws:    account.Draws,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Rating != entries[j].Rating {
+			return entries[i].Rating > entries[j].Rating
+		}
+		return entries[i].PlayerID < entries[j].PlayerID
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+	return entries
+}
+
+// account retorna a conta do jogador, criando-a com o rating inicial (deve ser chamado com o lock adquirido)
+func (l *Ladder) account(playerID string) *ladderAccount {
+	account, ok := l.state.Accounts[playerID]
+	if !ok {
+		account = &ladderAccount{Rating: InitialRating}
+		l.state.Accounts[playerID] = account
+	}
+	return account
+}
+
+// appendResult acrescenta o resultado ao histórico e o sincroniza com o disco
+// (deve ser chamado com o lock adquirido)
+func (l *Ladder) appendResult(entry ladderResult) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("erro ao codificar resultado ranqueado: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(l.dir, "results.jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir histórico ranqueado: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("erro ao gravar resultado ranqueado: %w", err)
+	}
+	return file.Sync()
+}
+
+// save grava o estado do ranking, substituindo o arquivo de uma só vez (deve ser chamado com o lock adquirido)
+func (l *Ladder) save() error {
+	data, err := json.MarshalIndent(l.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao codificar ranking: %w", err)
+	}
+
+	path := filepath.Join(l.dir, "ladder.json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("erro ao gravar ranking: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,87 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"pingpong/server/protocol"
+	"testing"
+	"time"
+)
+
+func TestLadderFiles(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ladder, err := OpenLadder(dir, 7*24*time.Hour, start)
+	if err != nil {
+		t.Fatalf("Erro ao abrir ranking: %v", err)
+	}
+
+	// Entre ratings iguais, a vitória vale metade do K
+	changes, err := ladder.Record("m_1", protocol.END_HP, map[string]string{"ana": protocol.WIN, "bia": protocol.LOSE}, start)
+	if err != nil {
+		t.Fatalf("Erro ao registrar resultado: %v", err)
+	}
+	if changes[0].PlayerID != "ana" || changes[0].Delta != EloK/2 || changes[1].Delta != -EloK/2 {
+		t.Fatalf("Variação de ±%d esperada, obtida %+v", EloK/2, changes)
+	}
+	if _, err := ladder.Record("m_2", protocol.END_HP, map[string]string{"ana": protocol.DRAW, "bia": protocol.DRAW}, start); err != nil {
+		t.Fatalf("Erro ao registrar empate: %v", err)
+	}
+
+	// results.jsonl guarda cada partida com os ratings antes e depois
+	file, err := os.Open(filepath.Join(dir, "results.jsonl"))
+	if err != nil {
+		t.Fatalf("Histórico ranqueado não gravado: %v", err)
+	}
+	defer file.Close()
+	lines := []ladderResult{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry ladderResult
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Linha inválida no histórico: %v", err)
+		}
+		lines = append(lines, entry)
+	}
+	if len(lines) != 2 || lines[0].MatchID != "m_1" || lines[0].Before["ana"] != InitialRating || lines[1].Before["ana"] != lines[0].After["ana"] {
+		t.Fatalf("Histórico com as duas partidas encadeadas esperado, obtido %+v", lines)
+	}
+
+	// ladder.json restaura ratings e desempenho da temporada
+	reopened, err := OpenLadder(dir, 7*24*time.Hour, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Erro ao reabrir ranking: %v", err)
+	}
+	rating := ladder.Rating("ana")
+	if reopened.Rating("ana") != rating || reopened.Rating("bia") != ladder.Rating("bia") {
+		t.Fatalf("Ratings diferentes após reabrir: ana %d/%d", rating, reopened.Rating("ana"))
+	}
+	board := reopened.Leaderboard("bia", 1, start.Add(time.Hour))
+	if len(board.Entries) != 2 || board.Entries[0].PlayerID != "ana" || board.You == nil || board.You.Rank != 2 || board.You.Draws != 1 {
+		t.Fatalf("Ranking com ana em primeiro e bia em segundo esperado, obtido %+v", board)
+	}
+
+	// Antes do prazo a temporada continua; depois, os ratings se aproximam do inicial pela metade
+	if results, err := reopened.EndSeasonIfDue(start.Add(24 * time.Hour)); err != nil || len(results) != 0 {
+		t.Fatalf("Temporada não deveria terminar antes do prazo: %+v (%v)", results, err)
+	}
+	results, err := reopened.EndSeasonIfDue(start.Add(7 * 24 * time.Hour))
+	if err != nil || len(results) != 2 {
+		t.Fatalf("Resultados da temporada esperados, obtidos %+v (%v)", results, err)
+	}
+	if want := InitialRating + (rating-InitialRating)/2; results[0].PlayerID != "ana" || results[0].NewRating != want {
+		t.Errorf("Soft reset de ana esperado para %d, obtido %+v", want, results[0])
+	}
+
+	// A nova temporada também é gravada
+	next, err := OpenLadder(dir, 7*24*time.Hour, start.Add(8*24*time.Hour))
+	if err != nil {
+		t.Fatalf("Erro ao reabrir ranking: %v", err)
+	}
+	board = next.Leaderboard("ana", 1, start.Add(8*24*time.Hour))
+	if board.Season != 2 || len(board.Entries) != 0 {
+		t.Errorf("Temporada 2 sem partidas esperada, obtida %+v", board)
+	}
+}
@@ -0,0 +1,270 @@
+package game
+
+import (
+	"errors"
+	"math/rand"
+	"pingpong/server/protocol"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrLobbyNotFound = errors.New("sala não encontrada")
+	ErrWrongPassword = errors.New("senha da sala incorreta")
+	ErrNoSpectators  = errors.New("a sala não aceita espectadores")
+)
+
+// Parâmetros dos códigos de convite (salas personalizadas e desafios por código)
+const (
+	CodeLength   = 6
+	CodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // sem caracteres ambíguos (0/O, 1/I)
+)
+
+// Lobby representa uma sala personalizada: a partida começa quando todos os assentos do modo são ocupados
+type Lobby struct {
+	Code       string
+	HostID     string
+	Mode       MatchMode
+	Rules      Rules
+	Password   string   // senha exigida no JOIN_LOBBY (vazia = sala aberta)
+	Spectators bool     // o anfitrião aceita espectadores
+	Players    []string // IDs dos jogadores, na ordem de entrada (o primeiro é o anfitrião)
+	Watchers   []string // IDs dos espectadores, que acompanham a partida quando ela começa
+}
+
+// Full verifica se a sala ocupou todos os assentos do modo
+func (l *Lobby) Full() bool {
+	return len(l.Players) >= ModeConfigs[l.Mode].MaxPlayers
+}
+
+// View resume a sala para o navegador de salas (sem revelar a senha)
+func (l *Lobby) View() protocol.LobbyView {
+	return protocol.LobbyView{
+		Code:              l.Code,
+		HostID:            l.HostID,
+		Mode:              string(l.Mode),
+		Rules:             l.Rules.View(),
+		Players:           len(l.Players),
+		MaxPlayers:        ModeConfigs[l.Mode].MaxPlayers,
+		PasswordProtected: l.Password != "",
+		Spectators:        l.Spectators,
+	}
+}
+
+// LobbyManager gerencia as salas personalizadas abertas, indexadas pelo código de convite
+type LobbyManager struct {
+	lobbies  map[string]*Lobby
+	byPlayer map[string]string // playerID -> código da sala em que está
+	rng      *rand.Rand
+	mu       sync.Mutex
+}
+
+// NewLobbyManager cria o gerenciador de salas (seed 0 = semente aleatória para os códigos)
+func NewLobbyManager(seed int64) *LobbyManager {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	return &LobbyManager{
+		lobbies:  make(map[string]*Lobby),
+		byPlayer: make(map[string]string),
+		rng:      rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Create abre a sala descrita (HostID, Mode, Rules, Password e Spectators) com o anfitrião como
+// primeiro jogador; quem estava em outra sala sai dela
+func (lm *LobbyManager) Create(lobby Lobby) Lobby {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lm.leave(lobby.HostID)
+
+	lobby.Code = randomCode(lm.rng, func(code string) bool {
+		_, taken := lm.lobbies[code]
+		return taken
+	})
+	lobby.Players = []string{lobby.HostID}
+	lm.lobbies[lobby.Code] = &lobby
+	lm.byPlayer[lobby.HostID] = lobby.Code
+	return lobby.snapshot()
+}
+
+// Join coloca o jogador na sala do código, conferindo a senha; quando a sala fica cheia, ela é fechada
+// e a partida deve ser criada com os jogadores retornados
+func (lm *LobbyManager) Join(code, playerID, password string) (Lobby, error) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lobby, ok := lm.lobbies[code]
+	if !ok {
+		return Lobby{}, ErrLobbyNotFound
+	}
+	if lm.byPlayer[playerID] == code && containsID(lobby.Players, playerID) {
+		return lobby.snapshot(), nil
+	}
+	if lobby.Password != "" && lobby.Password != password {
+		return Lobby{}, ErrWrongPassword
+	}
+
+	lm.leave(playerID)
+	lobby.Players = append(lobby.Players, playerID)
+	lm.byPlayer[playerID] = code
+
+	if lobby.Full() {
+		lm.close(lobby)
+	}
+	return lobby.snapshot(), nil
+}
+
+// Spectate coloca o jogador na sala do código como espectador, conferindo a senha; o espectador não
+// ocupa assento e recebe a visão neutra da partida quando ela começa
+func (lm *LobbyManager) Spectate(code, playerID, password string) (Lobby, error) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lobby, ok := lm.lobbies[code]
+	if !ok {
+		return Lobby{}, ErrLobbyNotFound
+	}
+	if !lobby.Spectators {
+		return Lobby{}, ErrNoSpectators
+	}
+	if lobby.Password != "" && lobby.Password != password {
+		return Lobby{}, ErrWrongPassword
+	}
+	if lm.byPlayer[playerID] == code {
+		// Quem já está na sala (jogador ou espectador) continua nela
+		return lobby.snapshot(), nil
+	}
+
+	lm.leave(playerID)
+	lobby.Watchers = append(lobby.Watchers, playerID)
+	lm.byPlayer[playerID] = code
+	return lobby.snapshot(), nil
+}
+
+// Leave retira o jogador (ou espectador) de sua sala (ok = false se não estava em nenhuma) e retorna a
+// sala restante, que deve ser anunciada aos que ficaram. Se o anfitrião sai, o próximo jogador assume;
+// a sala sem Players é fechada e seus espectadores saem dela
+func (lm *LobbyManager) Leave(playerID string) (lobby Lobby, ok bool) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	return lm.leave(playerID)
+}
+
+// LobbyOf retorna a sala em que o jogador está
+func (lm *LobbyManager) LobbyOf(playerID string) (Lobby, bool) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	code, ok := lm.byPlayer[playerID]
+	if !ok {
+		return Lobby{}, false
+	}
+	return lm.lobbies[code].snapshot(), true
+}
+
+// Get retorna a sala aberta do código de convite
+func (lm *LobbyManager) Get(code string) (Lobby, bool) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lobby, ok := lm.lobbies[code]
+	if !ok {
+		return Lobby{}, false
+	}
+	return lobby.snapshot(), true
+}
+
+// List retorna as salas abertas, ordenadas pelo código
+func (lm *LobbyManager) List() []Lobby {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lobbies := make([]Lobby, 0, len(lm.lobbies))
+	for _, lobby := range lm.lobbies {
+		lobbies = append(lobbies, lobby.snapshot())
+	}
+	sort.Slice(lobbies, func(i, j int) bool {
+		return lobbies[i].Code < lobbies[j].Code
+	})
+	return lobbies
+}
+
+// leave retira o jogador da sala (deve ser chamado com o lock adquirido)
+func (lm *LobbyManager) leave(playerID string) (Lobby, bool) {
+	code, ok := lm.byPlayer[playerID]
+	if !ok {
+		return Lobby{}, false
+	}
+	delete(lm.byPlayer, playerID)
+
+	lobby := lm.lobbies[code]
+	lobby.Players = removeID(lobby.Players, playerID)
+	lobby.Watchers = removeID(lobby.Watchers, playerID)
+
+	if len(lobby.Players) == 0 {
+		lm.close(lobby)
+		return lobby.snapshot(), true
+	}
+	if lobby.HostID == playerID {
+		lobby.HostID = lobby.Players[0]
+	}
+	return lobby.snapshot(), true
+}
+
+// close remove a sala, seus jogadores e seus espectadores do gerenciador (deve ser chamado com o lock
+// adquirido)
+func (lm *LobbyManager) close(lobby *Lobby) {
+	delete(lm.lobbies, lobby.Code)
+	for _, playerID := range lobby.Players {
+		delete(lm.byPlayer, playerID)
+	}
+	for _, playerID := range lobby.Watchers {
+		delete(lm.byPlayer, playerID)
+	}
+}
+
+// containsID verifica se o ID está na lista
+func containsID(ids []string, id string) bool {
+	for _, current := range ids {
+		if current == id {
+			return true
+		}
+	}
+	return false
+}
+
+// removeID retira o ID da lista, mantendo a ordem dos demais
+func removeID(ids []string, id string) []string {
+	for i, current := range ids {
+		if current == id {
+			return append(ids[:i], ids[i+1:]...)
+		}
+	}
+	return ids
+}
+
+// randomCode gera um código de convite que ainda não está em uso
+func randomCode(rng *rand.Rand, taken func(code string) bool) string {
+	for {
+		code := make([]byte, CodeLength)
+		for i := range code {
+			code[i] = CodeAlphabet[rng.Intn(len(CodeAlphabet))]
+		}
+		if !taken(string(code)) {
+			return string(code)
+		}
+	}
+}
+
+// snapshot copia a sala para uso fora do lock
+func (l *Lobby) snapshot() Lobby {
+	copied := *l
+	copied.Players = append([]string{}, l.Players...)
+	copied.Watchers = append([]string{}, l.Watchers...)
+	return copied
+}
@@ -0,0 +1,53 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLobbySpectators(t *testing.T) {
+	lobbies := NewLobbyManager(1)
+	lobby := lobbies.Create(Lobby{HostID: "ana", Mode: ModeSimultaneous, Rules: DefaultRules(), Password: "segredo", Spectators: true})
+
+	if _, err := lobbies.Spectate(lobby.Code, "eva", "errada"); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("Esperado ErrWrongPassword, obtido %v", err)
+	}
+	watched, err := lobbies.Spectate(lobby.Code, "eva", "segredo")
+	if err != nil || len(watched.Players) != 1 || len(watched.Watchers) != 1 || watched.Full() {
+		t.Fatalf("Espectador não deveria ocupar assento: %+v (%v)", watched, err)
+	}
+
+	// O espectador pode passar a jogar: sai da lista de espectadores e ocupa o assento
+	joined, err := lobbies.Join(lobby.Code, "eva", "segredo")
+	if err != nil || len(joined.Watchers) != 0 || !joined.Full() {
+		t.Fatalf("eva deveria ocupar o último assento: %+v (%v)", joined, err)
+	}
+	if _, ok := lobbies.LobbyOf("eva"); ok {
+		t.Error("Sala cheia deveria ser fechada")
+	}
+}
+
+func TestLobbyClosesWithoutPlayers(t *testing.T) {
+	lobbies := NewLobbyManager(1)
+	lobby := lobbies.Create(Lobby{HostID: "ana", Mode: ModeSimultaneous, Rules: DefaultRules(), Spectators: true})
+	if _, err := lobbies.Spectate(lobby.Code, "eva", ""); err != nil {
+		t.Fatalf("Espectador recusado: %v", err)
+	}
+
+	// Sem jogadores a sala fecha, e o espectador sai dela
+	left, ok := lobbies.Leave("ana")
+	if !ok || len(left.Players) != 0 || len(left.Watchers) != 1 {
+		t.Fatalf("Sala restante com o espectador esperada, obtido %+v", left)
+	}
+	if _, ok := lobbies.LobbyOf("eva"); ok {
+		t.Error("Espectador continua em uma sala fechada")
+	}
+	if len(lobbies.List()) != 0 {
+		t.Errorf("Nenhuma sala aberta esperada, obtidas %+v", lobbies.List())
+	}
+
+	closed := lobbies.Create(Lobby{HostID: "bia", Mode: ModeSimultaneous, Rules: DefaultRules()})
+	if _, err := lobbies.Spectate(closed.Code, "eva", ""); !errors.Is(err, ErrNoSpectators) {
+		t.Errorf("Esperado ErrNoSpectators, obtido %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"pingpong/server/protocol"
+	"time"
+)
+
+var (
+	ErrAlreadyLocked = errors.New("jogada já confirmada nesta rodada")
+	ErrNoCardChosen  = errors.New("nenhuma carta escolhida")
+)
+
+// Unplay retira a carta escolhida e ainda não confirmada pelo jogador
+func (m *Match) Unplay(playerID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	playerIndex := m.GetPlayerIndex(playerID)
+	if playerIndex < 0 || !m.isActive(playerIndex) {
+		return fmt.Errorf("jogador não está nesta partida")
+	}
+	if _, locked := m.Waiting[playerID]; locked {
+		return ErrAlreadyLocked
+	}
+	if _, chosen := m.Tentative[playerID]; !chosen {
+		return ErrNoCardChosen
+	}
+
+	delete(m.Tentative, playerID)
+	delete(m.Actions, playerID)
+	m.markActive(playerIndex)
+	return nil
+}
+
+// LockIn confirma a carta escolhida pelo jogador; a rodada avança assim que todos confirmam
+func (m *Match) LockIn(playerID string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	playerIndex := m.GetPlayerIndex(playerID)
+	if playerIndex < 0 || !m.isActive(playerIndex) {
+		return fmt.Errorf("jogador não está nesta partida")
+	}
+	if !m.isPlayersTurn(playerIndex) {
+		return ErrNotYourTurn
+	}
+	if _, locked := m.Waiting[playerID]; locked {
+		return ErrAlreadyLocked
+	}
+	if _, chosen := m.Tentative[playerID]; !chosen {
+		return ErrNoCardChosen
+	}
+
+	m.markActive(playerIndex)
+	m.lockIn(playerIndex, at)
+	return nil
+}
+
+// lockIn confirma a carta escolhida do assento e avança a rodada (deve ser chamado com o lock adquirido)
+func (m *Match) lockIn(playerIndex int, at time.Time) {
+	m.spendBank(playerIndex, at)
+	m.confirmPlay(playerIndex, at, false)
+
+	// Avança para a defesa ou resolve a rodada se todos confirmaram
+	m.advanceRound()
+}
+
+// confirmPlay move a carta escolhida do assento para as jogadas confirmadas e avisa os demais
+// sem revelar a carta; auto indica confirmação por timeout (deve ser chamado com o lock adquirido)
+func (m *Match) confirmPlay(playerIndex int, at time.Time, auto bool) {
+	playerID := m.Players[playerIndex]
+	cardID := m.Tentative[playerID]
+	delete(m.Tentative, playerID)
+	m.Waiting[playerID] = cardID
+
+	entry := protocol.ReplayEntry{
+		T:        RecordPlay,
+		TS:       at.UnixMilli(),
+		Round:    m.Round,
+		PlayerID: playerID,
+		CardID:   cardID,
+		Auto:     auto,
+	}
+	if action, ok := m.Actions[playerID]; ok {
+		entry.Action = string(action.Type)
+		entry.Cards = action.Cards
+	}
+	if len(m.Players) > 2 {
+		entry.Target = m.Players[m.Targets[playerIndex]]
+	}
+	m.record(entry)
+
+	log.Printf("[MATCH %s] %s confirmou a jogada", m.ID, playerID)
+
+	for other, otherID := range m.Players {
+		if other != playerIndex && !m.Left[other] {
+			m.emit(otherID, protocol.ServerMsg{T: protocol.LOCKED_IN, SenderID: playerID})
+		}
+	}
+	m.persist()
+}
@@ -1,6 +1,7 @@
 package game
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
@@ -9,169 +10,417 @@ import (
 	"time"
 )
 
-// Match representa uma partida 1v1
+var (
+	ErrInvalidTarget = errors.New("alvo inválido")
+)
+
+// Match representa uma partida entre dois ou mais jogadores (1v1, 2v2 ou todos contra todos)
 type Match struct {
-	ID       string
-	P1       *protocol.PlayerConn
-	P2       *protocol.PlayerConn
-	HP       [2]int
-	Hands    [2]Hand
-	Discard  [2][]string
-	Round    int
-	State    MatchState
-	Waiting  map[string]string // playerID -> cardID jogado
-	Deadline time.Time
-	CardDB   *CardDB
-	mu       sync.Mutex
-	done     chan bool
+	ID              string
+	Mode            MatchMode
+	Players         []string // IDs dos jogadores, na ordem dos assentos
+	Teams           []int    // time de cada assento
+	HP              []int    // com HP compartilhado, todos do time têm o mesmo valor
+	MaxHP           []int    // HP máximo de cada assento (limite das curas)
+	Hands           []Hand
+	Decks           [][]string // deck montado no draft (nil = compra aleatória do CardDB)
+	Discard         [][]string
+	Effects         [][]StatusEffect
+	Energy          []int
+	MaxEnergy       []int
+	Targets         []int // assento alvo de cada jogador
+	Left            []bool
+	Forfeited       []bool // assento perdeu por abandono (inatividade)
+	EliminatedRound []int  // rodada em que o assento foi eliminado (0 = ativo)
+	Round           int
+	State           MatchState
+	Waiting         map[string]string      // playerID -> cardID jogado e confirmado (LOCK_IN)
+	Tentative       map[string]string      // playerID -> cardID escolhido e ainda não confirmado
+	Actions         map[string]RoundAction // playerID -> ação DEFEND ou CYCLE escolhida (ausente = PLAY)
+	Deadline        time.Time
+	TimeControl     TimeControl
+	Rules           Rules
+	Bank            []int   // banco de tempo restante de cada assento (ms)
+	Terrain         Terrain // terreno da rodada atual (vazio = partida sem terreno)
+	CardDB          *CardDB
+	Seed            int64 // semente do gerador da partida (reproduz mãos, reposições e auto-plays)
+	mu              sync.Mutex
+	done            chan bool
+
+	statusLogs     [][]string  // logs de efeitos de status da rodada atual, por assento
+	draft          *draftState // andamento do draft (nil fora da fase de draft)
+	mulliganed     []bool      // assento já decidiu o mulligan (nil fora da fase de mulligan)
+	observers      []Observer  // destinos dos eventos da partida (rede, bots, replays)
+	clock          roundClock  // prazo da fase atual
+	idleTimer      *time.Timer // verificação de inatividade da partida inteira
+	autoplays      []int       // timeouts consecutivos de cada assento
+	lastAction     []time.Time // última ação própria de cada assento (jogada ou escolha no draft)
+	drawVotes      []bool      // aceites da oferta de empate pendente (nil = sem oferta)
+	terrainEnabled bool        // sorteia um terreno a cada rodada
+	cardPool       []string    // cartas permitidas pelas regras, na ordem de sorteio do CardDB
+	goal           *goalState  // objetivo do cenário de treino (nil = partida comum)
+	boss           *bossState  // chefe do raid (nil fora do modo RAID)
+	store          *AsyncStore // destino do estado gravado das partidas assíncronas (nil = partida ao vivo)
+	source         *countingSource
+	rng            *rand.Rand // gerador da partida, usado apenas com o lock adquirido
 }
 
-// NewMatch cria uma nova partida
-func NewMatch(id string, p1, p2 *protocol.PlayerConn, cardDB *CardDB) *Match {
+// NewMatch cria uma nova partida com os jogadores na ordem dos assentos e as regras informadas
+// (seed 0 = semente aleatória). O prazo base do controle de tempo inicial é o das regras
+func NewMatch(id string, players []string, cardDB *CardDB, mode MatchMode, seed int64, rules Rules) *Match {
+	config := ModeConfigs[mode]
+	seats := len(players)
+
+	// O modo draft sempre monta o deck em draft
+	if config.Draft {
+		rules.DeckPolicy = DeckDraft
+	}
+	timeControl := TimeControls[TimeClassic]
+	timeControl.BaseMs = rules.RoundPlayTimeout
+
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	log.Printf("[MATCH %s] Criada com seed %d", id, seed)
+	source := newCountingSource(seed, 0)
+
 	match := &Match{
-		ID:      id,
-		P1:      p1,
-		P2:      p2,
-		HP:      [2]int{HPStart, HPStart},
-		Hands:   [2]Hand{},
-		Discard: [2][]string{{}, {}},
-		Round:   1,
-		State:   StateAwaitingPlays,
-		Waiting: make(map[string]string),
-		CardDB:  cardDB,
-		done:    make(chan bool, 1),
+		ID:              id,
+		Mode:            mode,
+		Players:         players,
+		Teams:           make([]int, seats),
+		HP:              make([]int, seats),
+		MaxHP:           make([]int, seats),
+		Hands:           make([]Hand, seats),
+		Decks:           make([][]string, seats),
+		Discard:         make([][]string, seats),
+		Effects:         make([][]StatusEffect, seats),
+		Energy:          make([]int, seats),
+		MaxEnergy:       make([]int, seats),
+		Targets:         make([]int, seats),
+		Left:            make([]bool, seats),
+		Forfeited:       make([]bool, seats),
+		EliminatedRound: make([]int, seats),
+		Round:           1,
+		Waiting:         make(map[string]string),
+		TimeControl:     timeControl,
+		Rules:           rules,
+		Bank:            make([]int, seats),
+		Tentative:       make(map[string]string),
+		Actions:         make(map[string]RoundAction),
+		CardDB:          cardDB,
+		Seed:            seed,
+		cardPool:        rules.cardPool(cardDB),
+		source:          source,
+		rng:             rand.New(source),
+		done:            make(chan bool, 1),
+		statusLogs:      make([][]string, seats),
+		autoplays:       make([]int, seats),
+		lastAction:      make([]time.Time, seats),
+	}
+
+	for seat := range players {
+		match.Teams[seat] = seat / config.TeamSize
+		match.MaxHP[seat] = match.maxHP()
+		match.HP[seat] = match.MaxHP[seat]
+		match.Discard[seat] = []string{}
+		match.Effects[seat] = []StatusEffect{}
+		match.Energy[seat] = EnergyStart
+		match.MaxEnergy[seat] = EnergyStart
+		match.lastAction[seat] = time.Now()
+		match.Bank[seat] = match.TimeControl.BankMs
+	}
+	for seat := range players {
+		match.Targets[seat] = match.defaultTarget(seat)
 	}
 
+	// No draft as mãos são compradas do deck escolhido pelos jogadores
+	switch rules.DeckPolicy {
+	case DeckDraft:
+		match.State = StateDrafting
+		return match
+	case DeckSingleton:
+		for seat := range players {
+			match.Decks[seat] = match.singletonDeck()
+		}
+	}
+
+	match.State = StateMulligan
+
 	// Gera mãos iniciais
 	match.DealInitialHands()
 
 	return match
 }
 
+// SetTimeControl define o controle de tempo da partida e reinicia os bancos (antes de Start)
+func (m *Match) SetTimeControl(timeControl TimeControl) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.TimeControl = timeControl
+	for seat := range m.Players {
+		m.Bank[seat] = timeControl.BankMs
+	}
+}
+
+// Start anuncia a partida aos jogadores e abre o draft ou o mulligan das mãos iniciais
+func (m *Match) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for seat, playerID := range m.Players {
+		msg := protocol.ServerMsg{
+			T:           protocol.MATCH_FOUND,
+			MatchID:     m.ID,
+			Mode:        string(m.Mode),
+			TimeControl: m.TimeControl.Name,
+			Rules:       m.Rules.View(),
+		}
+		if len(m.Players) == 2 {
+			msg.OpponentID = m.Players[1-seat]
+		} else {
+			msg.PlayerIDs = m.Players
+		}
+		if m.boss != nil {
+			msg.Boss = m.boss.View()
+		}
+		m.emit(playerID, msg)
+	}
+
+	m.record(protocol.ReplayEntry{
+		T:       RecordStart,
+		MatchID: m.ID,
+		Mode:    string(m.Mode),
+		Seed:    m.Seed,
+		Rules:   m.Rules.View(),
+		Players: m.Players,
+		Hands:   m.handsSnapshot(),
+	})
+	m.armIdleTimer(m.idleLimit())
+
+	if m.State == StateDrafting {
+		m.startDraft()
+		return
+	}
+
+	// Cenários de treino começam com as mãos definidas no arquivo
+	if m.goal != nil {
+		m.startFirstRound()
+		return
+	}
+
+	m.startMulligan()
+}
+
 // DealInitialHands distribui as mãos iniciais
 func (m *Match) DealInitialHands() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.Hands[0] = m.CardDB.GenerateHand(HandSize)
-	m.Hands[1] = m.CardDB.GenerateHand(HandSize)
+	for seat := range m.Players {
+		m.Hands[seat] = Hand{}
+	}
+	m.refillHands()
 }
 
-// GetPlayerIndex retorna o índice do jogador (0 ou 1)
+// GetPlayerIndex retorna o assento do jogador (-1 se não estiver na partida)
 func (m *Match) GetPlayerIndex(playerID string) int {
-	if m.P1.ID == playerID {
-		return 0
+	for seat, id := range m.Players {
+		if id == playerID {
+			return seat
+		}
 	}
-	return 1
+	return -1
 }
 
-// GetOpponentIndex retorna o índice do oponente
-func (m *Match) GetOpponentIndex(playerID string) int {
-	if m.P1.ID == playerID {
-		return 1
-	}
-	return 0
+// HasPlayer verifica se o jogador participa da partida e não a abandonou
+func (m *Match) HasPlayer(playerID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seat := m.GetPlayerIndex(playerID)
+	return seat >= 0 && !m.Left[seat]
 }
 
-// PlayCard registra uma carta jogada por um jogador
-func (m *Match) PlayCard(playerID, cardID string) error {
+// Apply registra a ação e a carta escolhidas pelo jogador (ainda não confirmadas, podem ser trocadas
+// ou retiradas até o LOCK_IN); com play.Lock, confirma a jogada e avança a partida. Os eventos
+// resultantes (STATE, ROUND_RESULT, MATCH_END) são entregues aos observers antes do retorno
+func (m *Match) Apply(play Play) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	playerID, cardID, targetID := play.PlayerID, play.CardID, play.Target
+	if play.At.IsZero() {
+		play.At = time.Now()
+	}
+	if play.Action == "" {
+		play.Action = ActionPlay
+	}
+
 	// Valida se o jogador está na partida
 	playerIndex := m.GetPlayerIndex(playerID)
-	if (playerIndex == 0 && m.P1.ID != playerID) || (playerIndex == 1 && m.P2.ID != playerID) {
+	if playerIndex < 0 || !m.isActive(playerIndex) {
 		return fmt.Errorf("jogador não está nesta partida")
 	}
 
-	// Valida se a carta está na mão do jogador
-	found := false
-	for _, handCardID := range m.Hands[playerIndex] {
-		if handCardID == cardID {
-			found = true
-			break
-		}
+	// Valida se é a vez do jogador (modo por turnos)
+	if !m.isPlayersTurn(playerIndex) {
+		return ErrNotYourTurn
+	}
+
+	// Jogador congelado pula a jogada desta rodada
+	if m.hasStatus(playerIndex, FREEZE) {
+		return fmt.Errorf("jogador está congelado nesta rodada")
+	}
+
+	// Valida as cartas da ação (na mão e pagáveis)
+	if err := m.validateAction(playerIndex, play); err != nil {
+		return err
 	}
-	if !found {
-		return fmt.Errorf("carta não está na mão do jogador")
+
+	// Jogada já confirmada não pode ser trocada
+	if _, locked := m.Waiting[playerID]; locked {
+		return ErrAlreadyLocked
 	}
 
-	// Valida se a carta existe no CardDB
-	if !m.CardDB.ValidateCard(cardID) {
-		return fmt.Errorf("carta inválida")
+	// Valida o alvo escolhido (se omitido, mantém o anterior)
+	if targetID != "" {
+		target := m.GetPlayerIndex(targetID)
+		if target < 0 || !m.isValidTarget(playerIndex, target) {
+			return ErrInvalidTarget
+		}
+		m.Targets[playerIndex] = target
 	}
 
-	// Registra a jogada
-	m.Waiting[playerID] = cardID
+	// Registra a carta e a ação escolhidas (o CYCLE não coloca carta em combate)
+	delete(m.Actions, playerID)
+	switch play.Action {
+	case ActionDefend:
+		m.Actions[playerID] = RoundAction{Type: ActionDefend}
+	case ActionCycle:
+		cardID = ""
+		m.Actions[playerID] = RoundAction{Type: ActionCycle, Cards: append([]string{}, play.Cards...)}
+	}
+	m.Tentative[playerID] = cardID
+	m.markActive(playerIndex)
 
-	// Verifica se ambos jogaram
-	if len(m.Waiting) == 2 {
-		// Resolve a rodada
-		go m.resolveRound()
+	if play.Lock {
+		m.lockIn(playerIndex, play.At)
 	}
 
 	return nil
 }
 
-// resolveRound resolve uma rodada quando ambos jogadores jogaram
+// resolveRound resolve uma rodada quando todos os jogadores ativos jogaram (deve ser chamado com o lock adquirido)
 func (m *Match) resolveRound() {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	m.State = StateResolving
+	m.stopClock()
+
+	seats := len(m.Players)
+	playing := m.activeSeats()
+
+	// Pega as cartas jogadas (ID vazio indica jogador que passou a vez)
+	cards := make([]Card, seats)
+	for _, seat := range playing {
+		cards[seat], _ = m.CardDB.GetCard(m.Waiting[m.Players[seat]])
+		if !m.isValidTarget(seat, m.Targets[seat]) {
+			m.Targets[seat] = m.defaultTarget(seat)
+		}
+	}
 
-	// Pega as cartas jogadas
-	p1CardID := m.Waiting[m.P1.ID]
-	p2CardID := m.Waiting[m.P2.ID]
+	// Calcula bônus elemental e danos contra o alvo de cada jogador (DEFEND e CYCLE não atacam)
+	bonus := make([]int, seats)
+	dealt := make([]int, seats)
+	taken := make([]int, seats)
+	for _, seat := range playing {
+		if m.actionOf(seat) != ActionPlay {
+			continue
+		}
+		target := m.Targets[seat]
+		bonus[seat] = m.Rules.ElementalBonus(cards[seat].Element, cards[target].Element)
+		baseAttack := cards[seat].ATK + m.terrainBonusOf(cards[seat]) + m.bossRage(seat)
+		dealt[seat] = max(0, baseAttack+bonus[seat]-m.defenseOf(target, cards[target]))
+
+		// No modo por turnos apenas o atacante causa dano
+		if m.Mode == ModeTurnBased && seat != m.attackerIndex() {
+			dealt[seat] = 0
+		}
 
-	p1Card, _ := m.CardDB.GetCard(p1CardID)
-	p2Card, _ := m.CardDB.GetCard(p2CardID)
+		taken[target] += dealt[seat]
 
-	// Calcula bônus elemental
-	p1Bonus := ElementalBonus(p1Card.Element, p2Card.Element)
-	p2Bonus := ElementalBonus(p2Card.Element, p1Card.Element)
+		// O golpe em área do chefe do raid atinge também os demais adversários
+		for _, other := range m.cleaveTargets(seat) {
+			otherBonus := m.Rules.ElementalBonus(cards[seat].Element, cards[other].Element)
+			hit := max(0, baseAttack+otherBonus-m.defenseOf(other, cards[other]))
+			dealt[seat] += hit
+			taken[other] += hit
+		}
+	}
 
-	// Calcula danos
-	p1DamageDealt := max(0, (p1Card.ATK+p1Bonus)-p2Card.DEF)
-	p2DamageDealt := max(0, (p2Card.ATK+p2Bonus)-p1Card.DEF)
+	// Aplica danos simultaneamente
+	for _, seat := range playing {
+		m.damage(seat, taken[seat])
+	}
 
-	// Aplica danos
-	m.HP[1] -= p1DamageDealt // P1 causa dano em P2
-	m.HP[0] -= p2DamageDealt // P2 causa dano em P1
+	for _, seat := range playing {
+		// Desconta o custo das cartas jogadas
+		m.spendEnergy(seat, cards[seat])
 
-	// Remove cartas das mãos e adiciona ao descarte
-	m.removeCardFromHand(0, p1CardID)
-	m.removeCardFromHand(1, p2CardID)
-	m.Discard[0] = append(m.Discard[0], p1CardID)
-	m.Discard[1] = append(m.Discard[1], p2CardID)
+		// Remove cartas das mãos e adiciona ao descarte
+		m.discardPlayed(seat, m.Waiting[m.Players[seat]])
+		m.discardCycled(seat)
+	}
 
 	// Repõe as mãos
 	m.refillHands()
 
-	// Cria logs da rodada
-	logs := m.createRoundLogs(p1Card, p2Card, p1Bonus, p2Bonus, p1DamageDealt, p2DamageDealt)
+	// Efeitos de status: os ativos agem primeiro, os novos passam a valer na próxima rodada
+	m.tickStatusEffects(playing)
+	for _, seat := range playing {
+		if m.actionOf(seat) == ActionPlay {
+			m.applyCardEffect(seat, cards[seat])
+		}
+	}
+
+	// Marca os jogadores eliminados nesta rodada
+	m.markEliminated(playing)
 
-	// Envia resultado da rodada
-	m.broadcastRoundResult(p1Card, p2Card, p1Bonus, p2Bonus, p1DamageDealt, p2DamageDealt, logs)
+	// Envia resultado da rodada com os logs na perspectiva de cada jogador
+	m.broadcastRoundResult(playing, cards, bonus, dealt, taken)
 
-	// Limpa as jogadas
+	// Limpa as jogadas e a oferta de empate da rodada
 	m.Waiting = make(map[string]string)
+	m.Tentative = make(map[string]string)
+	m.Actions = make(map[string]RoundAction)
+	m.drawVotes = nil
+	m.statusLogs = make([][]string, seats)
 	m.Round++
 
-	// Verifica fim do jogo
-	if m.EndIfGameOver() {
+	// Verifica fim do jogo (ou do objetivo do cenário de treino)
+	if m.EndIfGameOver(protocol.END_HP) || m.endIfGoalDecided() {
 		return
 	}
 
 	// Próxima rodada
-	m.State = StateAwaitingPlays
-	m.Deadline = time.Now().Add(time.Duration(RoundPlayTimeout) * time.Millisecond)
-
-	// Envia estado atualizado
+	m.State = m.roundStartState()
+	m.rollTerrain()
+	m.gainEnergy()
+	m.passFrozenPlayers()
+	m.passBrokePlayers()
+
+	// Abre o prazo da nova rodada (auto-play de quem não jogar) e envia estado atualizado
+	m.startPlayClock()
 	m.BroadcastState()
+}
 
-	// Agenda timeout para auto-play
-	go m.scheduleAutoPlay()
+// discardPlayed move a carta jogada da mão para o descarte (ignora auto-pass)
+func (m *Match) discardPlayed(playerIndex int, cardID string) {
+	if cardID == "" {
+		return
+	}
+	m.removeCardFromHand(playerIndex, cardID)
+	m.Discard[playerIndex] = append(m.Discard[playerIndex], cardID)
 }
 
 // removeCardFromHand remove uma carta da mão do jogador
@@ -186,225 +435,328 @@ func (m *Match) removeCardFromHand(playerIndex int, cardID string) {
 
 // refillHands repõe as mãos até o tamanho máximo
 func (m *Match) refillHands() {
-	for playerIndex := 0; playerIndex < 2; playerIndex++ {
-		for len(m.Hands[playerIndex]) < HandSize {
-			newCard := m.CardDB.GetRandomCard()
-			if newCard != "" {
-				m.Hands[playerIndex] = append(m.Hands[playerIndex], newCard)
+	for _, playerIndex := range m.activeSeats() {
+		for len(m.Hands[playerIndex]) < m.Rules.HandSize {
+			newCard := m.drawCard(playerIndex)
+			if newCard == "" {
+				break
 			}
+			m.Hands[playerIndex] = append(m.Hands[playerIndex], newCard)
 		}
 	}
 }
 
-// createRoundLogs cria os logs da rodada
-func (m *Match) createRoundLogs(p1Card, p2Card Card, p1Bonus, p2Bonus, p1Dmg, p2Dmg int) []string {
+// createRoundLogs cria os logs da rodada na perspectiva de um jogador
+func (m *Match) createRoundLogs(viewer int, playing []int, cards []Card, bonus, dealt, taken []int) []string {
 	logs := []string{}
 
-	p1BonusText := ""
-	if p1Bonus > 0 {
-		p1BonusText = fmt.Sprintf(" (+%d bônus elemental)", p1Bonus)
-	}
+	if len(m.Players) == 2 && (m.actionOf(viewer) != ActionPlay || m.actionOf(1-viewer) != ActionPlay) {
+		// DEFEND ou CYCLE: uma frase por jogador
+		for _, seat := range []int{viewer, 1 - viewer} {
+			logs = append(logs, m.actionLog(viewer, seat, cards[seat], bonus[seat]))
+		}
+	} else if len(m.Players) == 2 {
+		myCard, oppCard := cards[viewer], cards[1-viewer]
 
-	logs = append(logs, fmt.Sprintf("Você jogou %s (ATK %d%s). Oponente jogou %s (DEF %d).",
-		p1Card.Name, p1Card.ATK, p1BonusText, p2Card.Name, p2Card.DEF))
+		myBonusText := ""
+		if bonus[viewer] > 0 {
+			myBonusText = fmt.Sprintf(" (+%d bônus elemental)", bonus[viewer])
+		}
 
-	if p1Dmg > 0 {
-		logs = append(logs, fmt.Sprintf("Você causou %d de dano!", p1Dmg))
+		switch {
+		case myCard.ID == "":
+			logs = append(logs, fmt.Sprintf("Você passou a vez (congelado ou sem energia). Oponente jogou %s (ATK %d).",
+				oppCard.Name, oppCard.ATK))
+		case oppCard.ID == "":
+			logs = append(logs, fmt.Sprintf("Você jogou %s (ATK %d%s). Oponente passou a vez (congelado ou sem energia).",
+				myCard.Name, myCard.ATK, myBonusText))
+		default:
+			logs = append(logs, fmt.Sprintf("Você jogou %s (ATK %d%s). Oponente jogou %s (DEF %d).",
+				myCard.Name, myCard.ATK, myBonusText, oppCard.Name, oppCard.DEF))
+		}
+	} else {
+		for _, seat := range playing {
+			if cards[seat].ID == "" || m.actionOf(seat) != ActionPlay {
+				logs = append(logs, m.actionLog(viewer, seat, cards[seat], bonus[seat]))
+				continue
+			}
+
+			bonusText := ""
+			if bonus[seat] > 0 {
+				bonusText = fmt.Sprintf(" (+%d bônus elemental)", bonus[seat])
+			}
+			target := m.Targets[seat]
+			logs = append(logs, fmt.Sprintf("%s jogou %s (ATK %d%s) contra %s (DEF %d).",
+				m.seatLabel(viewer, seat), cards[seat].Name, cards[seat].ATK, bonusText,
+				m.targetLabel(viewer, target), cards[target].DEF))
+		}
 	}
-	if p2Dmg > 0 {
-		logs = append(logs, fmt.Sprintf("Você recebeu %d de dano!", p2Dmg))
+
+	if terrainBonus := m.terrainBonusOf(cards[viewer]); terrainBonus != 0 && m.actionOf(viewer) == ActionPlay {
+		logs = append(logs, fmt.Sprintf("O terreno %s alterou seu ATK em %+d.", m.Terrain, terrainBonus))
+	}
+	if dealt[viewer] > 0 {
+		logs = append(logs, fmt.Sprintf("Você causou %d de dano!", dealt[viewer]))
+	}
+	if taken[viewer] > 0 {
+		logs = append(logs, fmt.Sprintf("Você recebeu %d de dano!", taken[viewer]))
 	}
 
 	return logs
 }
 
-// broadcastRoundResult envia o resultado da rodada para ambos jogadores
-func (m *Match) broadcastRoundResult(p1Card, p2Card Card, p1Bonus, p2Bonus, p1Dmg, p2Dmg int, logs []string) {
-	// Para P1
-	p1Msg := protocol.ServerMsg{
-		T: protocol.ROUND_RESULT,
-		You: &protocol.PlayerView{
-			HP:           m.HP[0],
-			CardID:       p1Card.ID,
-			ElementBonus: p1Bonus,
-			DmgDealt:     p1Dmg,
-			DmgTaken:     p2Dmg,
-		},
-		Opponent: &protocol.PlayerView{
-			HP:           m.HP[1],
-			CardID:       p2Card.ID,
-			ElementBonus: p2Bonus,
-		},
-		Logs: logs,
-	}
-
-	// Para P2 (perspectiva invertida)
-	p2Logs := []string{}
-	for _, log := range logs {
-		// Inverte "Você" e "Oponente" nos logs para P2
-		if log == logs[0] { // primeiro log
-			p2Logs = append(p2Logs, fmt.Sprintf("Você jogou %s (ATK %d%s). Oponente jogou %s (DEF %d).",
-				p2Card.Name, p2Card.ATK,
-				func() string {
-					if p2Bonus > 0 {
-						return fmt.Sprintf(" (+%d bônus elemental)", p2Bonus)
-					}
-					return ""
-				}(),
-				p1Card.Name, p1Card.DEF))
-		} else {
-			// Inverte dano causado/recebido
-			if p1Dmg > 0 && log == fmt.Sprintf("Você causou %d de dano!", p1Dmg) {
-				p2Logs = append(p2Logs, fmt.Sprintf("Você recebeu %d de dano!", p1Dmg))
-			} else if p2Dmg > 0 && log == fmt.Sprintf("Você recebeu %d de dano!", p2Dmg) {
-				p2Logs = append(p2Logs, fmt.Sprintf("Você causou %d de dano!", p2Dmg))
+// broadcastRoundResult envia o resultado da rodada para todos os jogadores
+func (m *Match) broadcastRoundResult(playing []int, cards []Card, bonus, dealt, taken []int) {
+	played := make([]bool, len(m.Players))
+	for _, seat := range playing {
+		played[seat] = true
+	}
+
+	// Visão de um assento após a rodada
+	resultView := func(seat int) protocol.PlayerView {
+		view := protocol.PlayerView{
+			HP:      m.HP[seat],
+			Effects: m.statusViews(seat),
+		}
+		if played[seat] {
+			view.CardID = cards[seat].ID
+			view.ElementBonus = bonus[seat]
+			if action := m.actionOf(seat); action != ActionPlay {
+				view.Action = string(action)
+			} else {
+				view.TerrainBonus = m.terrainBonusOf(cards[seat])
 			}
 		}
+		return view
 	}
 
-	p2Msg := protocol.ServerMsg{
-		T: protocol.ROUND_RESULT,
-		You: &protocol.PlayerView{
-			HP:           m.HP[1],
-			CardID:       p2Card.ID,
-			ElementBonus: p2Bonus,
-			DmgDealt:     p2Dmg,
-			DmgTaken:     p1Dmg,
-		},
-		Opponent: &protocol.PlayerView{
-			HP:           m.HP[0],
-			CardID:       p1Card.ID,
-			ElementBonus: p1Bonus,
-		},
-		Logs: p2Logs,
+	// Visão de todos os assentos (enviada em partidas com mais de dois jogadores e gravada no replay)
+	seatViews := make([]protocol.SeatView, len(m.Players))
+	for seat := range m.Players {
+		view := m.seatView(seat)
+		view.PlayerView = resultView(seat)
+		view.DmgDealt = dealt[seat]
+		view.DmgTaken = taken[seat]
+		if played[seat] {
+			view.Target = m.Players[m.Targets[seat]]
+		}
+		seatViews[seat] = view
 	}
+	m.record(protocol.ReplayEntry{T: RecordRoundResult, Round: m.Round, Terrain: string(m.Terrain), Seats: seatViews})
+
+	for viewer, playerID := range m.Players {
+		// Logs na perspectiva do jogador
+		logs := m.turnLogs(viewer)
+		if played[viewer] {
+			logs = append(logs, m.createRoundLogs(viewer, playing, cards, bonus, dealt, taken)...)
+		}
+		logs = append(logs, m.statusLogs[viewer]...)
+
+		you := resultView(viewer)
+		you.DmgDealt = dealt[viewer]
+		you.DmgTaken = taken[viewer]
+		opponent := resultView(m.Targets[viewer])
+
+		msg := protocol.ServerMsg{
+			T:        protocol.ROUND_RESULT,
+			You:      &you,
+			Opponent: &opponent,
+			Terrain:  string(m.Terrain),
+			Logs:     logs,
+		}
 
-	m.P1.SendMsg(p1Msg)
-	m.P2.SendMsg(p2Msg)
+		// Partidas com mais de dois jogadores descrevem todos os assentos
+		if len(m.Players) > 2 {
+			msg.Seats = seatViews
+		}
+
+		m.emit(playerID, msg)
+	}
 }
 
-// BroadcastState envia o estado atual para ambos jogadores
+// BroadcastState envia o estado atual para todos os jogadores; nas partidas assíncronas, grava o
+// estado e avisa quem está na vez
 func (m *Match) BroadcastState() {
-	deadlineMs := time.Until(m.Deadline).Milliseconds()
-	if deadlineMs < 0 {
-		deadlineMs = 0
+	for viewer := range m.Players {
+		m.sendState(viewer)
 	}
 
-	// Para P1
-	p1Msg := protocol.ServerMsg{
-		T: protocol.STATE,
-		You: &protocol.PlayerView{
-			HP:   m.HP[0],
-			Hand: m.Hands[0],
-		},
-		Opponent: &protocol.PlayerView{
-			HP:       m.HP[1],
-			HandSize: len(m.Hands[1]),
-		},
-		Round:      m.Round,
-		DeadlineMs: deadlineMs,
-	}
+	m.persist()
+	m.notifyTurn()
+}
 
-	// Para P2
-	p2Msg := protocol.ServerMsg{
+// sendState envia o estado atual na perspectiva de um jogador
+func (m *Match) sendState(viewer int) {
+	playerID := m.Players[viewer]
+	target := m.Targets[viewer]
+
+	msg := protocol.ServerMsg{
 		T: protocol.STATE,
 		You: &protocol.PlayerView{
-			HP:   m.HP[1],
-			Hand: m.Hands[1],
+			HP:        m.HP[viewer],
+			Hand:      m.Hands[viewer],
+			Effects:   m.statusViews(viewer),
+			Energy:    m.Energy[viewer],
+			MaxEnergy: m.MaxEnergy[viewer],
+			DeckSize:  len(m.Decks[viewer]),
+			BankMs:    int64(m.Bank[viewer]),
 		},
 		Opponent: &protocol.PlayerView{
-			HP:       m.HP[0],
-			HandSize: len(m.Hands[0]),
+			HP:        m.HP[target],
+			HandSize:  len(m.Hands[target]),
+			Effects:   m.statusViews(target),
+			Energy:    m.Energy[target],
+			MaxEnergy: m.MaxEnergy[target],
+			BankMs:    int64(m.Bank[target]),
 		},
 		Round:      m.Round,
-		DeadlineMs: deadlineMs,
+		DeadlineMs: m.remainingMs(),
+		Terrain:    string(m.Terrain),
+	}
+	m.addTurnInfo(&msg, viewer)
+
+	// Partidas com mais de dois jogadores descrevem todos os assentos (o alvo apenas do próprio jogador)
+	if len(m.Players) > 2 {
+		for seat := range m.Players {
+			view := m.seatView(seat)
+			view.HP = m.HP[seat]
+			view.HandSize = len(m.Hands[seat])
+			view.Effects = m.statusViews(seat)
+			view.Energy = m.Energy[seat]
+			view.MaxEnergy = m.MaxEnergy[seat]
+			view.BankMs = int64(m.Bank[seat])
+			if seat == viewer && m.isActive(seat) {
+				view.Target = m.Players[target]
+			}
+			msg.Seats = append(msg.Seats, view)
+		}
 	}
 
-	m.P1.SendMsg(p1Msg)
-	m.P2.SendMsg(p2Msg)
+	m.emit(playerID, msg)
 }
 
-// EndIfGameOver verifica se o jogo terminou e envia MATCH_END
-func (m *Match) EndIfGameOver() bool {
-	if m.HP[0] <= 0 || m.HP[1] <= 0 {
-		m.State = StateEnded
-
-		var p1Result, p2Result string
-
-		if m.HP[0] <= 0 && m.HP[1] <= 0 {
-			// Empate
-			p1Result = protocol.DRAW
-			p2Result = protocol.DRAW
-		} else if m.HP[0] <= 0 {
-			// P1 perdeu
-			p1Result = protocol.LOSE
-			p2Result = protocol.WIN
-		} else {
-			// P2 perdeu
-			p1Result = protocol.WIN
-			p2Result = protocol.LOSE
-		}
-
-		// Envia resultado final
-		m.P1.SendMsg(protocol.ServerMsg{T: protocol.MATCH_END, Result: p1Result})
-		m.P2.SendMsg(protocol.ServerMsg{T: protocol.MATCH_END, Result: p2Result})
+// EndIfGameOver verifica se o jogo terminou (no máximo um time ativo) e envia MATCH_END
+// com o motivo do fim (protocol.END_*)
+func (m *Match) EndIfGameOver(reason string) bool {
+	aliveTeams := m.activeTeams()
+	if len(aliveTeams) > 1 {
+		return false
+	}
 
-		log.Printf("[MATCH %s] Partida finalizada. P1(%s): %s, P2(%s): %s",
-			m.ID, m.P1.ID, p1Result, m.P2.ID, p2Result)
+	// Sem sobreviventes: empatam os eliminados por último
+	lastRound := 0
+	for _, round := range m.EliminatedRound {
+		lastRound = max(lastRound, round)
+	}
 
-		// Sinaliza que a partida terminou
-		select {
-		case m.done <- true:
-		default:
+	results := make(map[string]string, len(m.Players))
+	for seat, playerID := range m.Players {
+		result := protocol.LOSE
+		if aliveTeams[m.Teams[seat]] {
+			result = protocol.WIN
+		} else if m.Forfeited[seat] {
+			result = protocol.FORFEIT
+		} else if len(aliveTeams) == 0 && m.EliminatedRound[seat] == lastRound && !m.Left[seat] {
+			result = protocol.DRAW
 		}
-
-		return true
+		results[playerID] = result
 	}
-	return false
+
+	m.finish(reason, results)
+	return true
 }
 
-// scheduleAutoPlay agenda o auto-play se necessário
-func (m *Match) scheduleAutoPlay() {
-	time.Sleep(time.Duration(RoundPlayTimeout) * time.Millisecond)
-	m.AutoplayIfNeeded()
+// finish encerra a partida e envia a cada jogador o resultado e o motivo do fim
+// (deve ser chamado com o lock adquirido)
+func (m *Match) finish(reason string, results map[string]string) {
+	m.State = StateEnded
+	m.stopClock()
+	if m.idleTimer != nil {
+		m.idleTimer.Stop()
+	}
+
+	for seat, playerID := range m.Players {
+		result := results[playerID]
+
+		// Envia resultado final
+		m.emit(playerID, protocol.ServerMsg{T: protocol.MATCH_END, Result: result, Reason: reason})
+
+		log.Printf("[MATCH %s] Partida finalizada (%s). Assento %d (%s): %s", m.ID, reason, seat, playerID, result)
+	}
+	m.record(protocol.ReplayEntry{T: RecordEnd, Round: m.Round, Results: results, Reason: reason})
+	m.persist()
+	m.reportGoal(results)
+
+	// Sinaliza que a partida terminou
+	select {
+	case m.done <- true:
+	default:
+	}
 }
 
-// AutoplayIfNeeded executa auto-play para jogadores que não jogaram
-func (m *Match) AutoplayIfNeeded() {
+// RemovePlayer retira da partida um jogador que desconectou ou saiu, avisando os demais
+func (m *Match) RemovePlayer(playerID string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if m.State != StateAwaitingPlays {
+	seat := m.GetPlayerIndex(playerID)
+	if seat < 0 || m.Left[seat] || m.State == StateEnded {
 		return
 	}
 
-	// Verifica quais jogadores não jogaram
-	playersToAutoplay := []string{}
+	m.leave(seat, protocol.END_DISCONNECT, "OPPONENT_DISCONNECTED", fmt.Sprintf("O jogador %s desconectou", playerID))
+}
 
-	if _, played := m.Waiting[m.P1.ID]; !played {
-		playersToAutoplay = append(playersToAutoplay, m.P1.ID)
+// leave retira o assento da partida e, se ela continuar, avança a rodada que aguardava apenas
+// esse jogador (deve ser chamado com o lock adquirido)
+func (m *Match) leave(seat int, reason, code, notice string) {
+	if m.removeSeat(seat, reason, code, notice) {
+		return
 	}
 
-	if _, played := m.Waiting[m.P2.ID]; !played {
-		playersToAutoplay = append(playersToAutoplay, m.P2.ID)
+	m.advancePhase()
+}
+
+// advancePhase avança a rodada, o draft ou o mulligan que pode estar aguardando apenas jogadores
+// que saíram (deve ser chamado com o lock adquirido)
+func (m *Match) advancePhase() {
+	switch {
+	case m.awaitingPlays():
+		m.advanceRound()
+	case m.State == StateDrafting:
+		m.advanceDraft()
+	case m.State == StateMulligan:
+		m.advanceMulligan()
 	}
+}
 
-	// Executa auto-play
-	for _, playerID := range playersToAutoplay {
-		playerIndex := m.GetPlayerIndex(playerID)
-		if len(m.Hands[playerIndex]) > 0 {
-			// Escolhe carta aleatória
-			randomIndex := rand.Intn(len(m.Hands[playerIndex]))
-			randomCard := m.Hands[playerIndex][randomIndex]
-			m.Waiting[playerID] = randomCard
+// removeSeat retira o assento da partida (dropSeat) e verifica o fim dela; retorna true se a
+// partida terminou, com o motivo informado (deve ser chamado com o lock adquirido)
+func (m *Match) removeSeat(seat int, reason, code, notice string) bool {
+	m.dropSeat(seat, code, notice)
+	return m.EndIfGameOver(reason)
+}
 
-			log.Printf("[MATCH %s] Auto-play para %s: %s", m.ID, playerID, randomCard)
-		}
+// dropSeat marca o assento como fora da partida e avisa os demais com o código informado, sem
+// verificar o fim da partida (deve ser chamado com o lock adquirido)
+func (m *Match) dropSeat(seat int, code, notice string) {
+	m.Left[seat] = true
+	if m.EliminatedRound[seat] == 0 {
+		m.EliminatedRound[seat] = m.Round
 	}
-
-	// Se ambos jogaram (incluindo auto-play), resolve a rodada
-	if len(m.Waiting) == 2 {
-		go m.resolveRound()
+	delete(m.Waiting, m.Players[seat])
+	delete(m.Tentative, m.Players[seat])
+	delete(m.Actions, m.Players[seat])
+	m.drawVotes = nil
+
+	for other, otherID := range m.Players {
+		if other != seat && !m.Left[other] {
+			m.emit(otherID, protocol.ServerMsg{
+				T:    protocol.ERROR,
+				Code: code,
+				Msg:  notice,
+			})
+		}
 	}
 }
 
@@ -413,12 +765,12 @@ func (m *Match) Done() <-chan bool {
 	return m.done
 }
 
-// ElementalBonus calcula o bônus elemental
-func ElementalBonus(a, b Element) int {
+// ElementalBonus calcula o bônus elemental com o valor das regras
+func (r Rules) ElementalBonus(a, b Element) int {
 	if (a == FIRE && b == PLANT) ||
 		(a == PLANT && b == WATER) ||
 		(a == WATER && b == FIRE) {
-		return ElementalATKBonus
+		return r.ElementalATKBonus
 	}
 	return 0
 }
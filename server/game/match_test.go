@@ -0,0 +1,66 @@
+package game
+
+import "testing"
+
+// testCards carrega a base de cartas do servidor
+func testCards(t *testing.T) *CardDB {
+	t.Helper()
+
+	cardDB := NewCardDB()
+	if err := cardDB.LoadFromFile("../cards.json"); err != nil {
+		t.Fatalf("Erro ao carregar cartas: %v", err)
+	}
+	return cardDB
+}
+
+// newRoundMatch cria uma partida sem rede com as mãos definidas pelo teste, já na primeira rodada.
+// O prazo da rodada é cancelado ao fim do teste
+func newRoundMatch(t *testing.T, mode MatchMode, hands ...Hand) *Match {
+	t.Helper()
+
+	players := []string{"p1", "p2", "p3", "p4"}[:len(hands)]
+	match := NewMatch("m_test", players, testCards(t), mode, 1, DefaultRules())
+	copy(match.Hands, hands)
+	t.Cleanup(func() {
+		match.mu.Lock()
+		defer match.mu.Unlock()
+		match.stopClock()
+	})
+
+	match.Start()
+	keepAll(t, match)
+	return match
+}
+
+// keepAll encerra o mulligan mantendo as mãos iniciais de todos os jogadores
+func keepAll(t *testing.T, match *Match) {
+	t.Helper()
+
+	for _, playerID := range match.Players {
+		if err := match.Mulligan(playerID, nil); err != nil {
+			t.Fatalf("Mulligan de %s rejeitado: %v", playerID, err)
+		}
+	}
+}
+
+// mustPlay confirma a jogada da carta e falha o teste se ela for rejeitada
+func mustPlay(t *testing.T, match *Match, playerID, cardID string) {
+	t.Helper()
+
+	if err := match.Apply(Play{PlayerID: playerID, CardID: cardID, Lock: true}); err != nil {
+		t.Fatalf("Jogada de %s (%s) rejeitada: %v", playerID, cardID, err)
+	}
+}
+
+// watchEnd retorna o mapa preenchido com os resultados do registro END quando a partida termina
+func watchEnd(match *Match) map[string]string {
+	results := make(map[string]string)
+	match.Subscribe(ObserverFunc(func(event Event) {
+		if event.Record != nil && event.Record.T == RecordEnd {
+			for playerID, result := range event.Record.Results {
+				results[playerID] = result
+			}
+		}
+	}))
+	return results
+}
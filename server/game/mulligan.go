@@ -0,0 +1,147 @@
+package game
+
+import (
+	"errors"
+	"log"
+	"pingpong/server/protocol"
+	"time"
+)
+
+var (
+	ErrNotInMulligan     = errors.New("a fase de mulligan já terminou")
+	ErrAlreadyMulliganed = errors.New("você já fez o mulligan")
+	ErrNotInHand         = errors.New("carta não está na mão do jogador")
+)
+
+// startMulligan abre a fase de mulligan: cada jogador pode devolver parte da mão inicial uma
+// única vez antes da primeira rodada (deve ser chamado com o lock adquirido)
+func (m *Match) startMulligan() {
+	m.State = StateMulligan
+	m.mulliganed = make([]bool, len(m.Players))
+	m.armClock(m.mulliganTimeout(), m.finishMulligan)
+
+	deadlineMs := time.Until(m.Deadline).Milliseconds()
+	for seat, playerID := range m.Players {
+		m.emit(playerID, protocol.ServerMsg{
+			T:          protocol.MULLIGAN_OFFER,
+			Cards:      append([]string{}, m.Hands[seat]...),
+			DeadlineMs: deadlineMs,
+		})
+	}
+	m.persist()
+}
+
+// Mulligan devolve as cartas informadas da mão inicial e compra o mesmo número de substitutas;
+// sem cartas, o jogador mantém a mão. Os demais jogadores sabem apenas quantas cartas foram trocadas
+func (m *Match) Mulligan(playerID string, cardIDs []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seat, err := m.activeSeatOf(playerID)
+	if err != nil {
+		return err
+	}
+	if m.State != StateMulligan {
+		return ErrNotInMulligan
+	}
+	if m.mulliganed[seat] {
+		return ErrAlreadyMulliganed
+	}
+
+	// Cada cópia de uma carta na mão pode ser devolvida uma vez
+	kept := append(Hand{}, m.Hands[seat]...)
+	for _, cardID := range cardIDs {
+		found := false
+		for i, handCardID := range kept {
+			if handCardID == cardID {
+				kept = append(kept[:i], kept[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrNotInHand
+		}
+	}
+
+	m.markActive(seat)
+	m.replaceCards(seat, kept, cardIDs)
+	m.advanceMulligan()
+	return nil
+}
+
+// replaceCards completa a mão mantida com cartas novas e avisa os jogadores
+// (deve ser chamado com o lock adquirido)
+func (m *Match) replaceCards(seat int, kept Hand, returned []string) {
+	playerID := m.Players[seat]
+	m.mulliganed[seat] = true
+
+	m.Hands[seat] = kept
+	for len(m.Hands[seat]) < m.Rules.HandSize {
+		newCard := m.drawCard(seat)
+		if newCard == "" {
+			break
+		}
+		m.Hands[seat] = append(m.Hands[seat], newCard)
+	}
+
+	// Com deck montado (draft), as cartas devolvidas voltam ao deck, que é embaralhado
+	if m.Decks[seat] != nil && len(returned) > 0 {
+		m.Decks[seat] = append(m.Decks[seat], returned...)
+		m.rng.Shuffle(len(m.Decks[seat]), func(i, j int) {
+			m.Decks[seat][i], m.Decks[seat][j] = m.Decks[seat][j], m.Decks[seat][i]
+		})
+	}
+
+	m.record(protocol.ReplayEntry{T: RecordMulligan, PlayerID: playerID, Cards: returned})
+	log.Printf("[MATCH %s] %s trocou %d carta(s) no mulligan", m.ID, playerID, len(returned))
+
+	for other, otherID := range m.Players {
+		msg := protocol.ServerMsg{T: protocol.MULLIGAN_DONE, SenderID: playerID, Replaced: len(returned)}
+		if other == seat {
+			msg.Cards = append([]string{}, m.Hands[seat]...)
+		} else if m.Left[other] {
+			continue
+		}
+		m.emit(otherID, msg)
+	}
+	m.persist()
+}
+
+// advanceMulligan inicia a primeira rodada quando todos os ativos decidiram
+// (deve ser chamado com o lock adquirido)
+func (m *Match) advanceMulligan() {
+	for _, seat := range m.activeSeats() {
+		if !m.mulliganed[seat] {
+			return
+		}
+	}
+	m.finishMulligan()
+}
+
+// finishMulligan mantém a mão de quem não decidiu a tempo e inicia a primeira rodada
+// (deve ser chamado com o lock adquirido)
+func (m *Match) finishMulligan() {
+	if m.State != StateMulligan {
+		return
+	}
+
+	for _, seat := range m.activeSeats() {
+		if !m.mulliganed[seat] {
+			m.replaceCards(seat, m.Hands[seat], nil)
+		}
+	}
+	m.mulliganed = nil
+
+	log.Printf("[MATCH %s] Mulligan finalizado", m.ID)
+	m.startFirstRound()
+}
+
+// startFirstRound registra as mãos finais e abre a primeira rodada (deve ser chamado com o lock adquirido)
+func (m *Match) startFirstRound() {
+	m.record(protocol.ReplayEntry{T: RecordDeal, Hands: m.handsSnapshot()})
+	m.State = m.roundStartState()
+	m.rollTerrain()
+	m.startPlayClock()
+	m.BroadcastState()
+}
@@ -0,0 +1,274 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"pingpong/server/protocol"
+	"sort"
+	"sync"
+)
+
+// FavoriteCardsShown é quantas cartas mais usadas aparecem no perfil
+const FavoriteCardsShown = 3
+
+// elementRecord conta as partidas (e vitórias) em que o jogador usou cartas de um elemento
+type elementRecord struct {
+	Matches int `json:"matches"`
+	Wins    int `json:"wins"`
+}
+
+// profileAccount acumula as estatísticas de um jogador em todas as partidas registradas
+type profileAccount struct {
+	Matches     int                       `json:"matches"`
+	Wins        int                       `json:"wins"`
+	Losses      int                       `json:"losses"`
+	Draws       int                       `json:"draws"`
+	Elements    map[string]*elementRecord `json:"elements"`
+	Rounds      int                       `json:"rounds"`
+	DamageDealt int                       `json:"damageDealt"`
+	DamageTaken int                       `json:"damageTaken"`
+	Cards       map[string]int            `json:"cards"` // vezes que cada carta foi usada
+	Streak      int                       `json:"streak"`
+	BestStreak  int                       `json:"bestStreak"`
+	Plays       int                       `json:"plays"`
+	AutoPlays   int                       `json:"autoPlays"`
+}
+
+// ProfileStore guarda as estatísticas de cada conta (jogador identificado com LOGIN) em um arquivo
+// JSON, regravado ao fim de cada partida registrada
+type ProfileStore struct {
+	path     string
+	accounts map[string]*profileAccount
+	mu       sync.Mutex
+}
+
+// LoadProfiles abre as estatísticas gravadas no arquivo (inexistente = nenhuma partida registrada)
+func LoadProfiles(path string) (*ProfileStore, error) {
+	store := &ProfileStore{path: path, accounts: make(map[string]*profileAccount)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler perfis: %w", err)
+	}
+	if err := json.Unmarshal(data, &store.accounts); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar perfis: %w", err)
+	}
+	return store, nil
+}
+
+// matchTally é o que um jogador fez em uma partida, somado ao perfil quando ela termina
+type matchTally struct {
+	rounds    int
+	dealt     int
+	taken     int
+	plays     int
+	autoPlays int
+	cards     map[string]int
+	elements  map[Element]bool
+}
+
+// MatchStats é o observer que acompanha os registros de uma partida (as jogadas e os resultados das
+// rodadas) e, no registro END, soma ao perfil o que cada conta da partida fez
+type MatchStats struct {
+	store  *ProfileStore
+	cardDB *CardDB
+	tally  map[string]*matchTally
+}
+
+// Track cria o observer de estatísticas de uma partida; só as contas informadas são registradas
+// (bots e conexões sem LOGIN ficam de fora)
+func (s *ProfileStore) Track(accounts []string, cardDB *CardDB) *MatchStats {
+	stats := &MatchStats{store: s, cardDB: cardDB, tally: make(map[string]*matchTally, len(accounts))}
+	for _, playerID := range accounts {
+		stats.tally[playerID] = &matchTally{cards: make(map[string]int), elements: make(map[Element]bool)}
+	}
+	return stats
+}
+
+// Feed repassa registros já gravados (o replay de uma partida assíncrona restaurada), para que as
+// rodadas anteriores ao reinício também contem
+func (t *MatchStats) Feed(entries []protocol.ReplayEntry) {
+	for i := range entries {
+		t.OnEvent(Event{MatchID: entries[i].MatchID, Record: &entries[i]})
+	}
+}
+
+// OnEvent acumula as jogadas e os resultados das rodadas e registra a partida no fim
+func (t *MatchStats) OnEvent(event Event) {
+	if event.Record == nil {
+		return
+	}
+
+	entry := event.Record
+	switch entry.T {
+	case RecordPlay:
+		if tally, ok := t.tally[entry.PlayerID]; ok {
+			tally.plays++
+			if entry.Auto {
+				tally.autoPlays++
+			}
+		}
+	case RecordRoundResult:
+		for _, seat := range entry.Seats {
+			tally, ok := t.tally[seat.PlayerID]
+			if !ok || (seat.CardID == "" && seat.Action == "") {
+				continue
+			}
+			tally.rounds++
+			tally.dealt += seat.DmgDealt
+			tally.taken += seat.DmgTaken
+			if card, ok := t.cardDB.GetCard(seat.CardID); ok {
+				tally.cards[card.ID]++
+				tally.elements[card.Element] = true
+			}
+		}
+	case RecordEnd:
+		if err := t.store.record(t.tally, entry.Results); err != nil {
+			log.Printf("[PROFILE %s] Erro ao gravar estatísticas: %v", event.MatchID, err)
+		}
+	}
+}
+
+// record soma ao perfil de cada conta o que ela fez na partida e o resultado final
+func (s *ProfileStore) record(tallies map[string]*matchTally, results map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for playerID, tally := range tallies {
+		result, ok := results[playerID]
+		if !ok {
+			continue
+		}
+
+		account, ok := s.accounts[playerID]
+		if !ok {
+			account = &profileAccount{Elements: make(map[string]*elementRecord), Cards: make(map[string]int)}
+			s.accounts[playerID] = account
+		}
+
+		account.Matches++
+		switch result {
+		case protocol.WIN:
+			account.Wins++
+			account.Streak++
+			account.BestStreak = max(account.BestStreak, account.Streak)
+		case protocol.DRAW:
+			account.Draws++
+			account.Streak = 0
+		default:
+			account.Losses++
+			account.Streak = 0
+		}
+
+		for element := range tally.elements {
+			record, ok := account.Elements[string(element)]
+			if !ok {
+				record = &elementRecord{}
+				account.Elements[string(element)] = record
+			}
+			record.Matches++
+			if result == protocol.WIN {
+				record.Wins++
+			}
+		}
+		for cardID, plays := range tally.cards {
+			account.Cards[cardID] += plays
+		}
+		account.Rounds += tally.rounds
+		account.DamageDealt += tally.dealt
+		account.DamageTaken += tally.taken
+		account.Plays += tally.plays
+		account.AutoPlays += tally.autoPlays
+	}
+	return s.save()
+}
+
+// View calcula o perfil do jogador; false se nenhuma partida dele foi registrada
+func (s *ProfileStore) View(playerID string) (protocol.ProfileView, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.accounts[playerID]
+	if !ok {
+		return protocol.ProfileView{}, false
+	}
+
+	view := protocol.ProfileView{
+		PlayerID:         playerID,
+		Matches:          account.Matches,
+		Wins:             account.Wins,
+		Losses:           account.Losses,
+		Draws:            account.Draws,
+		WinRate:          percent(account.Wins, account.Matches),
+		Rounds:           account.Rounds,
+		AvgDamageDealt:   average(account.DamageDealt, account.Rounds),
+		AvgDamageTaken:   average(account.DamageTaken, account.Rounds),
+		CurrentStreak:    account.Streak,
+		LongestWinStreak: account.BestStreak,
+		AutoPlayRate:     percent(account.AutoPlays, account.Plays),
+	}
+
+	for element, record := range account.Elements {
+		view.Elements = append(view.Elements, protocol.ElementStats{
+			Element: element,
+			Matches: record.Matches,
+			Wins:    record.Wins,
+			WinRate: percent(record.Wins, record.Matches),
+		})
+	}
+	sort.Slice(view.Elements, func(i, j int) bool {
+		return view.Elements[i].Element < view.Elements[j].Element
+	})
+
+	for cardID, plays := range account.Cards {
+		view.FavoriteCards = append(view.FavoriteCards, protocol.CardCount{CardID: cardID, Plays: plays})
+	}
+	sort.Slice(view.FavoriteCards, func(i, j int) bool {
+		if view.FavoriteCards[i].Plays != view.FavoriteCards[j].Plays {
+			return view.FavoriteCards[i].Plays > view.FavoriteCards[j].Plays
+		}
+		return view.FavoriteCards[i].CardID < view.FavoriteCards[j].CardID
+	})
+	if len(view.FavoriteCards) > FavoriteCardsShown {
+		view.FavoriteCards = view.FavoriteCards[:FavoriteCardsShown]
+	}
+
+	return view, true
+}
+
+// percent retorna part/total em porcentagem arredondada (0 sem total)
+func percent(part, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return int(math.Round(float64(part) * 100 / float64(total)))
+}
+
+// average retorna sum/count com uma casa decimal (0 sem count)
+func average(sum, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return math.Round(float64(sum)*10/float64(count)) / 10
+}
+
+// save grava as estatísticas, substituindo o arquivo de uma só vez (deve ser chamado com o lock adquirido)
+func (s *ProfileStore) save() error {
+	data, err := json.MarshalIndent(s.accounts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao codificar perfis: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("erro ao gravar perfis: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,68 @@
+package game
+
+import (
+	"path/filepath"
+	"pingpong/server/protocol"
+	"testing"
+)
+
+func TestProfileStoreFile(t *testing.T) {
+	cardDB := testCards(t)
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		t.Fatalf("Erro ao abrir perfis: %v", err)
+	}
+
+	// Duas partidas de ana (bia não tem conta): uma vitória com auto-play e uma derrota
+	for _, result := range []string{protocol.WIN, protocol.LOSE} {
+		stats := profiles.Track([]string{"ana"}, cardDB)
+		stats.Feed([]protocol.ReplayEntry{
+			{T: RecordPlay, PlayerID: "ana", CardID: "c_001"},
+			{T: RecordPlay, PlayerID: "bia", CardID: "c_004", Auto: true},
+			{T: RecordRoundResult, Round: 1, Seats: []protocol.SeatView{
+				{PlayerID: "ana", PlayerView: protocol.PlayerView{CardID: "c_001", DmgDealt: 4, DmgTaken: 1}},
+				{PlayerID: "bia", PlayerView: protocol.PlayerView{CardID: "c_004", DmgDealt: 1, DmgTaken: 4}},
+			}},
+			{T: RecordPlay, PlayerID: "ana", CardID: "c_004", Auto: true},
+			{T: RecordRoundResult, Round: 2, Seats: []protocol.SeatView{
+				{PlayerID: "ana", PlayerView: protocol.PlayerView{CardID: "c_004", DmgTaken: 2}},
+				{PlayerID: "bia", PlayerView: protocol.PlayerView{CardID: "c_001", DmgDealt: 2}},
+			}},
+			{T: RecordEnd, Results: map[string]string{"ana": result, "bia": protocol.LOSE}},
+		})
+	}
+
+	if _, ok := profiles.View("bia"); ok {
+		t.Error("Jogador sem conta não deveria ter perfil")
+	}
+
+	// O perfil volta do arquivo com as estatísticas somadas
+	reloaded, err := LoadProfiles(path)
+	if err != nil {
+		t.Fatalf("Erro ao reabrir perfis: %v", err)
+	}
+	view, ok := reloaded.View("ana")
+	if !ok {
+		t.Fatal("Perfil de ana não gravado")
+	}
+	if view.Matches != 2 || view.Wins != 1 || view.Losses != 1 || view.WinRate != 50 {
+		t.Errorf("2 partidas com 1 vitória esperadas, obtido %+v", view)
+	}
+	if view.Rounds != 4 || view.AvgDamageDealt != 2 || view.AvgDamageTaken != 1.5 {
+		t.Errorf("4 rodadas com médias 2/1.5 esperadas, obtido %d rodadas e %.1f/%.1f", view.Rounds, view.AvgDamageDealt, view.AvgDamageTaken)
+	}
+	if view.AutoPlayRate != 50 || view.CurrentStreak != 0 || view.LongestWinStreak != 1 {
+		t.Errorf("Auto-play 50%%, sequência 0 e melhor sequência 1 esperados, obtido %+v", view)
+	}
+	if len(view.FavoriteCards) != 2 || view.FavoriteCards[0].Plays != 2 {
+		t.Errorf("Duas cartas favoritas com 2 jogadas esperadas, obtido %+v", view.FavoriteCards)
+	}
+
+	fire, _ := cardDB.GetCard("c_001")
+	for _, element := range view.Elements {
+		if element.Element == string(fire.Element) && (element.Matches != 2 || element.Wins != 1) {
+			t.Errorf("%s: 2 partidas e 1 vitória esperadas, obtido %+v", element.Element, element)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"pingpong/server/protocol"
+	"time"
+)
+
+// MaxBossHP é o limite de HP de um chefe de raid
+const MaxBossHP = 500
+
+// BossRotation é o intervalo de rodízio do chefe enfrentado no modo RAID
+const BossRotation = 7 * 24 * time.Hour
+
+// BossAbilities são as habilidades de um chefe de raid, aplicadas pela resolução da rodada
+type BossAbilities struct {
+	Armor       int `json:"armor,omitempty"`       // reduz o dano de cada golpe recebido
+	EnrageBelow int `json:"enrageBelow,omitempty"` // HP a partir do qual o chefe entra em fúria (0 = nunca)
+	EnrageATK   int `json:"enrageAtk,omitempty"`   // ATK somado às cartas do chefe em fúria
+	CleaveEvery int `json:"cleaveEvery,omitempty"` // a cada N rodadas o ataque atinge todos os jogadores (0 = nunca)
+}
+
+// Boss é um chefe de raid: um assento do servidor com HP alto, deck e habilidades próprios, que
+// enfrenta o time de dois jogadores
+type Boss struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	HP          int           `json:"hp"`
+	Deck        []string      `json:"deck"` // deck fixo, embaralhado a cada partida
+	Strategy    BotStrategy   `json:"strategy"`
+	Abilities   BossAbilities `json:"abilities"`
+}
+
+// RaidRoster são os chefes que se revezam no modo RAID
+type RaidRoster struct {
+	Bosses []Boss
+}
+
+// bossState guarda o chefe de raid da partida e o assento que ele ocupa
+type bossState struct {
+	Boss
+	seat    int
+	enraged bool
+}
+
+// LoadRaidRoster carrega e valida os chefes do arquivo JSON do raid
+func LoadRaidRoster(filename string, cardDB *CardDB) (*RaidRoster, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo do raid: %w", err)
+	}
+
+	var bosses []Boss
+	if err := json.Unmarshal(data, &bosses); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar JSON do raid: %w", err)
+	}
+	if len(bosses) == 0 {
+		return nil, errors.New("nenhum chefe de raid no arquivo")
+	}
+
+	seen := make(map[string]bool, len(bosses))
+	for _, boss := range bosses {
+		if err := boss.validate(cardDB); err != nil {
+			return nil, fmt.Errorf("chefe %q: %w", boss.ID, err)
+		}
+		if seen[boss.ID] {
+			return nil, fmt.Errorf("chefe %q repetido", boss.ID)
+		}
+		seen[boss.ID] = true
+	}
+
+	return &RaidRoster{Bosses: bosses}, nil
+}
+
+// validate confere o HP, o deck, a estratégia e as habilidades do chefe
+func (b Boss) validate(cardDB *CardDB) error {
+	if b.ID == "" {
+		return errors.New("ID vazio")
+	}
+	if b.HP < 1 || b.HP > MaxBossHP {
+		return fmt.Errorf("HP deve ficar entre 1 e %d", MaxBossHP)
+	}
+	if !ValidBotStrategy(b.Strategy) {
+		return fmt.Errorf("estratégia desconhecida %q", b.Strategy)
+	}
+	abilities := b.Abilities
+	if abilities.Armor < 0 || abilities.EnrageBelow < 0 || abilities.EnrageATK < 0 || abilities.CleaveEvery < 0 {
+		return errors.New("habilidade com valor negativo")
+	}
+	if len(b.Deck) < HandSize {
+		return fmt.Errorf("o deck precisa de ao menos %d cartas", HandSize)
+	}
+	for _, cardID := range b.Deck {
+		if !cardDB.ValidateCard(cardID) {
+			return fmt.Errorf("carta desconhecida %q no deck", cardID)
+		}
+	}
+	return nil
+}
+
+// Current retorna o chefe da semana (os chefes se revezam a cada BossRotation)
+func (r *RaidRoster) Current(now time.Time) Boss {
+	index := now.Unix() / int64(BossRotation/time.Second) % int64(len(r.Bosses))
+	return r.Bosses[index]
+}
+
+// BotID retorna o ID do assento do chefe
+func (b Boss) BotID() string {
+	return BotID(b.ID)
+}
+
+// Join coloca o chefe no seu assento da partida (criada com BotID entre os jogadores): define o HP e o
+// deck, ativa as habilidades e registra o bot que joga por ele (antes de Start)
+func (b Boss) Join(match *Match) *Bot {
+	match.SetSeat(b.BotID(), b.HP, b.Deck)
+
+	match.mu.Lock()
+	match.boss = &bossState{Boss: b, seat: match.GetPlayerIndex(b.BotID())}
+	match.mu.Unlock()
+
+	return NewBot(match, b.BotID(), b.Strategy)
+}
+
+// View descreve o chefe para os jogadores
+func (b Boss) View() *protocol.BossView {
+	return &protocol.BossView{
+		ID:          b.ID,
+		PlayerID:    b.BotID(),
+		Name:        b.Name,
+		Description: b.Description,
+		HP:          b.HP,
+		Armor:       b.Abilities.Armor,
+		EnrageBelow: b.Abilities.EnrageBelow,
+		EnrageATK:   b.Abilities.EnrageATK,
+		CleaveEvery: b.Abilities.CleaveEvery,
+	}
+}
+
+// isBoss verifica se o assento é o do chefe de raid
+func (m *Match) isBoss(seat int) bool {
+	return m.boss != nil && m.boss.seat == seat
+}
+
+// bossArmor retorna a redução de dano do assento (apenas o chefe tem armadura)
+func (m *Match) bossArmor(seat int) int {
+	if !m.isBoss(seat) {
+		return 0
+	}
+	return m.boss.Abilities.Armor
+}
+
+// bossRage retorna o ATK extra do chefe em fúria; a fúria começa quando o HP chega a EnrageBelow e dura
+// até o fim da partida (deve ser chamado com o lock adquirido)
+func (m *Match) bossRage(seat int) int {
+	if !m.isBoss(seat) || m.boss.Abilities.EnrageBelow == 0 {
+		return 0
+	}
+	if !m.boss.enraged && m.HP[seat] <= m.boss.Abilities.EnrageBelow {
+		m.boss.enraged = true
+		m.logStatus(seat, "%s entrou em fúria: +%d de ATK até o fim da partida", m.boss.Abilities.EnrageATK)
+	}
+	if !m.boss.enraged {
+		return 0
+	}
+	return m.boss.Abilities.EnrageATK
+}
+
+// cleaveTargets retorna os demais adversários atingidos pelo golpe em área do chefe nesta rodada
+// (deve ser chamado com o lock adquirido)
+func (m *Match) cleaveTargets(seat int) []int {
+	if !m.isBoss(seat) || m.boss.Abilities.CleaveEvery == 0 || m.Round%m.boss.Abilities.CleaveEvery != 0 {
+		return nil
+	}
+
+	targets := []int{}
+	for _, other := range m.activeSeats() {
+		if other != m.Targets[seat] && m.isValidTarget(seat, other) {
+			targets = append(targets, other)
+		}
+	}
+	if len(targets) > 0 {
+		m.logStatus(seat, "%s desferiu um golpe em área contra todos os adversários")
+	}
+	return targets
+}
@@ -0,0 +1,134 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"pingpong/server/protocol"
+	"time"
+)
+
+// Tipos de registro do replay
+const (
+	RecordStart       = "START"        // seed, modo, jogadores e mãos iniciais
+	RecordDraftPick   = "DRAFT_PICK"   // carta escolhida no draft
+	RecordDeal        = "DEAL"         // mãos compradas do deck ao fim do draft e mãos finais do mulligan
+	RecordMulligan    = "MULLIGAN"     // cartas devolvidas por um jogador no mulligan
+	RecordPlay        = "PLAY"         // jogada aceita (ou auto-play)
+	RecordRoundResult = "ROUND_RESULT" // resultado da rodada de todos os assentos
+	RecordEnd         = "END"          // resultado final de cada jogador
+)
+
+// record emite um registro da partida para os observers (replays)
+func (m *Match) record(entry protocol.ReplayEntry) {
+	if entry.TS == 0 {
+		entry.TS = time.Now().UnixMilli()
+	}
+	event := Event{MatchID: m.ID, Record: &entry}
+	for _, observer := range m.observers {
+		observer.OnEvent(event)
+	}
+}
+
+// handsSnapshot copia as mãos atuais de todos os assentos
+func (m *Match) handsSnapshot() [][]string {
+	hands := make([][]string, len(m.Hands))
+	for seat, hand := range m.Hands {
+		hands[seat] = append([]string{}, hand...)
+	}
+	return hands
+}
+
+// ReplayRecorder grava os registros de uma partida em um arquivo JSONL
+type ReplayRecorder struct {
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// NewReplayRecorder cria o arquivo de replay da partida no diretório informado
+func NewReplayRecorder(dir, matchID string) (*ReplayRecorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de replays: %w", err)
+	}
+
+	file, err := os.Create(ReplayPath(dir, matchID))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar arquivo de replay: %w", err)
+	}
+
+	return &ReplayRecorder{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// ResumeReplayRecorder reabre o arquivo de replay de uma partida restaurada, continuando após os
+// registros já gravados
+func ResumeReplayRecorder(dir, matchID string) (*ReplayRecorder, error) {
+	file, err := os.OpenFile(ReplayPath(dir, matchID), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao reabrir arquivo de replay: %w", err)
+	}
+
+	return &ReplayRecorder{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// OnEvent grava os registros da partida e fecha o arquivo ao fim da partida
+func (r *ReplayRecorder) OnEvent(event Event) {
+	if event.Record == nil {
+		return
+	}
+
+	if err := r.encoder.Encode(event.Record); err != nil {
+		log.Printf("[REPLAY %s] Erro ao gravar registro: %v", event.MatchID, err)
+	}
+
+	if event.Record.T == RecordEnd {
+		r.file.Close()
+	}
+}
+
+// ReplayPath retorna o caminho do arquivo de replay de uma partida
+func ReplayPath(dir, matchID string) string {
+	return filepath.Join(dir, matchID+".jsonl")
+}
+
+// LoadReplay lê o replay de uma partida do diretório informado
+func LoadReplay(dir, matchID string) ([]protocol.ReplayEntry, error) {
+	file, err := os.Open(ReplayPath(dir, matchID))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir replay: %w", err)
+	}
+	defer file.Close()
+
+	entries := []protocol.ReplayEntry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry protocol.ReplayEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("erro ao decodificar replay: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler replay: %w", err)
+	}
+
+	return entries, nil
+}
+
+// ReplayHasPlayer verifica se o jogador ocupava um assento na partida registrada no replay
+func ReplayHasPlayer(entries []protocol.ReplayEntry, playerID string) bool {
+	for _, entry := range entries {
+		if entry.T != RecordStart {
+			continue
+		}
+		for _, id := range entry.Players {
+			if id == playerID {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package game
+
+import "testing"
+
+func TestReplayRecorderRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewReplayRecorder(dir, "m_replay")
+	if err != nil {
+		t.Fatalf("Erro ao criar replay: %v", err)
+	}
+
+	match := NewMatch("m_replay", []string{"p1", "p2"}, testCards(t), ModeSimultaneous, 7, DefaultRules())
+	match.Subscribe(recorder)
+	match.Start()
+	if err := match.Forfeit("p2"); err != nil {
+		t.Fatalf("Erro ao desistir: %v", err)
+	}
+
+	entries, err := LoadReplay(dir, "m_replay")
+	if err != nil {
+		t.Fatalf("Erro ao ler replay: %v", err)
+	}
+	if len(entries) < 2 || entries[0].T != RecordStart || entries[len(entries)-1].T != RecordEnd {
+		t.Fatalf("Replay deveria ir de START a END, obtido %+v", entries)
+	}
+	if entries[0].Seed != 7 {
+		t.Errorf("Seed esperada 7, obtida %d", entries[0].Seed)
+	}
+
+	// Apenas quem jogou a partida é participante do replay
+	for playerID, want := range map[string]bool{"p1": true, "p2": true, "p3": false, "": false} {
+		if got := ReplayHasPlayer(entries, playerID); got != want {
+			t.Errorf("ReplayHasPlayer(%q) = %v, esperado %v", playerID, got, want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"pingpong/server/protocol"
+)
+
+var (
+	ErrInvalidRules = errors.New("regras da partida inválidas")
+)
+
+// DeckPolicy define de onde saem as cartas das mãos e das reposições
+type DeckPolicy string
+
+const (
+	DeckRandom    DeckPolicy = "RANDOM"    // sorteio entre todas as cartas permitidas, com repetição (padrão)
+	DeckSingleton DeckPolicy = "SINGLETON" // deck embaralhado com uma cópia de cada carta permitida
+	DeckDraft     DeckPolicy = "DRAFT"     // deck montado em draft antes da primeira rodada
+)
+
+// Limites aceitos nas regras personalizadas
+const (
+	MaxRulesHP        = 100
+	MaxRulesHandSize  = 10
+	MaxRulesATKBonus  = 10
+	MinRulesTimeoutMs = 3_000
+	MaxRulesTimeoutMs = 120_000
+)
+
+// Rules reúne os parâmetros de uma partida; as partidas do matchmaking usam DefaultRules e as salas
+// personalizadas podem alterá-los
+type Rules struct {
+	HPStart           int
+	HandSize          int
+	ElementalATKBonus int
+	RoundPlayTimeout  int // ms (prazo base de cada fase)
+	DeckPolicy        DeckPolicy
+	BannedCards       []string // cartas que não aparecem nas mãos, reposições e pacotes do draft
+}
+
+// DefaultRules retorna as regras padrão do jogo
+func DefaultRules() Rules {
+	return Rules{
+		HPStart:           HPStart,
+		HandSize:          HandSize,
+		ElementalATKBonus: ElementalATKBonus,
+		RoundPlayTimeout:  RoundPlayTimeout,
+		DeckPolicy:        DeckRandom,
+	}
+}
+
+// ParseRules converte as regras recebidas do cliente (nil ou campos omitidos = padrão) e valida os limites
+func ParseRules(view *protocol.Rules, cardDB *CardDB) (Rules, error) {
+	rules := DefaultRules()
+	if view == nil {
+		return rules, nil
+	}
+
+	if view.HPStart != 0 {
+		rules.HPStart = view.HPStart
+	}
+	if view.HandSize != 0 {
+		rules.HandSize = view.HandSize
+	}
+	if view.ElementalATKBonus != nil {
+		rules.ElementalATKBonus = *view.ElementalATKBonus
+	}
+	if view.RoundPlayTimeout != 0 {
+		rules.RoundPlayTimeout = view.RoundPlayTimeout
+	}
+	if view.DeckPolicy != "" {
+		rules.DeckPolicy = DeckPolicy(view.DeckPolicy)
+	}
+	rules.BannedCards = append([]string{}, view.BannedCards...)
+
+	switch {
+	case rules.HPStart < 1 || rules.HPStart > MaxRulesHP:
+		return Rules{}, fmt.Errorf("%w: HP inicial deve ficar entre 1 e %d", ErrInvalidRules, MaxRulesHP)
+	case rules.HandSize < 1 || rules.HandSize > MaxRulesHandSize:
+		return Rules{}, fmt.Errorf("%w: tamanho da mão deve ficar entre 1 e %d", ErrInvalidRules, MaxRulesHandSize)
+	case rules.ElementalATKBonus < 0 || rules.ElementalATKBonus > MaxRulesATKBonus:
+		return Rules{}, fmt.Errorf("%w: bônus elemental deve ficar entre 0 e %d", ErrInvalidRules, MaxRulesATKBonus)
+	case rules.RoundPlayTimeout < MinRulesTimeoutMs || rules.RoundPlayTimeout > MaxRulesTimeoutMs:
+		return Rules{}, fmt.Errorf("%w: prazo da rodada deve ficar entre %d e %d ms", ErrInvalidRules, MinRulesTimeoutMs, MaxRulesTimeoutMs)
+	}
+
+	switch rules.DeckPolicy {
+	case DeckRandom, DeckSingleton, DeckDraft:
+	default:
+		return Rules{}, fmt.Errorf("%w: política de deck desconhecida %q", ErrInvalidRules, rules.DeckPolicy)
+	}
+
+	for _, cardID := range rules.BannedCards {
+		if !cardDB.ValidateCard(cardID) {
+			return Rules{}, fmt.Errorf("%w: carta banida desconhecida %q", ErrInvalidRules, cardID)
+		}
+	}
+
+	// As cartas permitidas precisam completar uma mão inicial
+	if len(rules.cardPool(cardDB)) < rules.HandSize {
+		return Rules{}, fmt.Errorf("%w: cartas banidas demais para uma mão de %d cartas", ErrInvalidRules, rules.HandSize)
+	}
+
+	return rules, nil
+}
+
+// View converte as regras para o formato do protocolo
+func (r Rules) View() *protocol.Rules {
+	bonus := r.ElementalATKBonus
+	return &protocol.Rules{
+		HPStart:           r.HPStart,
+		HandSize:          r.HandSize,
+		ElementalATKBonus: &bonus,
+		RoundPlayTimeout:  r.RoundPlayTimeout,
+		DeckPolicy:        string(r.DeckPolicy),
+		BannedCards:       append([]string{}, r.BannedCards...),
+	}
+}
+
+// isBanned verifica se a carta foi banida pelas regras
+func (r Rules) isBanned(cardID string) bool {
+	for _, bannedID := range r.BannedCards {
+		if bannedID == cardID {
+			return true
+		}
+	}
+	return false
+}
+
+// cardPool lista as cartas permitidas pelas regras, na ordem de sorteio do CardDB
+func (r Rules) cardPool(cardDB *CardDB) []string {
+	pool := []string{}
+	for _, cardID := range cardDB.CardIDs() {
+		if !r.isBanned(cardID) {
+			pool = append(pool, cardID)
+		}
+	}
+	return pool
+}
+
+// randomCard sorteia uma carta permitida com o gerador da partida (deve ser chamado com o lock adquirido)
+func (m *Match) randomCard() string {
+	if len(m.cardPool) == 0 {
+		return ""
+	}
+	return m.cardPool[m.rng.Intn(len(m.cardPool))]
+}
+
+// randomCards sorteia size cartas permitidas, com repetição (deve ser chamado com o lock adquirido)
+func (m *Match) randomCards(size int) []string {
+	cards := make([]string, size)
+	for i := range cards {
+		cards[i] = m.randomCard()
+	}
+	return cards
+}
+
+// singletonDeck monta um deck embaralhado com uma cópia de cada carta permitida
+func (m *Match) singletonDeck() []string {
+	deck := append([]string{}, m.cardPool...)
+	m.rng.Shuffle(len(deck), func(i, j int) {
+		deck[i], deck[j] = deck[j], deck[i]
+	})
+	return deck
+}
@@ -0,0 +1,330 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"pingpong/server/protocol"
+	"sort"
+)
+
+var (
+	ErrScenarioNotFound = errors.New("cenário não encontrado")
+	ErrNoSolution       = errors.New("cenário sem solução para verificar")
+)
+
+// MaxScenarioRounds é o limite de rodadas do objetivo de um cenário de treino
+const MaxScenarioRounds = 20
+
+// GoalType é o tipo de objetivo de um cenário de treino
+type GoalType string
+
+const (
+	GoalWin     GoalType = "WIN"     // vencer até a última rodada do objetivo
+	GoalSurvive GoalType = "SURVIVE" // continuar vivo ao fim da última rodada do objetivo
+)
+
+// ScenarioGoal é o objetivo do jogador no cenário
+type ScenarioGoal struct {
+	Type   GoalType `json:"type"`
+	Rounds int      `json:"rounds"` // rodadas disponíveis (WIN) ou a sobreviver (SURVIVE)
+}
+
+// ScenarioSide é a situação inicial de um lado do cenário
+type ScenarioSide struct {
+	HP    int            `json:"hp"`              // HP inicial e máximo (0 = HP inicial das regras)
+	Hand  []string       `json:"hand"`            // mão exata no início da primeira rodada
+	Deck  []string       `json:"deck,omitempty"`  // compras na ordem do arquivo (omitido = compra aleatória)
+	Plays []ScriptedPlay `json:"plays,omitempty"` // oponente: roteiro por rodada; jogador: solução conferida por Verify
+}
+
+// Scenario é um cenário de treino (puzzle): as mãos e o HP dos dois lados, as próximas jogadas do
+// oponente e o objetivo do jogador, jogados pelo motor normal da partida
+type Scenario struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Mode        MatchMode       `json:"mode"`            // SIMULTANEOUS (padrão) ou TURN_BASED
+	Rules       *protocol.Rules `json:"rules,omitempty"` // regras especiais (omitidas = padrão)
+	Seed        int64           `json:"seed,omitempty"`  // semente fixa (0 = seed do servidor)
+	Player      ScenarioSide    `json:"player"`
+	Opponent    ScenarioSide    `json:"opponent"`
+	Goal        ScenarioGoal    `json:"goal"`
+
+	rules Rules // regras validadas
+}
+
+// goalState é o objetivo ativo em uma partida de cenário (o jogador ocupa o primeiro assento)
+type goalState struct {
+	scenarioID string
+	ScenarioGoal
+}
+
+// ScenarioSet é o conjunto de cenários de treino carregados, ordenados pelo ID
+type ScenarioSet struct {
+	Scenarios []Scenario
+}
+
+// LoadScenarios carrega e valida os cenários do diretório (um arquivo JSON por cenário)
+func LoadScenarios(dir string, cardDB *CardDB) (*ScenarioSet, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar cenários: %w", err)
+	}
+
+	scenarios := make([]Scenario, 0, len(files))
+	seen := make(map[string]bool, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler cenário %s: %w", file, err)
+		}
+
+		var scenario Scenario
+		if err := json.Unmarshal(data, &scenario); err != nil {
+			return nil, fmt.Errorf("erro ao decodificar cenário %s: %w", file, err)
+		}
+		if err := scenario.validate(cardDB); err != nil {
+			return nil, fmt.Errorf("cenário %s: %w", file, err)
+		}
+		if seen[scenario.ID] {
+			return nil, fmt.Errorf("cenário %q repetido", scenario.ID)
+		}
+		seen[scenario.ID] = true
+		scenarios = append(scenarios, scenario)
+	}
+
+	sort.Slice(scenarios, func(i, j int) bool { return scenarios[i].ID < scenarios[j].ID })
+	return &ScenarioSet{Scenarios: scenarios}, nil
+}
+
+// validate confere o cenário e converte suas regras
+func (s *Scenario) validate(cardDB *CardDB) error {
+	if s.ID == "" {
+		return errors.New("ID vazio")
+	}
+	if s.Mode == "" {
+		s.Mode = ModeSimultaneous
+	}
+	if config, ok := ModeConfigs[s.Mode]; !ok || config.MaxPlayers != 2 || config.Draft || config.Boss {
+		return fmt.Errorf("modo %q não é 1v1 sem draft", s.Mode)
+	}
+	if s.Goal.Type != GoalWin && s.Goal.Type != GoalSurvive {
+		return fmt.Errorf("objetivo desconhecido %q", s.Goal.Type)
+	}
+	if s.Goal.Rounds < 1 || s.Goal.Rounds > MaxScenarioRounds {
+		return fmt.Errorf("rodadas do objetivo devem ficar entre 1 e %d", MaxScenarioRounds)
+	}
+	if len(s.Opponent.Plays) < s.Goal.Rounds {
+		return fmt.Errorf("o oponente precisa de uma jogada para cada uma das %d rodadas", s.Goal.Rounds)
+	}
+
+	rules, err := ParseRules(s.Rules, cardDB)
+	if err != nil {
+		return err
+	}
+	if rules.DeckPolicy == DeckDraft {
+		return errors.New("cenários não aceitam deck de draft")
+	}
+	s.rules = rules
+
+	for _, side := range []struct {
+		name string
+		ScenarioSide
+	}{{"jogador", s.Player}, {"oponente", s.Opponent}} {
+		if err := s.validateSide(side.ScenarioSide, cardDB); err != nil {
+			return fmt.Errorf("%s: %w", side.name, err)
+		}
+	}
+	return nil
+}
+
+// validateSide confere o HP, as cartas e o roteiro de um lado do cenário
+func (s *Scenario) validateSide(side ScenarioSide, cardDB *CardDB) error {
+	if side.HP < 0 || side.HP > MaxRulesHP {
+		return fmt.Errorf("HP deve ficar entre 0 e %d", MaxRulesHP)
+	}
+	if len(side.Hand) == 0 || len(side.Hand) > s.rules.HandSize {
+		return fmt.Errorf("a mão deve ter entre 1 e %d cartas", s.rules.HandSize)
+	}
+
+	cards := append(append([]string{}, side.Hand...), side.Deck...)
+	for _, play := range side.Plays {
+		switch play.Action {
+		case "", ActionPlay, ActionDefend, ActionCycle:
+		default:
+			return fmt.Errorf("ação desconhecida %q no roteiro", play.Action)
+		}
+		if play.Action != "" && play.Action != ActionPlay && s.Mode == ModeTurnBased {
+			return ErrActionUnavailable
+		}
+		if play.Action == ActionCycle && len(play.Cards) != CycleCards {
+			return ErrCycleCards
+		}
+		if play.CardID != "" {
+			cards = append(cards, play.CardID)
+		}
+		cards = append(cards, play.Cards...)
+	}
+	for _, cardID := range cards {
+		if !cardDB.ValidateCard(cardID) {
+			return fmt.Errorf("carta desconhecida %q", cardID)
+		}
+	}
+	return nil
+}
+
+// Find retorna o cenário com o ID informado
+func (set *ScenarioSet) Find(scenarioID string) (Scenario, bool) {
+	for _, scenario := range set.Scenarios {
+		if scenario.ID == scenarioID {
+			return scenario, true
+		}
+	}
+	return Scenario{}, false
+}
+
+// Views descreve os cenários disponíveis
+func (set *ScenarioSet) Views() []protocol.ScenarioView {
+	views := make([]protocol.ScenarioView, len(set.Scenarios))
+	for i, scenario := range set.Scenarios {
+		views[i] = protocol.ScenarioView{
+			ID:          scenario.ID,
+			Name:        scenario.Name,
+			Description: scenario.Description,
+			Mode:        string(scenario.Mode),
+			Goal:        string(scenario.Goal.Type),
+			Rounds:      scenario.Goal.Rounds,
+		}
+	}
+	return views
+}
+
+// BotID retorna o ID do assento do oponente do cenário
+func (s Scenario) BotID() string {
+	return BotID(s.ID)
+}
+
+// NewMatch cria a partida do cenário: o jogador no primeiro assento e o oponente roteirizado no
+// segundo, sem mulligan (os observers do jogador devem ser registrados antes de Start)
+func (s Scenario) NewMatch(id, playerID string, cardDB *CardDB, seed int64) *Match {
+	match := s.newMatch(id, playerID, cardDB, seed)
+	NewScriptedBot(match, s.BotID(), s.Opponent.Plays)
+	return match
+}
+
+// newMatch monta a situação inicial do cenário, sem controlador para o oponente
+func (s Scenario) newMatch(id, playerID string, cardDB *CardDB, seed int64) *Match {
+	if s.Seed != 0 {
+		seed = s.Seed
+	}
+	match := NewMatch(id, []string{playerID, s.BotID()}, cardDB, s.Mode, seed, s.rules)
+	for _, seat := range []struct {
+		playerID string
+		side     ScenarioSide
+	}{{playerID, s.Player}, {s.BotID(), s.Opponent}} {
+		match.SetSeat(seat.playerID, seat.side.HP, nil)
+		match.SetHand(seat.playerID, seat.side.Hand, seat.side.Deck)
+	}
+
+	match.mu.Lock()
+	match.goal = &goalState{scenarioID: s.ID, ScenarioGoal: s.Goal}
+	match.mu.Unlock()
+	return match
+}
+
+// Verify joga o cenário com a solução do arquivo (player.plays) contra o roteiro do oponente, sem
+// conexões (sem seed no arquivo, usa a semente 1), e retorna o resultado do objetivo. Serve de teste de
+// regressão dos cenários quando as regras mudam
+func (s Scenario) Verify(cardDB *CardDB) (protocol.ScenarioReport, error) {
+	if len(s.Player.Plays) == 0 {
+		return protocol.ScenarioReport{}, ErrNoSolution
+	}
+
+	const playerID = "player"
+	match := s.newMatch("verify_"+s.ID, playerID, cardDB, 1)
+	var report *protocol.ScenarioReport
+	match.Subscribe(ObserverFunc(func(event Event) {
+		if event.Msg.T == protocol.SCENARIO_RESULT {
+			report = event.Msg.Report
+		}
+	}))
+	match.Start()
+
+	for round := 1; report == nil; round++ {
+		if round > len(s.Player.Plays) {
+			match.Forfeit(playerID)
+			return protocol.ScenarioReport{}, fmt.Errorf("a solução terminou na rodada %d sem decidir o objetivo", round-1)
+		}
+
+		pending := []Play{}
+		for _, scripted := range []struct {
+			playerID string
+			play     ScriptedPlay
+		}{{playerID, s.Player.Plays[round-1]}, {s.BotID(), s.Opponent.Plays[round-1]}} {
+			if !scripted.play.empty() {
+				pending = append(pending, scripted.play.play(scripted.playerID))
+			}
+		}
+
+		// No modo por turnos o atacante joga primeiro: quem não está na vez tenta de novo em seguida
+		for len(pending) > 0 {
+			play := pending[0]
+			err := match.Apply(play)
+			pending = pending[1:]
+			if errors.Is(err, ErrNotYourTurn) && len(pending) > 0 {
+				pending = append(pending, play)
+				continue
+			}
+			if err != nil {
+				match.Forfeit(playerID)
+				return protocol.ScenarioReport{}, fmt.Errorf("rodada %d, jogada de %s: %w", round, play.PlayerID, err)
+			}
+		}
+	}
+	return *report, nil
+}
+
+// endIfGoalDecided encerra o cenário de treino ao fim da última rodada do objetivo: quem sobrevive
+// cumpre SURVIVE e quem ainda não venceu falha em WIN (deve ser chamado com o lock adquirido)
+func (m *Match) endIfGoalDecided() bool {
+	if m.goal == nil || m.Round-1 < m.goal.Rounds {
+		return false
+	}
+
+	playerResult, opponentResult := protocol.WIN, protocol.LOSE
+	if m.goal.Type == GoalWin {
+		playerResult, opponentResult = protocol.LOSE, protocol.WIN
+	}
+	m.finish(protocol.END_GOAL, map[string]string{m.Players[0]: playerResult, m.Players[1]: opponentResult})
+	return true
+}
+
+// reportGoal envia ao jogador se o objetivo do cenário foi cumprido (deve ser chamado com o lock
+// adquirido)
+func (m *Match) reportGoal(results map[string]string) {
+	if m.goal == nil {
+		return
+	}
+
+	playerID := m.Players[0]
+	report := protocol.ScenarioReport{
+		ScenarioID: m.goal.scenarioID,
+		Goal:       string(m.goal.Type),
+		Rounds:     m.goal.Rounds,
+		Round:      m.Round - 1,
+		Passed:     results[playerID] == protocol.WIN,
+		HP:         m.HP[0],
+		OpponentHP: m.HP[1],
+	}
+	m.emit(playerID, protocol.ServerMsg{T: protocol.SCENARIO_RESULT, Report: &report})
+
+	outcome := "falhou"
+	if report.Passed {
+		outcome = "cumprido"
+	}
+	log.Printf("[MATCH %s] Cenário %s: objetivo %s em %d rodada(s) %s", m.ID, report.ScenarioID, report.Goal, report.Rounds, outcome)
+}
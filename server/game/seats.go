@@ -0,0 +1,191 @@
+package game
+
+import (
+	"fmt"
+	"pingpong/server/protocol"
+)
+
+// isActive verifica se o assento ainda participa das rodadas (não eliminado e não saiu)
+func (m *Match) isActive(seat int) bool {
+	return !m.Left[seat] && m.HP[seat] > 0
+}
+
+// activeSeats retorna os assentos ativos em ordem
+func (m *Match) activeSeats() []int {
+	seats := []int{}
+	for seat := range m.Players {
+		if m.isActive(seat) {
+			seats = append(seats, seat)
+		}
+	}
+	return seats
+}
+
+// activeTeams retorna os times que ainda possuem algum assento ativo
+func (m *Match) activeTeams() map[int]bool {
+	teams := make(map[int]bool)
+	for _, seat := range m.activeSeats() {
+		teams[m.Teams[seat]] = true
+	}
+	return teams
+}
+
+// allPlayed verifica se todos os assentos ativos registraram jogada
+func (m *Match) allPlayed() bool {
+	for _, seat := range m.activeSeats() {
+		if _, played := m.Waiting[m.Players[seat]]; !played {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidTarget verifica se o alvo é um adversário ativo do jogador
+func (m *Match) isValidTarget(seat, target int) bool {
+	return m.isActive(target) && m.Teams[target] != m.Teams[seat]
+}
+
+// defaultTarget escolhe o próximo adversário ativo após o assento (em ordem circular)
+func (m *Match) defaultTarget(seat int) int {
+	seats := len(m.Players)
+	fallback := seat
+	for offset := 1; offset < seats; offset++ {
+		target := (seat + offset) % seats
+		if m.Teams[target] == m.Teams[seat] {
+			continue
+		}
+		if m.isActive(target) {
+			return target
+		}
+		if fallback == seat {
+			fallback = target
+		}
+	}
+	return fallback
+}
+
+// SetSeat define o HP inicial e máximo (0 = padrão) e o deck fixo (nil = compra aleatória) de um
+// assento, refazendo sua mão inicial com o deck embaralhado (antes de Start)
+func (m *Match) SetSeat(playerID string, hp int, deck []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seat := m.GetPlayerIndex(playerID)
+	if seat < 0 {
+		return
+	}
+	if hp > 0 {
+		m.MaxHP[seat] = hp
+		m.HP[seat] = hp
+	}
+	if deck != nil {
+		m.Decks[seat] = append([]string{}, deck...)
+		m.rng.Shuffle(len(m.Decks[seat]), func(i, j int) {
+			m.Decks[seat][i], m.Decks[seat][j] = m.Decks[seat][j], m.Decks[seat][i]
+		})
+		m.Hands[seat] = Hand{}
+		m.refillHands()
+	}
+}
+
+// SetHand define a mão exata de um assento e, se informado, o deck na ordem de compra, sem
+// embaralhar (antes de Start)
+func (m *Match) SetHand(playerID string, hand []string, deck []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seat := m.GetPlayerIndex(playerID)
+	if seat < 0 {
+		return
+	}
+	m.Hands[seat] = append(Hand{}, hand...)
+	if deck != nil {
+		m.Decks[seat] = append([]string{}, deck...)
+	}
+}
+
+// maxHP retorna o HP máximo padrão de um assento (o HP do time quando compartilhado)
+func (m *Match) maxHP() int {
+	config := ModeConfigs[m.Mode]
+	if config.SharedHP {
+		return m.Rules.HPStart * config.TeamSize
+	}
+	return m.Rules.HPStart
+}
+
+// damage aplica dano ao assento (ou a todo o time quando o HP é compartilhado)
+func (m *Match) damage(seat, amount int) {
+	if amount <= 0 {
+		return
+	}
+	for _, member := range m.hpGroup(seat) {
+		m.HP[member] -= amount
+	}
+}
+
+// heal cura o assento até o HP máximo e retorna quanto foi curado
+func (m *Match) heal(seat, amount int) int {
+	amount = min(amount, m.MaxHP[seat]-m.HP[seat])
+	if amount <= 0 {
+		return 0
+	}
+	for _, member := range m.hpGroup(seat) {
+		m.HP[member] += amount
+	}
+	return amount
+}
+
+// hpGroup retorna os assentos que compartilham o HP do assento informado
+func (m *Match) hpGroup(seat int) []int {
+	if !ModeConfigs[m.Mode].SharedHP {
+		return []int{seat}
+	}
+	group := []int{}
+	for member := range m.Players {
+		if m.Teams[member] == m.Teams[seat] {
+			group = append(group, member)
+		}
+	}
+	return group
+}
+
+// markEliminated registra a rodada de eliminação dos assentos que chegaram a HP ≤ 0
+func (m *Match) markEliminated(playing []int) {
+	for _, seat := range playing {
+		if m.HP[seat] <= 0 && m.EliminatedRound[seat] == 0 {
+			m.EliminatedRound[seat] = m.Round
+		}
+	}
+}
+
+// seatLabel retorna como o assento é chamado nos logs enviados ao jogador viewer
+func (m *Match) seatLabel(viewer, seat int) string {
+	switch {
+	case viewer == seat:
+		return "Você"
+	case len(m.Players) == 2:
+		return "Oponente"
+	case m.isBoss(seat):
+		return fmt.Sprintf("Chefe %s", m.boss.Name)
+	case m.Teams[viewer] == m.Teams[seat]:
+		return fmt.Sprintf("Aliado %s", m.Players[seat])
+	}
+	return fmt.Sprintf("Jogador %s", m.Players[seat])
+}
+
+// targetLabel retorna como o alvo é chamado no meio de uma frase dos logs
+func (m *Match) targetLabel(viewer, seat int) string {
+	if viewer == seat {
+		return "você"
+	}
+	return m.seatLabel(viewer, seat)
+}
+
+// seatView cria a descrição básica de um assento para STATE e ROUND_RESULT
+func (m *Match) seatView(seat int) protocol.SeatView {
+	return protocol.SeatView{
+		PlayerID:   m.Players[seat],
+		Team:       m.Teams[seat],
+		Eliminated: !m.isActive(seat),
+	}
+}
@@ -0,0 +1,79 @@
+package game
+
+import (
+	"errors"
+	"pingpong/server/protocol"
+	"reflect"
+	"testing"
+)
+
+func TestFreeForAllLastStanding(t *testing.T) {
+	hand := Hand{"c_007", "c_004", "c_006", "c_004", "c_006"}
+	match := newRoundMatch(t, ModeFreeForAll, hand, hand, hand)
+	results := watchEnd(match)
+
+	// Rodada 1: p1 e p2 eliminam p3 (10 ATK - 7 DEF, duas vezes); a partida segue com dois ativos
+	match.HP[2] = 5
+	for _, play := range []Play{
+		{PlayerID: "p1", CardID: "c_007", Target: "p3", Lock: true},
+		{PlayerID: "p2", CardID: "c_007", Target: "p3", Lock: true},
+		{PlayerID: "p3", CardID: "c_004", Target: "p1", Lock: true},
+	} {
+		if err := match.Apply(play); err != nil {
+			t.Fatalf("Jogada de %s rejeitada: %v", play.PlayerID, err)
+		}
+	}
+	if match.EliminatedRound[2] != 1 || match.isActive(2) {
+		t.Fatalf("p3 deveria ter sido eliminado na rodada 1 (HP %d)", match.HP[2])
+	}
+	if match.State == StateEnded {
+		t.Fatal("Partida terminou com dois jogadores ativos")
+	}
+	if err := match.Apply(Play{PlayerID: "p1", CardID: "c_004", Target: "p3"}); !errors.Is(err, ErrInvalidTarget) {
+		t.Errorf("Alvo eliminado: esperado ErrInvalidTarget, obtido %v", err)
+	}
+
+	// Rodada 2: o alvo de p2 (eliminado) volta ao adversário ativo; FIRE > PLANT elimina p2
+	match.HP[1] = 1
+	mustPlay(t, match, "p1", "c_004")
+	mustPlay(t, match, "p2", "c_006")
+
+	want := map[string]string{"p1": protocol.WIN, "p2": protocol.LOSE, "p3": protocol.LOSE}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("Resultados esperados %v, obtidos %v", want, results)
+	}
+}
+
+func TestSharedHPTeamWin(t *testing.T) {
+	hand := Hand{"c_007", "c_007", "c_007", "c_007", "c_007"} // Inferno Titan (ATK 10 / DEF 2)
+	match := newRoundMatch(t, ModeTeamsShared, hand, hand, hand, hand)
+	results := watchEnd(match)
+
+	if match.Teams[0] != match.Teams[1] || match.Teams[2] != match.Teams[3] || match.Teams[0] == match.Teams[2] {
+		t.Fatalf("Times esperados p1+p2 contra p3+p4, obtidos %v", match.Teams)
+	}
+	if match.HP[0] != HPStart*2 {
+		t.Fatalf("HP compartilhado esperado %d, obtido %d", HPStart*2, match.HP[0])
+	}
+
+	// p3 e p4 já estão quase sem HP; o dano recebido por um assento vale para o time todo
+	match.HP[2], match.HP[3] = 10, 10
+	for _, play := range []Play{
+		{PlayerID: "p1", CardID: "c_007", Target: "p3", Lock: true},
+		{PlayerID: "p2", CardID: "c_007", Target: "p4", Lock: true},
+		{PlayerID: "p3", CardID: "c_007", Target: "p1", Lock: true},
+		{PlayerID: "p4", CardID: "c_007", Target: "p2", Lock: true},
+	} {
+		if err := match.Apply(play); err != nil {
+			t.Fatalf("Jogada de %s rejeitada: %v", play.PlayerID, err)
+		}
+	}
+
+	if match.HP[0] != HPStart*2-16 || match.HP[1] != match.HP[0] {
+		t.Errorf("HP do time de p1 esperado %d nos dois assentos, obtido %d/%d", HPStart*2-16, match.HP[0], match.HP[1])
+	}
+	want := map[string]string{"p1": protocol.WIN, "p2": protocol.WIN, "p3": protocol.LOSE, "p4": protocol.LOSE}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("Resultados esperados %v, obtidos %v", want, results)
+	}
+}
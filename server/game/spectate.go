@@ -0,0 +1,42 @@
+package game
+
+import "pingpong/server/protocol"
+
+// SpectatorMsg converte um registro da partida na mensagem enviada aos espectadores (ok = false para
+// registros que não são mostrados). A visão é neutra: nunca inclui as mãos, os decks ou a seed.
+//   - START vira SPECTATING, com o modo, as regras e os jogadores
+//   - ROUND_RESULT traz apenas os assentos (HP, carta jogada, dano e efeitos de cada um)
+//   - END vira MATCH_END com o resultado de cada jogador
+func SpectatorMsg(event Event) (protocol.ServerMsg, bool) {
+	record := event.Record
+	if record == nil {
+		return protocol.ServerMsg{}, false
+	}
+
+	switch record.T {
+	case RecordStart:
+		return protocol.ServerMsg{
+			T:         protocol.SPECTATING,
+			MatchID:   event.MatchID,
+			Mode:      record.Mode,
+			Rules:     record.Rules,
+			PlayerIDs: record.Players,
+		}, true
+	case RecordRoundResult:
+		return protocol.ServerMsg{
+			T:       protocol.ROUND_RESULT,
+			MatchID: event.MatchID,
+			Round:   record.Round,
+			Terrain: record.Terrain,
+			Seats:   record.Seats,
+		}, true
+	case RecordEnd:
+		return protocol.ServerMsg{
+			T:       protocol.MATCH_END,
+			MatchID: event.MatchID,
+			Reason:  record.Reason,
+			Results: record.Results,
+		}, true
+	}
+	return protocol.ServerMsg{}, false
+}
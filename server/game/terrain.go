@@ -0,0 +1,61 @@
+package game
+
+import "log"
+
+// Terrain representa o terreno da rodada, que fortalece um elemento e enfraquece outro
+type Terrain string
+
+const (
+	TerrainNeutral Terrain = "NEUTRAL"
+	TerrainVolcano Terrain = "VOLCANO" // FIRE fortalecido, WATER enfraquecido
+	TerrainOcean   Terrain = "OCEAN"   // WATER fortalecido, PLANT enfraquecido
+	TerrainForest  Terrain = "FOREST"  // PLANT fortalecido, FIRE enfraquecido
+)
+
+// Terrains lista os terrenos sorteados a cada rodada, na ordem usada pelo gerador da partida
+var Terrains = []Terrain{TerrainNeutral, TerrainVolcano, TerrainOcean, TerrainForest}
+
+// terrainEffects define o elemento fortalecido e o enfraquecido por cada terreno: o enfraquecido
+// é o que normalmente vence o fortalecido, contrariando a vantagem elemental de costume
+var terrainEffects = map[Terrain]struct{ boosted, weakened Element }{
+	TerrainVolcano: {boosted: FIRE, weakened: WATER},
+	TerrainOcean:   {boosted: WATER, weakened: PLANT},
+	TerrainForest:  {boosted: PLANT, weakened: FIRE},
+}
+
+// SetTerrain ativa ou desativa o modificador de terreno da partida (antes de Start)
+func (m *Match) SetTerrain(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.terrainEnabled = enabled
+}
+
+// rollTerrain sorteia o terreno da rodada que está começando com o gerador da partida
+// (deve ser chamado com o lock adquirido)
+func (m *Match) rollTerrain() {
+	if !m.terrainEnabled {
+		return
+	}
+	m.Terrain = Terrains[m.rng.Intn(len(Terrains))]
+	log.Printf("[MATCH %s] Terreno da rodada %d: %s", m.ID, m.Round, m.Terrain)
+}
+
+// TerrainBonus calcula o ajuste de ATK de um elemento no terreno
+func TerrainBonus(terrain Terrain, element Element) int {
+	effect, ok := terrainEffects[terrain]
+	switch {
+	case !ok:
+		return 0
+	case element == effect.boosted:
+		return TerrainATKBonus
+	case element == effect.weakened:
+		return -TerrainATKBonus
+	}
+	return 0
+}
+
+// terrainBonusOf retorna o ajuste de ATK da carta no terreno da rodada atual
+func (m *Match) terrainBonusOf(card Card) int {
+	return TerrainBonus(m.Terrain, card.Element)
+}
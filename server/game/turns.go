@@ -0,0 +1,103 @@
+package game
+
+import (
+	"errors"
+	"pingpong/server/protocol"
+)
+
+var (
+	ErrNotYourTurn = errors.New("não é sua vez de jogar")
+)
+
+// attackerIndex retorna o índice do atacante da rodada atual (P1 ataca nas rodadas ímpares)
+func (m *Match) attackerIndex() int {
+	return (m.Round - 1) % 2
+}
+
+// roundStartState retorna o estado inicial de uma rodada conforme o modo da partida
+func (m *Match) roundStartState() MatchState {
+	if m.Mode == ModeTurnBased {
+		return StateAwaitingAttack
+	}
+	return StateAwaitingPlays
+}
+
+// awaitingPlays verifica se a partida está aceitando jogadas
+func (m *Match) awaitingPlays() bool {
+	return m.State == StateAwaitingPlays ||
+		m.State == StateAwaitingAttack ||
+		m.State == StateAwaitingDefense
+}
+
+// isPlayersTurn verifica se o jogador pode jogar na fase atual
+func (m *Match) isPlayersTurn(playerIndex int) bool {
+	switch m.State {
+	case StateAwaitingPlays:
+		return true
+	case StateAwaitingAttack:
+		return playerIndex == m.attackerIndex()
+	case StateAwaitingDefense:
+		return playerIndex != m.attackerIndex()
+	}
+	return false
+}
+
+// advanceRound avança a rodada conforme as jogadas registradas (deve ser chamado com o lock adquirido)
+func (m *Match) advanceRound() {
+	if m.State == StateAwaitingAttack {
+		if _, played := m.Waiting[m.Players[m.attackerIndex()]]; !played {
+			return
+		}
+
+		// Atacante jogou: o defensor vê a carta e tem um novo prazo para responder
+		m.State = StateAwaitingDefense
+		m.startPlayClock()
+		m.BroadcastState()
+
+		if !m.allPlayed() {
+			return
+		}
+	}
+
+	if !m.allPlayed() {
+		return
+	}
+
+	m.resolveRound()
+
+	// Jogadores que passaram a vez na nova rodada podem permitir avançar imediatamente
+	if m.State != StateEnded {
+		m.advanceRound()
+	}
+}
+
+// turnLogs cria o log de papel do jogador na rodada (apenas no modo por turnos)
+func (m *Match) turnLogs(playerIndex int) []string {
+	if m.Mode != ModeTurnBased {
+		return nil
+	}
+	if playerIndex == m.attackerIndex() {
+		return []string{"Você atacou nesta rodada."}
+	}
+	return []string{"Você defendeu nesta rodada."}
+}
+
+// addTurnInfo adiciona ao STATE o modo, a fase e, na defesa, a carta revelada do atacante
+func (m *Match) addTurnInfo(msg *protocol.ServerMsg, playerIndex int) {
+	if m.Mode != ModeTurnBased {
+		return
+	}
+
+	msg.Mode = string(m.Mode)
+	msg.Phase = string(m.State)
+	msg.AttackerID = m.Players[m.attackerIndex()]
+
+	if m.State == StateAwaitingDefense {
+		attackCardID := m.Waiting[msg.AttackerID]
+		if playerIndex == m.attackerIndex() {
+			msg.You.CardID = attackCardID
+		} else {
+			msg.Opponent.CardID = attackCardID
+		}
+	}
+}
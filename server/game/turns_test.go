@@ -0,0 +1,47 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTurnOrder(t *testing.T) {
+	match := newRoundMatch(t, ModeTurnBased,
+		Hand{"c_007", "c_004", "c_004", "c_004", "c_004"}, // Inferno Titan (ATK 10 / DEF 2)
+		Hand{"c_004", "c_004", "c_004", "c_004", "c_004"}, // Flame Warrior (ATK 6 / DEF 7)
+	)
+
+	// Rodada 1: p1 ataca primeiro; o defensor só joga depois de ver o ataque
+	if match.State != StateAwaitingAttack {
+		t.Fatalf("Estado esperado %s, obtido %s", StateAwaitingAttack, match.State)
+	}
+	if err := match.Apply(Play{PlayerID: "p2", CardID: "c_004", Lock: true}); !errors.Is(err, ErrNotYourTurn) {
+		t.Fatalf("Defensor jogou antes do ataque: %v", err)
+	}
+	mustPlay(t, match, "p1", "c_007")
+	if match.State != StateAwaitingDefense {
+		t.Fatalf("Estado esperado %s, obtido %s", StateAwaitingDefense, match.State)
+	}
+	if err := match.Apply(Play{PlayerID: "p1", CardID: "c_004", Lock: true}); !errors.Is(err, ErrNotYourTurn) {
+		t.Fatalf("Atacante jogou na fase de defesa: %v", err)
+	}
+	mustPlay(t, match, "p2", "c_004")
+
+	// Apenas o atacante causa dano: 10 ATK - 7 DEF = 3
+	if match.HP[0] != HPStart || match.HP[1] != HPStart-3 {
+		t.Errorf("HP esperado %d/%d, obtido %d/%d", HPStart, HPStart-3, match.HP[0], match.HP[1])
+	}
+
+	// Rodada 2: os papéis se invertem
+	if match.Round != 2 || match.attackerIndex() != 1 {
+		t.Fatalf("Na rodada 2 p2 deveria atacar (rodada %d, atacante %d)", match.Round, match.attackerIndex())
+	}
+	if err := match.Apply(Play{PlayerID: "p1", CardID: "c_004", Lock: true}); !errors.Is(err, ErrNotYourTurn) {
+		t.Fatalf("p1 jogou antes do ataque de p2: %v", err)
+	}
+	mustPlay(t, match, "p2", "c_004")
+	mustPlay(t, match, "p1", "c_004")
+	if match.Round != 3 || match.State != StateAwaitingAttack {
+		t.Errorf("Esperada rodada 3 aguardando ataque, obtida rodada %d em %s", match.Round, match.State)
+	}
+}
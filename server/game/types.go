@@ -1,5 +1,7 @@
 package game
 
+import "time"
+
 // Element representa os tipos elementais do jogo
 type Element string
 
@@ -16,21 +18,123 @@ type Card struct {
 	Element Element `json:"element"`
 	ATK     int     `json:"atk"`
 	DEF     int     `json:"def"`
+	Cost    int     `json:"cost"`
+	Effect  *Effect `json:"effect,omitempty"`
+}
+
+// StatusType representa os tipos de efeito de status que persistem entre rodadas
+type StatusType string
+
+const (
+	BURN   StatusType = "BURN"   // dano por rodada
+	POISON StatusType = "POISON" // dano por rodada, mais fraco e duradouro
+	FREEZE StatusType = "FREEZE" // jogador pula a próxima jogada (auto-pass)
+	REGEN  StatusType = "REGEN"  // cura por rodada
+)
+
+// Effect descreve o efeito de status aplicado por uma carta ao ser jogada
+type Effect struct {
+	Type     StatusType `json:"type"`
+	Duration int        `json:"duration"` // em rodadas
+	Stacks   int        `json:"stacks"`
+}
+
+// StatusEffect representa um efeito de status ativo em um jogador
+type StatusEffect struct {
+	Type     StatusType
+	Duration int // rodadas restantes
+	Stacks   int
 }
 
 // Hand representa a mão de um jogador (IDs das cartas)
 type Hand []string
 
-// Constantes do jogo configuráveis
+// Constantes do jogo configuráveis (HPStart, HandSize, ElementalATKBonus e RoundPlayTimeout são os
+// valores de DefaultRules, que as salas personalizadas podem alterar)
 const (
 	HPStart           = 20
 	HandSize          = 5
 	ElementalATKBonus = 3
-	RoundPlayTimeout  = 12_000 // ms
-	MatchIdleTimeout  = 60_000 // ms
+	TerrainATKBonus   = 2      // ajuste de ATK do elemento fortalecido (+) ou enfraquecido (-) pelo terreno
+	RoundPlayTimeout  = 12_000 // ms (prazo base do controle de tempo clássico)
+	MatchIdleTimeout  = 60_000 // ms sem nenhuma ação própria antes de perder por abandono (clássico e blitz)
+	AFKAutoplayLimit  = 3      // timeouts consecutivos antes de perder por abandono
 	KeepAliveInterval = 5_000  // ms para PING
 	KeepAliveTimeout  = 30_000 // ms sem tráfego
 	ReconnectWindow   = 10_000 // ms para reconexão rápida
+	FreeForAllWait    = 15_000 // ms de espera antes de iniciar todos contra todos com menos jogadores que o máximo
+)
+
+// TimeControl descreve o controle de tempo de uma partida: cada fase tem um prazo base e,
+// quando ele acaba, quem ainda não jogou consome o próprio banco de tempo
+type TimeControl struct {
+	Name   string
+	BaseMs int // prazo base de cada fase
+	BankMs int // banco de tempo inicial de cada jogador, para toda a partida
+	IdleMs int // tempo sem nenhuma ação própria antes de perder por abandono
+}
+
+// Controles de tempo escolhidos no matchmaking
+const (
+	TimeBlitz          = "BLITZ"
+	TimeClassic        = "CLASSIC"
+	TimeCorrespondence = "CORRESPONDENCE"
+)
+
+// TimeControls define os controles de tempo disponíveis
+var TimeControls = map[string]TimeControl{
+	TimeBlitz:          {Name: TimeBlitz, BaseMs: 5_000, BankMs: 15_000, IdleMs: MatchIdleTimeout},
+	TimeClassic:        {Name: TimeClassic, BaseMs: RoundPlayTimeout, BankMs: 30_000, IdleMs: MatchIdleTimeout},
+	TimeCorrespondence: {Name: TimeCorrespondence, BaseMs: 24 * 3600_000, BankMs: 48 * 3600_000, IdleMs: 7 * 24 * 3600_000},
+}
+
+// ParseTimeControl converte o controle de tempo recebido do cliente (vazio = clássico)
+func ParseTimeControl(name string) (TimeControl, bool) {
+	if name == "" {
+		return TimeControls[TimeClassic], true
+	}
+	timeControl, ok := TimeControls[name]
+	return timeControl, ok
+}
+
+// Parâmetros do modo draft
+const (
+	DraftPacks       = 3      // pacotes abertos por jogador
+	DraftPackSize    = 5      // cartas por pacote
+	DraftPickTimeout = 15_000 // ms para escolher uma carta
+)
+
+// Parâmetros do mulligan
+const (
+	MulliganTimeout = 15_000 // ms para decidir quais cartas da mão inicial devolver
+)
+
+// Parâmetros dos desafios
+const (
+	ChallengeTimeout = 60_000  // ms para o desafiado aceitar um desafio direto
+	InviteTimeout    = 600_000 // ms de validade de um código de convite
+)
+
+// Parâmetros das ações de rodada
+const (
+	DefendMultiplier = 2 // multiplicador da DEF da carta usada no DEFEND
+	CycleCards       = 2 // cartas descartadas (e compradas) no CYCLE
+)
+
+// Parâmetros de energia
+const (
+	EnergyStart     = 4 // energia inicial (suficiente para qualquer carta)
+	EnergyRegen     = 2 // energia recuperada a cada rodada
+	EnergyMaxGrowth = 1 // crescimento da energia máxima a cada rodada
+	EnergyCap       = 8 // limite da energia máxima
+)
+
+// Parâmetros dos efeitos de status
+const (
+	BurnDamagePerStack   = 2
+	PoisonDamagePerStack = 1
+	RegenHealPerStack    = 2
+	MaxStatusStacks      = 3
 )
 
 // PlayerStatus representa o estado de um jogador
@@ -48,13 +152,73 @@ const (
 type MatchState string
 
 const (
-	StateAwaitingPlays MatchState = "AWAITING_PLAYS"
-	StateResolving     MatchState = "RESOLVING"
-	StateBroadcasting  MatchState = "BROADCASTING"
-	StateNextRound     MatchState = "NEXT_ROUND"
-	StateEnded         MatchState = "ENDED"
+	StateDrafting        MatchState = "DRAFTING" // modo draft: jogadores escolhem as cartas do deck
+	StateMulligan        MatchState = "MULLIGAN" // jogadores podem trocar parte da mão inicial
+	StateAwaitingPlays   MatchState = "AWAITING_PLAYS"
+	StateAwaitingAttack  MatchState = "AWAITING_ATTACK"  // modo por turnos: aguarda o atacante
+	StateAwaitingDefense MatchState = "AWAITING_DEFENSE" // modo por turnos: aguarda o defensor
+	StateResolving       MatchState = "RESOLVING"
+	StateBroadcasting    MatchState = "BROADCASTING"
+	StateNextRound       MatchState = "NEXT_ROUND"
+	StateEnded           MatchState = "ENDED"
 )
 
+// MatchMode representa o modo de jogo escolhido no matchmaking
+type MatchMode string
+
+const (
+	ModeSimultaneous MatchMode = "SIMULTANEOUS"     // 1v1 com revelação simultânea (padrão)
+	ModeTurnBased    MatchMode = "TURN_BASED"       // 1v1 com atacante e defensor alternados
+	ModeTeams        MatchMode = "TEAMS_2V2"        // 2v2 com HP individual
+	ModeTeamsShared  MatchMode = "TEAMS_2V2_SHARED" // 2v2 com HP compartilhado pelo time
+	ModeFreeForAll   MatchMode = "FREE_FOR_ALL"     // 3 a 4 jogadores, cada um por si
+	ModeDraft        MatchMode = "DRAFT"            // 1v1 simultâneo com deck montado em draft
+	ModeRaid         MatchMode = "RAID"             // 2 jogadores cooperando contra um chefe do servidor
+)
+
+// ModeConfig descreve os assentos e times de um modo de jogo
+type ModeConfig struct {
+	MinPlayers int
+	MaxPlayers int
+	TeamSize   int  // jogadores por time (1 = cada um por si)
+	SharedHP   bool // o time compartilha um único HP (HPStart × TeamSize)
+	Draft      bool // os jogadores montam o deck da partida em um draft antes da primeira rodada
+	Boss       bool // um chefe do servidor ocupa um assento extra, no time adversário
+}
+
+// ModeConfigs define a configuração de cada modo de jogo
+var ModeConfigs = map[MatchMode]ModeConfig{
+	ModeSimultaneous: {MinPlayers: 2, MaxPlayers: 2, TeamSize: 1},
+	ModeTurnBased:    {MinPlayers: 2, MaxPlayers: 2, TeamSize: 1},
+	ModeTeams:        {MinPlayers: 4, MaxPlayers: 4, TeamSize: 2},
+	ModeTeamsShared:  {MinPlayers: 4, MaxPlayers: 4, TeamSize: 2, SharedHP: true},
+	ModeFreeForAll:   {MinPlayers: 3, MaxPlayers: 4, TeamSize: 1},
+	ModeDraft:        {MinPlayers: 2, MaxPlayers: 2, TeamSize: 1, Draft: true},
+	ModeRaid:         {MinPlayers: 2, MaxPlayers: 2, TeamSize: 2, Boss: true},
+}
+
+// ParseMatchMode converte o modo recebido do cliente (vazio = simultâneo)
+func ParseMatchMode(mode string) (MatchMode, bool) {
+	if mode == "" {
+		return ModeSimultaneous, true
+	}
+	if _, ok := ModeConfigs[MatchMode(mode)]; ok {
+		return MatchMode(mode), true
+	}
+	return "", false
+}
+
+// Play representa uma jogada enviada por um jogador
+type Play struct {
+	PlayerID string
+	CardID   string
+	Action   Action    // ação da rodada (vazio = PLAY)
+	Cards    []string  // cartas descartadas no CYCLE
+	Target   string    // ID do jogador alvo (opcional, partidas com mais de dois jogadores)
+	At       time.Time // chegada da jogada no servidor (zero = momento do Apply)
+	Lock     bool      // confirma a jogada (LOCK_IN) junto com a escolha da carta
+}
+
 // RoundResult representa o resultado de uma rodada
 type RoundResult struct {
 	P1Card        Card
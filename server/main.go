@@ -1,12 +1,18 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net"
 	"os"
+	"path/filepath"
 	"pingpong/server/game"
 	"pingpong/server/protocol"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -16,11 +22,39 @@ type GameServer struct {
 	cardDB           *game.CardDB
 	packSystem       *game.PackSystem
 	playersOnline    map[string]*protocol.PlayerConn
-	matchmakingQueue []*protocol.PlayerConn
+	matchmakingQueue map[queueKey][]*protocol.PlayerConn // fila FIFO por regras da partida (queueKey)
+	queuedAt         map[string]time.Time                // playerID -> entrada na fila
 	activeMatches    map[string]*game.Match
+	lobbies          *game.LobbyManager     // salas personalizadas abertas
+	browsing         map[string]bool        // jogadores que recebem LOBBY_ADDED/LOBBY_REMOVED (após LIST_LOBBIES)
+	challenges       *game.ChallengeManager // desafios diretos e convites por código pendentes
+	asyncStore       *game.AsyncStore       // estado gravado das partidas assíncronas
+	logins           sync.Map               // playerID -> *protocol.PlayerConn dos jogadores identificados (LOGIN)
+	accounts         *game.AccountStore     // token de cada nome usado no LOGIN
+	campaign         *game.Campaign         // encontros da campanha contra bots
+	campaignProgress *game.CampaignProgress // progresso de cada conta na campanha
+	scenarios        *game.ScenarioSet      // cenários de treino (puzzles)
+	raids            *game.RaidRoster       // chefes que se revezam no modo RAID
+	ladder           *game.Ladder           // rating Elo e temporadas das partidas ranqueadas
+	profiles         *game.ProfileStore     // estatísticas de cada conta (PROFILE)
+	matchSeed        int64                  // seed fixa para todas as partidas (0 = aleatória por partida)
+	replayDir        string                 // diretório dos arquivos de replay
 	mu               sync.RWMutex
 }
 
+// queueKey identifica uma fila de matchmaking: só jogadores com o mesmo modo, controle de tempo,
+// modificador de terreno e tipo de partida (ao vivo ou assíncrona, ranqueada ou casual) se enfrentam
+type queueKey struct {
+	Mode        game.MatchMode
+	TimeControl string
+	Terrain     bool
+	Async       bool
+	Ranked      bool
+}
+
+// playerNamePattern define os nomes aceitos no LOGIN
+var playerNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,20}$`)
+
 // NewGameServer cria um novo servidor do jogo
 func NewGameServer() *GameServer {
 	// Inicializa CardDB
@@ -37,13 +71,124 @@ func NewGameServer() *GameServer {
 	}
 	packSystem := game.NewPackSystem(packConfig, cardDB)
 
-	return &GameServer{
+	// Seed fixa para reproduzir partidas em testes
+	var matchSeed int64
+	if value := getEnv("MATCH_SEED", ""); value != "" {
+		seed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			log.Fatalf("[SERVER] MATCH_SEED inválido: %v", err)
+		}
+		matchSeed = seed
+		log.Printf("[SERVER] Usando seed fixa %d para todas as partidas", matchSeed)
+	}
+
+	// Partidas assíncronas sobrevivem à desconexão dos jogadores e ao reinício do servidor
+	asyncStore, err := game.NewAsyncStore(getEnv("ASYNC_DIR", "async"))
+	if err != nil {
+		log.Fatalf("[SERVER] Erro ao abrir partidas assíncronas: %v", err)
+	}
+
+	// Campanha: encontros contra bots, desbloqueados por conta
+	campaign, err := game.LoadCampaign("campaign.json", cardDB)
+	if err != nil {
+		log.Fatalf("[SERVER] Erro ao carregar campanha: %v", err)
+	}
+	campaignProgress, err := game.LoadCampaignProgress(getEnv("CAMPAIGN_PROGRESS", "campaign_progress.json"))
+	if err != nil {
+		log.Fatalf("[SERVER] Erro ao carregar progresso da campanha: %v", err)
+	}
+
+	// Cenários de treino: situações prontas com objetivo, um arquivo por cenário
+	scenarios, err := game.LoadScenarios(getEnv("SCENARIO_DIR", "scenarios"), cardDB)
+	if err != nil {
+		log.Fatalf("[SERVER] Erro ao carregar cenários: %v", err)
+	}
+	log.Printf("[SERVER] %d cenário(s) de treino carregado(s)", len(scenarios.Scenarios))
+
+	// Raid: chefes do servidor enfrentados por dois jogadores
+	raids, err := game.LoadRaidRoster("raids.json", cardDB)
+	if err != nil {
+		log.Fatalf("[SERVER] Erro ao carregar chefes do raid: %v", err)
+	}
+
+	// Ranking: rating das partidas ranqueadas, gravado a cada resultado, e temporadas
+	seasonDays, err := strconv.Atoi(getEnv("LADDER_SEASON_DAYS", "28"))
+	if err != nil || seasonDays < 1 {
+		log.Fatalf("[SERVER] LADDER_SEASON_DAYS inválido: %q", getEnv("LADDER_SEASON_DAYS", ""))
+	}
+	ladder, err := game.OpenLadder(getEnv("LADDER_DIR", "ladder"), time.Duration(seasonDays)*24*time.Hour, time.Now())
+	if err != nil {
+		log.Fatalf("[SERVER] Erro ao abrir ranking: %v", err)
+	}
+
+	// Contas: cada nome do LOGIN fica ligado ao token emitido no seu primeiro uso
+	accounts, err := game.LoadAccounts(getEnv("ACCOUNT_FILE", "accounts.json"))
+	if err != nil {
+		log.Fatalf("[SERVER] Erro ao carregar contas: %v", err)
+	}
+
+	// Perfis: estatísticas das contas, somadas ao fim de cada partida
+	profiles, err := game.LoadProfiles(getEnv("PROFILE_FILE", "profiles.json"))
+	if err != nil {
+		log.Fatalf("[SERVER] Erro ao carregar perfis: %v", err)
+	}
+
+	gs := &GameServer{
 		cardDB:           cardDB,
 		packSystem:       packSystem,
 		playersOnline:    make(map[string]*protocol.PlayerConn),
-		matchmakingQueue: make([]*protocol.PlayerConn, 0),
+		matchmakingQueue: make(map[queueKey][]*protocol.PlayerConn),
+		queuedAt:         make(map[string]time.Time),
 		activeMatches:    make(map[string]*game.Match),
+		lobbies:          game.NewLobbyManager(0),
+		browsing:         make(map[string]bool),
+		challenges:       game.NewChallengeManager(0),
+		asyncStore:       asyncStore,
+		accounts:         accounts,
+		campaign:         campaign,
+		campaignProgress: campaignProgress,
+		scenarios:        scenarios,
+		raids:            raids,
+		ladder:           ladder,
+		profiles:         profiles,
+		matchSeed:        matchSeed,
+		replayDir:        getEnv("REPLAY_DIR", "replays"),
+	}
+	gs.restoreAsyncMatches()
+
+	return gs
+}
+
+// restoreAsyncMatches retoma as partidas assíncronas gravadas antes do reinício do servidor
+func (gs *GameServer) restoreAsyncMatches() {
+	matches, err := gs.asyncStore.LoadAll(gs.cardDB)
+	if err != nil {
+		log.Fatalf("[SERVER] Erro ao restaurar partidas assíncronas: %v", err)
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	for _, match := range matches {
+		match.Subscribe(asyncObserver{logins: &gs.logins})
+
+		// As estatísticas retomam das rodadas já gravadas no replay
+		stats := gs.profiles.Track(match.Players, gs.cardDB)
+		if entries, err := game.LoadReplay(gs.replayDir, match.ID); err == nil {
+			stats.Feed(entries)
+		}
+		match.Subscribe(stats)
+		if recorder, err := game.ResumeReplayRecorder(gs.replayDir, match.ID); err != nil {
+			log.Printf("[SERVER] Replay da partida %s desativado: %v", match.ID, err)
+		} else {
+			match.Subscribe(recorder)
+		}
+
+		gs.activeMatches[match.ID] = match
+		match.Resume()
+		go gs.monitorMatch(match)
 	}
+	log.Printf("[SERVER] %d partida(s) assíncrona(s) restaurada(s)", len(matches))
 }
 
 // tryCreateMatch verifica a fila de matchmaking e cria partidas
@@ -51,45 +196,213 @@ func (gs *GameServer) tryCreateMatch() {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
 
-	// Precisa de pelo menos 2 jogadores na fila
-	if len(gs.matchmakingQueue) < 2 {
-		return
+	for key, queue := range gs.matchmakingQueue {
+		config := game.ModeConfigs[key.Mode]
+
+		// Precisa do número mínimo de jogadores na fila do modo
+		if len(queue) < config.MinPlayers {
+			continue
+		}
+
+		// Com menos jogadores que o máximo, aguarda FreeForAllWait desde a entrada do primeiro da fila
+		seats := min(len(queue), config.MaxPlayers)
+		waited := time.Since(gs.queuedAt[queue[0].ID])
+		if seats < config.MaxPlayers && waited < time.Duration(game.FreeForAllWait)*time.Millisecond {
+			continue
+		}
+
+		// Pega os primeiros jogadores da fila
+		players := append([]*protocol.PlayerConn{}, queue[:seats]...)
+		gs.matchmakingQueue[key] = queue[seats:]
+		for _, p := range players {
+			delete(gs.queuedAt, p.ID)
+		}
+
+		gs.startMatch(players, key, game.DefaultRules(), nil)
 	}
+}
 
-	// Pega os dois primeiros jogadores da fila
-	p1 := gs.matchmakingQueue[0]
-	p2 := gs.matchmakingQueue[1]
-	gs.matchmakingQueue = gs.matchmakingQueue[2:]
+// startMatch cria uma partida entre os jogadores com as regras da fila ou da sala; sem controle de tempo
+// na chave (salas personalizadas), o prazo base é o das regras (deve ser chamado com o lock adquirido)
+func (gs *GameServer) startMatch(players []*protocol.PlayerConn, key queueKey, rules game.Rules, spectators []*protocol.PlayerConn) {
+	mode := key.Mode
 
 	// Gera ID único para a partida
 	matchID := fmt.Sprintf("match_%d", time.Now().UnixNano())
 
-	// Cria a partida
-	match := game.NewMatch(matchID, p1, p2, gs.cardDB)
-	gs.activeMatches[matchID] = match
+	playerIDs := make([]string, len(players))
+	accounts := []string{}
+	conns := make(connObserver, len(players))
+	for i, p := range players {
+		playerIDs[i] = p.ID
+		conns[p.ID] = p
+		delete(gs.browsing, p.ID)
+		if gs.loggedIn(p) {
+			accounts = append(accounts, p.ID)
+		}
+	}
 
-	log.Printf("[SERVER] Partida criada: %s entre %s e %s", matchID, p1.ID, p2.ID)
+	// No raid, o chefe da semana ocupa o último assento, no time adversário
+	var boss game.Boss
+	if game.ModeConfigs[mode].Boss {
+		boss = gs.raids.Current(time.Now())
+		playerIDs = append(playerIDs, boss.BotID())
+	}
 
-	// Envia MATCH_FOUND para ambos jogadores
-	p1.SendMsg(protocol.ServerMsg{
-		T:          protocol.MATCH_FOUND,
-		MatchID:    matchID,
-		OpponentID: p2.ID,
-	})
+	// Cria a partida e conecta seus eventos aos sockets dos jogadores (nas partidas assíncronas, à
+	// conexão atual de cada jogador identificado, e o estado é gravado em disco)
+	match := game.NewMatch(matchID, playerIDs, gs.cardDB, mode, gs.matchSeed, rules)
+	if timeControl, ok := game.TimeControls[key.TimeControl]; ok {
+		match.SetTimeControl(timeControl)
+	}
+	match.SetTerrain(key.Terrain)
+	if boss.ID != "" {
+		boss.Join(match)
+	}
+	if key.Async {
+		match.Subscribe(asyncObserver{logins: &gs.logins})
+		match.Persist(gs.asyncStore)
+	} else {
+		match.Subscribe(conns)
+	}
+	if key.Ranked {
+		match.Subscribe(ladderObserver{gs: gs, conns: conns})
+	}
+	match.Subscribe(gs.profiles.Track(accounts, gs.cardDB))
+	if len(spectators) > 0 {
+		match.Subscribe(spectatorObserver(spectators))
+	}
+	gs.activeMatches[matchID] = match
 
-	p2.SendMsg(protocol.ServerMsg{
-		T:          protocol.MATCH_FOUND,
-		MatchID:    matchID,
-		OpponentID: p1.ID,
-	})
+	// Grava o replay da partida (sem replay se o diretório não estiver disponível)
+	if recorder, err := game.NewReplayRecorder(gs.replayDir, matchID); err != nil {
+		log.Printf("[SERVER] Replay da partida %s desativado: %v", matchID, err)
+	} else {
+		match.Subscribe(recorder)
+	}
+
+	log.Printf("[SERVER] Partida criada: %s (%s, %s, terreno %t, assíncrona %t, ranqueada %t, seed %d) entre %v", matchID, mode, match.TimeControl.Name, key.Terrain, key.Async, key.Ranked, match.Seed, playerIDs)
 
-	// Envia estado inicial
-	match.BroadcastState()
+	// Envia MATCH_FOUND e o estado inicial (ou o primeiro pacote do draft)
+	match.Start()
 
 	// Monitora o fim da partida
 	go gs.monitorMatch(match)
 }
 
+// connObserver entrega os eventos de uma partida às conexões dos jogadores (playerID -> conexão)
+type connObserver map[string]*protocol.PlayerConn
+
+// OnEvent envia a mensagem do evento ao jogador destinatário
+func (o connObserver) OnEvent(event game.Event) {
+	if conn, ok := o[event.PlayerID]; ok {
+		conn.SendMsg(event.Msg)
+	}
+}
+
+// spectatorObserver entrega aos espectadores de uma sala a visão neutra da partida (sem as mãos)
+type spectatorObserver []*protocol.PlayerConn
+
+// OnEvent converte os registros da partida e os envia a todos os espectadores
+func (o spectatorObserver) OnEvent(event game.Event) {
+	msg, ok := game.SpectatorMsg(event)
+	if !ok {
+		return
+	}
+	for _, conn := range o {
+		conn.SendMsg(msg)
+	}
+}
+
+// asyncObserver entrega os eventos de uma partida assíncrona à conexão atual do jogador, se ele estiver
+// identificado e online; as mensagens levam o ID da partida, pois o jogador pode ter várias em andamento
+type asyncObserver struct {
+	logins *sync.Map
+}
+
+// OnEvent envia a mensagem do evento ao jogador destinatário, se estiver online
+func (o asyncObserver) OnEvent(event game.Event) {
+	if event.Record != nil {
+		return
+	}
+	if conn, online := o.logins.Load(event.PlayerID); online {
+		msg := event.Msg
+		msg.MatchID = event.MatchID
+		conn.(*protocol.PlayerConn).SendMsg(msg)
+	}
+}
+
+// ladderObserver atualiza o rating dos jogadores com o resultado de uma partida ranqueada
+type ladderObserver struct {
+	gs    *GameServer
+	conns connObserver
+}
+
+// OnEvent registra o resultado final (registro END, emitido depois do MATCH_END) e envia RATING_UPDATE
+func (o ladderObserver) OnEvent(event game.Event) {
+	if event.Record == nil || event.Record.T != game.RecordEnd {
+		return
+	}
+
+	changes, err := o.gs.ladder.Record(event.MatchID, event.Record.Reason, event.Record.Results, time.Now())
+	if err != nil {
+		log.Printf("[SERVER] Erro ao registrar partida ranqueada %s: %v", event.MatchID, err)
+		return
+	}
+	for i := range changes {
+		change := changes[i]
+		log.Printf("[SERVER] Rating de %s: %d (%+d, %s)", change.PlayerID, change.Rating, change.Delta, change.Division)
+		if conn, ok := o.conns[change.PlayerID]; ok {
+			conn.SendMsg(protocol.ServerMsg{T: protocol.RATING_UPDATE, MatchID: event.MatchID, Rating: &change})
+		}
+	}
+}
+
+// endSeasonIfDue encerra a temporada do ranking quando vence: credita as moedas da recompensa de
+// cada jogador e envia SEASON_END a quem estiver identificado e online
+func (gs *GameServer) endSeasonIfDue() {
+	results, err := gs.ladder.EndSeasonIfDue(time.Now())
+	if err != nil {
+		log.Printf("[SERVER] Erro ao gravar fim de temporada: %v", err)
+	}
+	for i := range results {
+		result := results[i]
+		if err := gs.campaignProgress.AddCoins(result.PlayerID, result.Coins); err != nil {
+			log.Printf("[SERVER] Erro ao creditar recompensa da temporada de %s: %v", result.PlayerID, err)
+		}
+		if conn, online := gs.logins.Load(result.PlayerID); online {
+			conn.(*protocol.PlayerConn).SendMsg(protocol.ServerMsg{T: protocol.SEASON_END, Season: &result})
+		}
+		log.Printf("[SERVER] Temporada %d: %s terminou em %dº (%s) e recebeu %d moedas", result.Season, result.PlayerID, result.Rank, result.Division, result.Coins)
+	}
+}
+
+// handleLeaderboard envia uma página do ranking da temporada com a posição de quem pediu
+func (gs *GameServer) handleLeaderboard(player *protocol.PlayerConn, page int) {
+	view := gs.ladder.Leaderboard(player.ID, page, time.Now())
+	player.SendMsg(protocol.ServerMsg{T: protocol.LEADERBOARD_PAGE, Leaderboard: &view})
+}
+
+// handleProfile envia as estatísticas de um jogador (sem playerId, as do próprio jogador)
+func (gs *GameServer) handleProfile(player *protocol.PlayerConn, playerID string) {
+	if playerID == "" {
+		playerID = player.ID
+	}
+
+	profile, ok := gs.profiles.View(playerID)
+	if !ok {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.PROFILE_NOT_FOUND,
+			Msg:  fmt.Sprintf("Nenhuma partida registrada para %s", playerID),
+		})
+		return
+	}
+	profile.Rating = gs.ladder.Rating(playerID)
+	profile.Division = string(game.DivisionOf(profile.Rating))
+	player.SendMsg(protocol.ServerMsg{T: protocol.PROFILE_INFO, Profile: &profile})
+}
+
 // monitorMatch monitora uma partida até seu término
 func (gs *GameServer) monitorMatch(match *game.Match) {
 	<-match.Done()
@@ -102,19 +415,65 @@ func (gs *GameServer) monitorMatch(match *game.Match) {
 	log.Printf("[SERVER] Partida %s finalizada e removida", match.ID)
 }
 
-// findPlayerMatch encontra a partida de um jogador
-func (gs *GameServer) findPlayerMatch(playerID string) *game.Match {
+// inMatch verifica se o jogador está em uma partida ao vivo (deve ser chamado com o lock adquirido)
+func (gs *GameServer) inMatch(playerID string) bool {
+	for _, match := range gs.activeMatches {
+		if !match.IsAsync() && match.HasPlayer(playerID) {
+			return true
+		}
+	}
+	return false
+}
+
+// findPlayerMatch encontra a partida informada do jogador ou, sem matchID, a partida ao vivo
+// (ou a única partida assíncrona) em que ele está
+func (gs *GameServer) findPlayerMatch(playerID, matchID string) *game.Match {
 	gs.mu.RLock()
 	defer gs.mu.RUnlock()
 
+	if matchID != "" {
+		if match, ok := gs.activeMatches[matchID]; ok && match.HasPlayer(playerID) {
+			return match
+		}
+		return nil
+	}
+
+	asyncMatches := []*game.Match{}
 	for _, match := range gs.activeMatches {
-		if match.P1.ID == playerID || match.P2.ID == playerID {
+		if !match.HasPlayer(playerID) {
+			continue
+		}
+		if !match.IsAsync() {
 			return match
 		}
+		asyncMatches = append(asyncMatches, match)
+	}
+	if len(asyncMatches) == 1 {
+		return asyncMatches[0]
 	}
 	return nil
 }
 
+// asyncMatchesOf lista as partidas assíncronas em andamento do jogador (deve ser chamado com o lock adquirido)
+func (gs *GameServer) asyncMatchesOf(playerID string) []protocol.AsyncMatchView {
+	views := []protocol.AsyncMatchView{}
+	for _, match := range gs.activeMatches {
+		if match.IsAsync() && match.HasPlayer(playerID) {
+			views = append(views, match.AsyncView(playerID))
+		}
+	}
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].MatchID < views[j].MatchID
+	})
+	return views
+}
+
+// loggedIn verifica se a conexão é a do jogador identificado com seu ID
+func (gs *GameServer) loggedIn(player *protocol.PlayerConn) bool {
+	conn, ok := gs.logins.Load(player.ID)
+	return ok && conn == player
+}
+
 func main() {
 	addr := getEnv("LISTEN_ADDR", ":9000")
 
@@ -136,6 +495,8 @@ func main() {
 
 		for range ticker.C {
 			gameServer.tryCreateMatch()
+			gameServer.expireChallenges()
+			gameServer.endSeasonIfDue()
 		}
 	}()
 
@@ -192,55 +553,99 @@ func (gs *GameServer) cleanup(player *protocol.PlayerConn) {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
 
-	// Remove da lista de jogadores online
+	// Remove da lista de jogadores online, do navegador de salas e dos jogadores identificados
 	delete(gs.playersOnline, player.ID)
+	delete(gs.browsing, player.ID)
+	gs.logins.CompareAndDelete(player.ID, player)
 
-	// Remove da fila de matchmaking
-	for i, p := range gs.matchmakingQueue {
-		if p.ID == player.ID {
-			gs.matchmakingQueue = append(gs.matchmakingQueue[:i], gs.matchmakingQueue[i+1:]...)
-			break
-		}
-	}
-
-	// Notifica oponente se estava em partida
-	for _, match := range gs.activeMatches {
-		if match.P1.ID == player.ID || match.P2.ID == player.ID {
-			var opponent *protocol.PlayerConn
-			if match.P1.ID == player.ID {
-				opponent = match.P2
-			} else {
-				opponent = match.P1
-			}
+	// Remove da fila de matchmaking e da sala personalizada
+	gs.removeFromQueues(player.ID)
+	gs.leaveLobby(player.ID)
 
-			if opponent != nil {
-				opponent.SendMsg(protocol.ServerMsg{
-					T:    protocol.ERROR,
-					Code: "OPPONENT_DISCONNECTED",
-					Msg:  "Seu oponente desconectou",
-				})
-				opponent.SendMsg(protocol.ServerMsg{
-					T:      protocol.MATCH_END,
-					Result: protocol.WIN,
-				})
-			}
+	// Cancela os desafios pendentes do jogador, avisando a outra parte
+	gs.cancelChallenges(player.ID)
 
-			// Remove a partida
-			delete(gs.activeMatches, match.ID)
+	// Retira o jogador da partida ao vivo; os demais são notificados e a partida termina
+	// quando sobra apenas um time (remoção feita por monitorMatch). As partidas assíncronas continuam
+	for _, match := range gs.activeMatches {
+		if !match.IsAsync() && match.HasPlayer(player.ID) {
+			match.RemovePlayer(player.ID)
 			break
 		}
 	}
 }
 
+// cancelChallenges cancela os desafios pendentes do jogador, avisando a outra parte
+// (deve ser chamado com o lock adquirido)
+func (gs *GameServer) cancelChallenges(playerID string) {
+	for _, challenge := range gs.challenges.RemovePlayer(playerID) {
+		gs.notifyChallenge(challenge, playerID, protocol.ServerMsg{
+			T:           protocol.CHALLENGE_DECLINED,
+			ChallengeID: challenge.ID,
+			SenderID:    playerID,
+		})
+	}
+}
+
 // handleMessage processa uma mensagem do cliente
 func (gs *GameServer) handleMessage(player *protocol.PlayerConn, msg *protocol.ClientMsg) {
 	switch msg.T {
+	case protocol.LOGIN:
+		gs.handleLogin(player, msg.PlayerID, msg.Token)
+	case protocol.LIST_MATCHES:
+		gs.handleListMatches(player)
+	case protocol.GET_STATE:
+		gs.handleGetState(player, msg.MatchID)
+	case protocol.CAMPAIGN:
+		gs.handleCampaign(player)
+	case protocol.START_ENCOUNTER:
+		gs.handleStartEncounter(player, msg.EncounterID)
+	case protocol.LIST_SCENARIOS:
+		player.SendMsg(protocol.ServerMsg{T: protocol.SCENARIO_LIST, Scenarios: gs.scenarios.Views()})
+	case protocol.START_SCENARIO:
+		gs.handleStartScenario(player, msg.ScenarioID)
+	case protocol.LEADERBOARD:
+		gs.handleLeaderboard(player, msg.Page)
+	case protocol.PROFILE:
+		gs.handleProfile(player, msg.PlayerID)
 	case protocol.FIND_MATCH:
-		gs.handleFindMatch(player)
+		gs.handleFindMatch(player, msg)
+	case protocol.CREATE_LOBBY:
+		gs.handleCreateLobby(player, msg)
+	case protocol.JOIN_LOBBY:
+		gs.handleJoinLobby(player, msg.Code, msg.Password, msg.Spectate)
+	case protocol.LIST_LOBBIES:
+		gs.handleListLobbies(player)
+	case protocol.CHALLENGE, protocol.CREATE_INVITE:
+		gs.handleChallenge(player, msg)
+	case protocol.ACCEPT_CHALLENGE:
+		gs.handleAcceptChallenge(player, msg.ChallengeID)
+	case protocol.DECLINE_CHALLENGE:
+		gs.handleDeclineChallenge(player, msg.ChallengeID)
+	case protocol.REDEEM_INVITE:
+		gs.handleRedeemInvite(player, msg.Code)
 	case protocol.PLAY:
-		gs.handlePlay(player, msg.CardID)
+		gs.handlePlay(player, msg, false)
+	case protocol.LOCK_IN:
+		if msg.CardID != "" || len(msg.Cards) > 0 {
+			gs.handlePlay(player, msg, true)
+		} else {
+			gs.handleLockIn(player, msg.MatchID)
+		}
+	case protocol.UNPLAY:
+		gs.handleUnplay(player, msg.MatchID)
+	case protocol.DRAFT_PICK:
+		gs.handleDraftPick(player, msg.MatchID, msg.CardID)
+	case protocol.MULLIGAN:
+		gs.handleMulligan(player, msg.MatchID, msg.Cards)
+	case protocol.GET_REPLAY:
+		gs.handleGetReplay(player, msg.MatchID)
+	case protocol.FORFEIT:
+		gs.handleForfeit(player, msg.MatchID)
+	case protocol.OFFER_DRAW, protocol.ACCEPT_DRAW, protocol.DECLINE_DRAW:
+		gs.handleDraw(player, msg.MatchID, msg.T)
 	case protocol.CHAT:
-		gs.handleChat(player, msg.Text)
+		gs.handleChat(player, msg.MatchID, msg.Text)
 	case protocol.PING:
 		gs.handlePing(player, msg.TS)
 	case protocol.OPEN_PACK:
@@ -256,69 +661,1085 @@ func (gs *GameServer) handleMessage(player *protocol.PlayerConn, msg *protocol.C
 	}
 }
 
-// handleFindMatch adiciona jogador à fila de matchmaking
-func (gs *GameServer) handleFindMatch(player *protocol.PlayerConn) {
+// handleLogin identifica a conexão com um nome estável, usado como ID do jogador, e envia suas
+// partidas assíncronas em andamento. Deve vir antes de entrar em filas, salas ou partidas
+func (gs *GameServer) handleLogin(player *protocol.PlayerConn, name, token string) {
+	if !playerNamePattern.MatchString(name) {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.INVALID_MESSAGE,
+			Msg:  "Nome inválido: use de 3 a 20 letras, números, _ ou -",
+		})
+		return
+	}
+
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
 
-	// Verifica se já está na fila
-	for _, p := range gs.matchmakingQueue {
-		if p.ID == player.ID {
+	issued := "" // token emitido no primeiro LOGIN do nome
+	if name != player.ID {
+		_, queued := gs.queuedAt[player.ID]
+		_, inLobby := gs.lobbies.LobbyOf(player.ID)
+		if queued || inLobby || gs.inMatch(player.ID) || gs.loggedIn(player) {
+			player.SendMsg(protocol.ServerMsg{
+				T:    protocol.ERROR,
+				Code: protocol.INVALID_MESSAGE,
+				Msg:  "Identifique-se antes de entrar em filas, salas ou partidas",
+			})
+			return
+		}
+		if _, taken := gs.playersOnline[name]; taken {
+			player.SendMsg(protocol.ServerMsg{
+				T:    protocol.ERROR,
+				Code: protocol.PLAYER_UNAVAILABLE,
+				Msg:  "Nome em uso por outro jogador online",
+			})
+			return
+		}
+
+		// O nome pertence a quem recebeu o token no primeiro LOGIN
+		var err error
+		issued, err = gs.accounts.Claim(name, token)
+		if errors.Is(err, game.ErrInvalidToken) {
+			log.Printf("[SERVER] %s tentou usar o nome %s sem o token correto", player.Conn.RemoteAddr(), name)
+			player.SendMsg(protocol.ServerMsg{
+				T:    protocol.ERROR,
+				Code: protocol.INVALID_TOKEN,
+				Msg:  "Nome registrado: envie o token recebido no primeiro LOGIN",
+			})
+			return
+		}
+		if err != nil {
+			log.Printf("[SERVER] Erro ao registrar o nome %s: %v", name, err)
+			player.SendMsg(protocol.ServerMsg{
+				T:    protocol.ERROR,
+				Code: protocol.INTERNAL,
+				Msg:  "Não foi possível registrar o nome",
+			})
 			return
 		}
+
+		// Troca o ID da conexão pelo nome (desafios pendentes do ID anterior são cancelados)
+		gs.cancelChallenges(player.ID)
+		delete(gs.playersOnline, player.ID)
+		if gs.browsing[player.ID] {
+			delete(gs.browsing, player.ID)
+			gs.browsing[name] = true
+		}
+		player.ID = name
+		gs.playersOnline[name] = player
+		gs.logins.Store(name, player)
+		log.Printf("[SERVER] %s identificou-se como %s (nome novo %t)", player.Conn.RemoteAddr(), name, issued != "")
 	}
 
-	// Adiciona à fila
-	gs.matchmakingQueue = append(gs.matchmakingQueue, player)
-	log.Printf("[SERVER] %s entrou na fila de matchmaking", player.ID)
+	player.SendMsg(protocol.ServerMsg{T: protocol.LOGGED_IN, PlayerID: name, Token: issued})
+	player.SendMsg(protocol.ServerMsg{T: protocol.ASYNC_MATCHES, Matches: gs.asyncMatchesOf(name)})
+}
+
+// handleListMatches envia as partidas assíncronas em andamento do jogador
+func (gs *GameServer) handleListMatches(player *protocol.PlayerConn) {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	player.SendMsg(protocol.ServerMsg{T: protocol.ASYNC_MATCHES, Matches: gs.asyncMatchesOf(player.ID)})
 }
 
-// handlePlay processa uma jogada
-func (gs *GameServer) handlePlay(player *protocol.PlayerConn, cardID string) {
-	match := gs.findPlayerMatch(player.ID)
+// handleGetState reenvia a fase atual de uma partida do jogador (para retomar uma partida assíncrona)
+func (gs *GameServer) handleGetState(player *protocol.PlayerConn, matchID string) {
+	match := gs.findPlayerMatch(player.ID, matchID)
 	if match == nil {
 		player.SendMsg(protocol.ServerMsg{
 			T:    protocol.ERROR,
 			Code: protocol.MATCH_NOT_FOUND,
-			Msg:  "Você não está em uma partida",
+			Msg:  "Você não está nesta partida",
 		})
 		return
 	}
 
-	if err := match.PlayCard(player.ID, cardID); err != nil {
+	if err := match.SyncState(player.ID); err != nil {
 		player.SendMsg(protocol.ServerMsg{
 			T:    protocol.ERROR,
-			Code: protocol.INVALID_CARD,
+			Code: protocol.MATCH_NOT_FOUND,
 			Msg:  err.Error(),
 		})
+	}
+}
+
+// handleCampaign envia os encontros da campanha com o progresso do jogador
+func (gs *GameServer) handleCampaign(player *protocol.PlayerConn) {
+	if !gs.campaignAllowed(player) {
 		return
 	}
 
-	log.Printf("[SERVER] %s jogou carta %s", player.ID, cardID)
+	encounters, coins := gs.campaignProgress.View(gs.campaign, player.ID)
+	player.SendMsg(protocol.ServerMsg{T: protocol.CAMPAIGN_INFO, Encounters: encounters, Coins: coins})
 }
 
-// handleChat processa mensagens de chat
-func (gs *GameServer) handleChat(player *protocol.PlayerConn, text string) {
-	match := gs.findPlayerMatch(player.ID)
-	if match == nil {
+// handleStartEncounter inicia a partida do jogador contra o bot de um encontro desbloqueado
+func (gs *GameServer) handleStartEncounter(player *protocol.PlayerConn, encounterID string) {
+	if !gs.campaignAllowed(player) {
+		return
+	}
+
+	encounter, _, ok := gs.campaign.Find(encounterID)
+	if err := gs.campaignProgress.CheckUnlocked(gs.campaign, player.ID, encounterID); err != nil {
+		code := protocol.ENCOUNTER_LOCKED
+		if !ok {
+			code = protocol.ENCOUNTER_NOT_FOUND
+		}
+		player.SendMsg(protocol.ServerMsg{T: protocol.ERROR, Code: code, Msg: err.Error()})
+		return
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if gs.inMatch(player.ID) {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.PLAYER_UNAVAILABLE,
+			Msg:  "Você já está em uma partida",
+		})
+		return
+	}
+
+	// O encontro substitui filas e salas em que o jogador estava
+	gs.removeFromQueues(player.ID)
+	gs.leaveLobby(player.ID)
+	delete(gs.browsing, player.ID)
+
+	matchID := fmt.Sprintf("match_%d", time.Now().UnixNano())
+	match := encounter.NewMatch(matchID, player.ID, gs.cardDB, gs.matchSeed)
+	match.Subscribe(connObserver{player.ID: player})
+	match.Subscribe(campaignObserver{gs: gs, player: player, encounter: encounter})
+	match.Subscribe(gs.profiles.Track([]string{player.ID}, gs.cardDB))
+	if recorder, err := game.NewReplayRecorder(gs.replayDir, matchID); err != nil {
+		log.Printf("[SERVER] Replay da partida %s desativado: %v", matchID, err)
+	} else {
+		match.Subscribe(recorder)
+	}
+	gs.activeMatches[matchID] = match
+
+	log.Printf("[SERVER] Encontro %s iniciado por %s na partida %s (seed %d)", encounter.ID, player.ID, matchID, match.Seed)
+
+	match.Start()
+	go gs.monitorMatch(match)
+}
+
+// handleStartScenario inicia um cenário de treino do jogador contra o oponente roteirizado; o
+// resultado do objetivo chega em SCENARIO_RESULT ao fim da partida
+func (gs *GameServer) handleStartScenario(player *protocol.PlayerConn, scenarioID string) {
+	scenario, ok := gs.scenarios.Find(scenarioID)
+	if !ok {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.SCENARIO_NOT_FOUND,
+			Msg:  game.ErrScenarioNotFound.Error(),
+		})
+		return
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if gs.inMatch(player.ID) {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.PLAYER_UNAVAILABLE,
+			Msg:  "Você já está em uma partida",
+		})
 		return
 	}
 
-	var opponent *protocol.PlayerConn
-	if match.P1.ID == player.ID {
-		opponent = match.P2
+	// O cenário substitui filas e salas em que o jogador estava
+	gs.removeFromQueues(player.ID)
+	gs.leaveLobby(player.ID)
+	delete(gs.browsing, player.ID)
+
+	matchID := fmt.Sprintf("match_%d", time.Now().UnixNano())
+	match := scenario.NewMatch(matchID, player.ID, gs.cardDB, gs.matchSeed)
+	match.Subscribe(connObserver{player.ID: player})
+	if recorder, err := game.NewReplayRecorder(gs.replayDir, matchID); err != nil {
+		log.Printf("[SERVER] Replay da partida %s desativado: %v", matchID, err)
 	} else {
-		opponent = match.P1
+		match.Subscribe(recorder)
 	}
+	gs.activeMatches[matchID] = match
+
+	log.Printf("[SERVER] Cenário %s iniciado por %s na partida %s (seed %d)", scenario.ID, player.ID, matchID, match.Seed)
+
+	match.Start()
+	go gs.monitorMatch(match)
+}
+
+// campaignAllowed verifica se o jogador está identificado (LOGIN), pois o progresso é guardado por conta
+func (gs *GameServer) campaignAllowed(player *protocol.PlayerConn) bool {
+	if gs.loggedIn(player) {
+		return true
+	}
+	player.SendMsg(protocol.ServerMsg{
+		T:    protocol.ERROR,
+		Code: protocol.LOGIN_REQUIRED,
+		Msg:  "A campanha exige LOGIN",
+	})
+	return false
+}
+
+// campaignObserver concede a recompensa quando o jogador vence um encontro pela primeira vez
+type campaignObserver struct {
+	gs        *GameServer
+	player    *protocol.PlayerConn
+	encounter game.Encounter
+}
+
+// OnEvent trata o MATCH_END do jogador (chamado depois de o resultado ser enviado a ele)
+func (o campaignObserver) OnEvent(event game.Event) {
+	if event.PlayerID == o.player.ID && event.Msg.T == protocol.MATCH_END && event.Msg.Result == protocol.WIN {
+		o.gs.rewardEncounter(o.player, o.encounter)
+	}
+}
+
+// rewardEncounter registra a vitória no encontro e, se for a primeira, abre os pacotes da
+// recompensa (enquanto houver estoque) e envia CAMPAIGN_REWARD e a campanha atualizada
+func (gs *GameServer) rewardEncounter(player *protocol.PlayerConn, encounter game.Encounter) {
+	first, err := gs.campaignProgress.Clear(player.ID, encounter)
+	if err != nil {
+		log.Printf("[SERVER] Erro ao gravar progresso da campanha de %s: %v", player.ID, err)
+	}
+	if !first {
+		return
+	}
+
+	cards := []string{}
+	for i := 0; i < encounter.Reward.Packs; i++ {
+		pack, err := gs.packSystem.OpenPack(player.ID)
+		if err != nil {
+			log.Printf("[SERVER] Recompensa de %s sem pacote: %v", player.ID, err)
+			break
+		}
+		cards = append(cards, pack...)
+	}
+	log.Printf("[SERVER] %s venceu o encontro %s pela primeira vez: %d moedas, cartas %v", player.ID, encounter.ID, encounter.Reward.Coins, cards)
+
+	player.SendMsg(protocol.ServerMsg{
+		T:           protocol.CAMPAIGN_REWARD,
+		EncounterID: encounter.ID,
+		Coins:       encounter.Reward.Coins,
+		Cards:       cards,
+	})
+	encounters, coins := gs.campaignProgress.View(gs.campaign, player.ID)
+	player.SendMsg(protocol.ServerMsg{T: protocol.CAMPAIGN_INFO, Encounters: encounters, Coins: coins})
+}
+
+// asyncAllowed verifica se o jogador pode iniciar uma partida assíncrona no modo, avisando o motivo
+// da recusa: é preciso estar identificado (LOGIN) e o modo deve ser 1v1 sem draft
+func (gs *GameServer) asyncAllowed(player *protocol.PlayerConn, mode game.MatchMode) bool {
+	if !gs.loggedIn(player) {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.LOGIN_REQUIRED,
+			Msg:  "Partidas assíncronas exigem LOGIN",
+		})
+		return false
+	}
+	if !game.AsyncModeAllowed(mode) {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.INVALID_MESSAGE,
+			Msg:  "Partidas assíncronas aceitam apenas modos 1v1 sem draft",
+		})
+		return false
+	}
+	return true
+}
+
+// rankedAllowed verifica se o jogador pode entrar na fila ranqueada do modo, avisando o motivo da
+// recusa: é preciso estar identificado (LOGIN), pois o rating é guardado por conta, e a partida deve
+// ser 1v1 ao vivo
+func (gs *GameServer) rankedAllowed(player *protocol.PlayerConn, mode game.MatchMode, async bool) bool {
+	if !gs.loggedIn(player) {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.LOGIN_REQUIRED,
+			Msg:  "Partidas ranqueadas exigem LOGIN",
+		})
+		return false
+	}
+	if async || !game.RankedModeAllowed(mode) {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.INVALID_MESSAGE,
+			Msg:  "Partidas ranqueadas aceitam apenas modos 1v1 ao vivo",
+		})
+		return false
+	}
+	return true
+}
+
+// handleFindMatch adiciona jogador à fila de matchmaking do modo, controle de tempo e terreno escolhidos;
+// partidas assíncronas usam sempre o controle de tempo por correspondência
+func (gs *GameServer) handleFindMatch(player *protocol.PlayerConn, msg *protocol.ClientMsg) {
+	mode, ok := game.ParseMatchMode(msg.Mode)
+	if !ok {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.INVALID_MESSAGE,
+			Msg:  "Modo de jogo desconhecido",
+		})
+		return
+	}
+
+	if msg.Ranked && !gs.rankedAllowed(player, mode, msg.Async) {
+		return
+	}
+
+	timeControlName := msg.TimeControl
+	if msg.Async {
+		if !gs.asyncAllowed(player, mode) {
+			return
+		}
+		timeControlName = game.TimeCorrespondence
+	}
+	timeControl, ok := game.ParseTimeControl(timeControlName)
+	if !ok {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.INVALID_MESSAGE,
+			Msg:  "Controle de tempo desconhecido",
+		})
+		return
+	}
+	key := queueKey{Mode: mode, TimeControl: timeControl.Name, Terrain: msg.Terrain, Async: msg.Async, Ranked: msg.Ranked}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	// Verifica se já está nesta fila
+	for _, p := range gs.matchmakingQueue[key] {
+		if p.ID == player.ID {
+			return
+		}
+	}
+
+	// Troca de fila se estava aguardando outro modo, controle de tempo ou terreno (ou sai da sala)
+	gs.removeFromQueues(player.ID)
+	gs.leaveLobby(player.ID)
+
+	// Adiciona à fila
+	gs.matchmakingQueue[key] = append(gs.matchmakingQueue[key], player)
+	gs.queuedAt[player.ID] = time.Now()
+	log.Printf("[SERVER] %s entrou na fila de matchmaking (%s, %s, terreno %t, assíncrona %t, ranqueada %t)", player.ID, mode, timeControl.Name, msg.Terrain, msg.Async, msg.Ranked)
+}
+
+// removeFromQueues remove o jogador de todas as filas (deve ser chamado com o lock adquirido)
+func (gs *GameServer) removeFromQueues(playerID string) {
+	for key, queue := range gs.matchmakingQueue {
+		for i, p := range queue {
+			if p.ID == playerID {
+				gs.matchmakingQueue[key] = append(queue[:i], queue[i+1:]...)
+				delete(gs.queuedAt, playerID)
+				break
+			}
+		}
+	}
+}
+
+// handleCreateLobby abre uma sala personalizada com as regras recebidas e envia o código de convite
+func (gs *GameServer) handleCreateLobby(player *protocol.PlayerConn, msg *protocol.ClientMsg) {
+	mode, ok := game.ParseMatchMode(msg.Mode)
+	if !ok {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.INVALID_MESSAGE,
+			Msg:  "Modo de jogo desconhecido",
+		})
+		return
+	}
+
+	rules, err := game.ParseRules(msg.Rules, gs.cardDB)
+	if err == nil && game.ModeConfigs[mode].Boss && rules.DeckPolicy == game.DeckDraft {
+		err = errors.New("o raid não aceita deck de draft: o chefe tem deck próprio")
+	}
+	if err != nil {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.INVALID_MESSAGE,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if gs.inMatch(player.ID) {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.PLAYER_UNAVAILABLE,
+			Msg:  "Você já está em uma partida",
+		})
+		return
+	}
+
+	gs.removeFromQueues(player.ID)
+	gs.leaveLobby(player.ID)
+
+	lobby := gs.lobbies.Create(game.Lobby{
+		HostID:     player.ID,
+		Mode:       mode,
+		Rules:      rules,
+		Password:   msg.Password,
+		Spectators: msg.Spectators,
+	})
+	gs.broadcastLobby(lobby)
+	log.Printf("[SERVER] %s criou a sala %s (%s, regras %+v, senha %t)", player.ID, lobby.Code, mode, rules, lobby.Password != "")
+}
+
+// handleJoinLobby coloca o jogador na sala do código de convite (com a senha, se houver), como jogador
+// ou como espectador; com a sala cheia, a partida começa
+func (gs *GameServer) handleJoinLobby(player *protocol.PlayerConn, code, password string, spectate bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if gs.inMatch(player.ID) {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.PLAYER_UNAVAILABLE,
+			Msg:  "Você já está em uma partida",
+		})
+		return
+	}
+
+	// Sai da sala anterior (o Join e o Spectate mantêm quem já está na sala do código)
+	if current, ok := gs.lobbies.LobbyOf(player.ID); ok && current.Code != code {
+		gs.leaveLobby(player.ID)
+	}
+
+	// Quem entrou em outra partida enquanto esperava sai da sala antes que ela encha e a partida comece
+	if waiting, ok := gs.lobbies.Get(code); ok {
+		for _, playerID := range waiting.Players {
+			if gs.inMatch(playerID) {
+				gs.leaveLobby(playerID)
+			}
+		}
+	}
+
+	join := gs.lobbies.Join
+	if spectate {
+		join = gs.lobbies.Spectate
+	}
+	lobby, err := join(code, player.ID, password)
+	if err != nil {
+		errCode := protocol.LOBBY_NOT_FOUND
+		switch {
+		case errors.Is(err, game.ErrWrongPassword):
+			errCode = protocol.WRONG_PASSWORD
+		case errors.Is(err, game.ErrNoSpectators):
+			errCode = protocol.NO_SPECTATORS
+		}
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: errCode,
+			Msg:  err.Error(),
+		})
+		return
+	}
+	gs.removeFromQueues(player.ID)
+	if spectate {
+		log.Printf("[SERVER] %s assiste à sala %s (%d espectadores)", player.ID, lobby.Code, len(lobby.Watchers))
+	} else {
+		log.Printf("[SERVER] %s entrou na sala %s (%d/%d)", player.ID, lobby.Code, len(lobby.Players), game.ModeConfigs[lobby.Mode].MaxPlayers)
+	}
+
+	gs.broadcastLobby(lobby)
+	if !lobby.Full() {
+		return
+	}
+
+	// Sala cheia: a partida começa com as regras da sala (quem desconecta sai da sala na limpeza);
+	// os espectadores ainda online passam a acompanhar a partida
+	players := make([]*protocol.PlayerConn, 0, len(lobby.Players))
+	for _, playerID := range lobby.Players {
+		players = append(players, gs.playersOnline[playerID])
+	}
+	spectators := []*protocol.PlayerConn{}
+	for _, playerID := range lobby.Watchers {
+		if conn, online := gs.playersOnline[playerID]; online {
+			spectators = append(spectators, conn)
+		}
+	}
+	gs.startMatch(players, queueKey{Mode: lobby.Mode}, lobby.Rules, spectators)
+}
+
+// handleListLobbies envia as salas abertas e passa a enviar ao jogador as salas abertas e fechadas
+// até ele entrar em uma partida
+func (gs *GameServer) handleListLobbies(player *protocol.PlayerConn) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	views := []protocol.LobbyView{}
+	for _, lobby := range gs.lobbies.List() {
+		views = append(views, lobby.View())
+	}
+	gs.browsing[player.ID] = true
+
+	player.SendMsg(protocol.ServerMsg{
+		T:       protocol.LOBBY_LIST,
+		Lobbies: views,
+	})
+}
+
+// leaveLobby retira o jogador de sua sala e avisa quem ficou (deve ser chamado com o lock adquirido)
+func (gs *GameServer) leaveLobby(playerID string) {
+	if lobby, ok := gs.lobbies.Leave(playerID); ok {
+		gs.broadcastLobby(lobby)
+	}
+}
+
+// broadcastLobby envia o estado da sala aos seus jogadores e espectadores e a atualização do navegador
+// aos jogadores que o acompanham: sala vazia ou cheia sai da lista, e os espectadores de uma sala que
+// ficou sem jogadores são avisados de que ela fechou (deve ser chamado com o lock adquirido)
+func (gs *GameServer) broadcastLobby(lobby game.Lobby) {
+	update := protocol.ServerMsg{T: protocol.LOBBY_REMOVED, LobbyCode: lobby.Code}
+	if len(lobby.Players) > 0 && !lobby.Full() {
+		view := lobby.View()
+		update = protocol.ServerMsg{T: protocol.LOBBY_ADDED, Lobby: &view}
+	}
+	for playerID := range gs.browsing {
+		if conn, online := gs.playersOnline[playerID]; online {
+			conn.SendMsg(update)
+		}
+	}
+
+	if len(lobby.Players) == 0 {
+		for _, playerID := range lobby.Watchers {
+			if conn, online := gs.playersOnline[playerID]; online && !gs.browsing[playerID] {
+				conn.SendMsg(update)
+			}
+		}
+		return
+	}
+
+	for _, playerID := range append(append([]string{}, lobby.Players...), lobby.Watchers...) {
+		if conn, online := gs.playersOnline[playerID]; online {
+			conn.SendMsg(protocol.ServerMsg{
+				T:            protocol.LOBBY,
+				LobbyCode:    lobby.Code,
+				HostID:       lobby.HostID,
+				Mode:         string(lobby.Mode),
+				Rules:        lobby.Rules.View(),
+				PlayerIDs:    lobby.Players,
+				SpectatorIDs: lobby.Watchers,
+			})
+		}
+	}
+}
+
+// handleChallenge desafia diretamente um jogador (CHALLENGE) ou gera um código de convite de uso
+// único (CREATE_INVITE) para uma partida 1v1 (ou um raid em dupla) fora da fila de matchmaking
+func (gs *GameServer) handleChallenge(player *protocol.PlayerConn, msg *protocol.ClientMsg) {
+	mode, ok := game.ParseMatchMode(msg.Mode)
+	if !ok || game.ModeConfigs[mode].MaxPlayers != 2 {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.INVALID_MESSAGE,
+			Msg:  "Desafios aceitam apenas modos 1v1 e RAID",
+		})
+		return
+	}
+
+	timeControlName := msg.TimeControl
+	if msg.Async {
+		if !gs.asyncAllowed(player, mode) {
+			return
+		}
+		timeControlName = game.TimeCorrespondence
+	}
+	timeControl, ok := game.ParseTimeControl(timeControlName)
+	if !ok {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.INVALID_MESSAGE,
+			Msg:  "Controle de tempo desconhecido",
+		})
+		return
+	}
+
+	challenge := game.Challenge{
+		ChallengerID: player.ID,
+		Mode:         mode,
+		TimeControl:  timeControl.Name,
+		Terrain:      msg.Terrain,
+		Async:        msg.Async,
+	}
+	if msg.T == protocol.CHALLENGE {
+		challenge.TargetID = msg.PlayerID
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	// O desafiado precisa estar online e fora de partida ao vivo (identificado, no desafio assíncrono)
+	target, online := gs.playersOnline[challenge.TargetID]
+	available := online && !gs.inMatch(challenge.TargetID)
+	if challenge.Async {
+		available = online && gs.loggedIn(target)
+	}
+	if msg.T == protocol.CHALLENGE && !available {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.PLAYER_UNAVAILABLE,
+			Msg:  "Jogador offline ou em partida",
+		})
+		return
+	}
+
+	challenge, err := gs.challenges.Send(challenge)
+	if err != nil {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.PLAYER_UNAVAILABLE,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	deadlineMs := time.Until(challenge.ExpiresAt).Milliseconds()
+	player.SendMsg(protocol.ServerMsg{
+		T:           protocol.CHALLENGE_SENT,
+		ChallengeID: challenge.ID,
+		OpponentID:  challenge.TargetID,
+		InviteCode:  challenge.Code,
+		Mode:        string(challenge.Mode),
+		TimeControl: challenge.TimeControl,
+		DeadlineMs:  deadlineMs,
+	})
+	if challenge.TargetID != "" {
+		gs.playersOnline[challenge.TargetID].SendMsg(protocol.ServerMsg{
+			T:           protocol.CHALLENGE_RECEIVED,
+			ChallengeID: challenge.ID,
+			SenderID:    player.ID,
+			Mode:        string(challenge.Mode),
+			TimeControl: challenge.TimeControl,
+			DeadlineMs:  deadlineMs,
+		})
+	}
+	log.Printf("[SERVER] %s criou o desafio %s (alvo %q, código %q)", player.ID, challenge.ID, challenge.TargetID, challenge.Code)
+}
+
+// handleAcceptChallenge inicia a partida do desafio direto aceito pelo desafiado
+func (gs *GameServer) handleAcceptChallenge(player *protocol.PlayerConn, challengeID string) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	challenge, err := gs.challenges.Accept(challengeID, player.ID)
+	if err != nil {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.CHALLENGE_NOT_FOUND,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	gs.startChallenge(challenge, player)
+}
+
+// handleDeclineChallenge recusa (ou cancela, para o desafiante) um desafio pendente
+func (gs *GameServer) handleDeclineChallenge(player *protocol.PlayerConn, challengeID string) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	challenge, err := gs.challenges.Decline(challengeID, player.ID)
+	if err != nil {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.CHALLENGE_NOT_FOUND,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	gs.notifyChallenge(challenge, player.ID, protocol.ServerMsg{
+		T:           protocol.CHALLENGE_DECLINED,
+		ChallengeID: challenge.ID,
+		SenderID:    player.ID,
+	})
+	log.Printf("[SERVER] %s recusou o desafio %s", player.ID, challenge.ID)
+}
+
+// handleRedeemInvite resgata o código de convite e inicia a partida contra quem o criou
+func (gs *GameServer) handleRedeemInvite(player *protocol.PlayerConn, code string) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	challenge, err := gs.challenges.Redeem(strings.ToUpper(strings.TrimSpace(code)), player.ID)
+	if err != nil {
+		errCode := protocol.CHALLENGE_NOT_FOUND
+		if errors.Is(err, game.ErrSelfChallenge) {
+			errCode = protocol.PLAYER_UNAVAILABLE
+		}
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: errCode,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	gs.startChallenge(challenge, player)
+}
+
+// startChallenge cria a partida entre o desafiante e quem aceitou, sem passar pela fila de matchmaking.
+// Se a partida não puder começar com o desafiante online, o desafio (ou o código de convite) é
+// devolvido e pode ser aceito de novo até o prazo original (deve ser chamado com o lock adquirido)
+func (gs *GameServer) startChallenge(challenge game.Challenge, accepter *protocol.PlayerConn) {
+	challenger, online := gs.playersOnline[challenge.ChallengerID]
+	if !online {
+		accepter.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.PLAYER_UNAVAILABLE,
+			Msg:  "Jogador offline ou em partida",
+		})
+		return
+	}
+	if challenge.Async && !gs.loggedIn(accepter) {
+		gs.challenges.Restore(challenge)
+		accepter.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.LOGIN_REQUIRED,
+			Msg:  "Partidas assíncronas exigem LOGIN",
+		})
+		return
+	}
+	if !challenge.Async && (gs.inMatch(challenger.ID) || gs.inMatch(accepter.ID)) {
+		gs.challenges.Restore(challenge)
+		accepter.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.PLAYER_UNAVAILABLE,
+			Msg:  "Jogador offline ou em partida",
+		})
+		return
+	}
+
+	// A partida ao vivo tira os dois das filas e salas; a assíncrona corre em paralelo
+	if !challenge.Async {
+		for _, playerID := range []string{challenger.ID, accepter.ID} {
+			gs.removeFromQueues(playerID)
+			gs.leaveLobby(playerID)
+		}
+	}
+
+	log.Printf("[SERVER] Desafio %s aceito por %s", challenge.ID, accepter.ID)
+	key := queueKey{Mode: challenge.Mode, TimeControl: challenge.TimeControl, Terrain: challenge.Terrain, Async: challenge.Async}
+	gs.startMatch([]*protocol.PlayerConn{challenger, accepter}, key, game.DefaultRules(), nil)
+}
+
+// expireChallenges retira os desafios cujo prazo acabou e avisa os envolvidos
+func (gs *GameServer) expireChallenges() {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	for _, challenge := range gs.challenges.Expire(time.Now()) {
+		msg := protocol.ServerMsg{T: protocol.CHALLENGE_EXPIRED, ChallengeID: challenge.ID, InviteCode: challenge.Code}
+		gs.notifyChallenge(challenge, "", msg)
+		log.Printf("[SERVER] Desafio %s expirou", challenge.ID)
+	}
+}
+
+// notifyChallenge envia a mensagem aos envolvidos no desafio, exceto a exceptID
+// (deve ser chamado com o lock adquirido)
+func (gs *GameServer) notifyChallenge(challenge game.Challenge, exceptID string, msg protocol.ServerMsg) {
+	for _, playerID := range []string{challenge.ChallengerID, challenge.TargetID} {
+		if playerID == "" || playerID == exceptID {
+			continue
+		}
+		if conn, online := gs.playersOnline[playerID]; online {
+			conn.SendMsg(msg)
+		}
+	}
+}
+
+// handlePlay processa a escolha da ação e da carta da rodada (confirmada de imediato com lock)
+func (gs *GameServer) handlePlay(player *protocol.PlayerConn, msg *protocol.ClientMsg, lock bool) {
+	match := gs.findPlayerMatch(player.ID, msg.MatchID)
+	if match == nil {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.MATCH_NOT_FOUND,
+			Msg:  "Você não está em uma partida",
+		})
+		return
+	}
+
+	play := game.Play{
+		PlayerID: player.ID,
+		CardID:   msg.CardID,
+		Action:   game.Action(msg.Action),
+		Cards:    msg.Cards,
+		Target:   msg.Target,
+		At:       time.Now(),
+		Lock:     lock,
+	}
+	if err := match.Apply(play); err != nil {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: playErrorCode(err),
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	log.Printf("[SERVER] %s escolheu carta %s", player.ID, play.CardID)
+}
+
+// handleLockIn confirma a carta escolhida pelo jogador
+func (gs *GameServer) handleLockIn(player *protocol.PlayerConn, matchID string) {
+	match := gs.findPlayerMatch(player.ID, matchID)
+	if match == nil {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.MATCH_NOT_FOUND,
+			Msg:  "Você não está em uma partida",
+		})
+		return
+	}
+
+	if err := match.LockIn(player.ID, time.Now()); err != nil {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: playErrorCode(err),
+			Msg:  err.Error(),
+		})
+	}
+}
+
+// handleUnplay retira a carta escolhida e ainda não confirmada
+func (gs *GameServer) handleUnplay(player *protocol.PlayerConn, matchID string) {
+	match := gs.findPlayerMatch(player.ID, matchID)
+	if match == nil {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.MATCH_NOT_FOUND,
+			Msg:  "Você não está em uma partida",
+		})
+		return
+	}
+
+	if err := match.Unplay(player.ID); err != nil {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: playErrorCode(err),
+			Msg:  err.Error(),
+		})
+	}
+}
+
+// playErrorCode converte o erro de uma jogada no código de erro do protocolo
+func playErrorCode(err error) string {
+	switch {
+	case errors.Is(err, game.ErrNotEnoughEnergy):
+		return protocol.NOT_ENOUGH_ENERGY
+	case errors.Is(err, game.ErrNotYourTurn), errors.Is(err, game.ErrAlreadyLocked):
+		return protocol.NOT_YOUR_TURN
+	case errors.Is(err, game.ErrInvalidTarget):
+		return protocol.INVALID_TARGET
+	case errors.Is(err, game.ErrInvalidAction), errors.Is(err, game.ErrActionUnavailable):
+		return protocol.INVALID_MESSAGE
+	}
+	return protocol.INVALID_CARD
+}
+
+// handleDraftPick processa a escolha de uma carta no draft
+func (gs *GameServer) handleDraftPick(player *protocol.PlayerConn, matchID, cardID string) {
+	match := gs.findPlayerMatch(player.ID, matchID)
+	if match == nil {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.MATCH_NOT_FOUND,
+			Msg:  "Você não está em uma partida",
+		})
+		return
+	}
+
+	if err := match.DraftPick(player.ID, cardID); err != nil {
+		code := protocol.INVALID_CARD
+		if errors.Is(err, game.ErrNotYourTurn) || errors.Is(err, game.ErrAlreadyPicked) {
+			code = protocol.NOT_YOUR_TURN
+		}
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: code,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	log.Printf("[SERVER] %s escolheu carta %s no draft", player.ID, cardID)
+}
+
+// handleMulligan processa as cartas devolvidas pelo jogador no mulligan (nenhuma = mantém a mão)
+func (gs *GameServer) handleMulligan(player *protocol.PlayerConn, matchID string, cardIDs []string) {
+	match := gs.findPlayerMatch(player.ID, matchID)
+	if match == nil {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.MATCH_NOT_FOUND,
+			Msg:  "Você não está em uma partida",
+		})
+		return
+	}
+
+	if err := match.Mulligan(player.ID, cardIDs); err != nil {
+		code := protocol.INVALID_CARD
+		if errors.Is(err, game.ErrNotInMulligan) || errors.Is(err, game.ErrAlreadyMulliganed) {
+			code = protocol.NOT_YOUR_TURN
+		}
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: code,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	log.Printf("[SERVER] %s devolveu %d carta(s) no mulligan", player.ID, len(cardIDs))
+}
+
+// handleForfeit processa a desistência do jogador na partida atual
+func (gs *GameServer) handleForfeit(player *protocol.PlayerConn, matchID string) {
+	match := gs.findPlayerMatch(player.ID, matchID)
+	if match == nil {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.MATCH_NOT_FOUND,
+			Msg:  "Você não está em uma partida",
+		})
+		return
+	}
+
+	if err := match.Forfeit(player.ID); err != nil {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.MATCH_NOT_FOUND,
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	log.Printf("[SERVER] %s desistiu da partida %s", player.ID, match.ID)
+}
+
+// handleDraw processa oferta, aceite ou recusa de empate
+func (gs *GameServer) handleDraw(player *protocol.PlayerConn, matchID, msgType string) {
+	match := gs.findPlayerMatch(player.ID, matchID)
+	if match == nil {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.MATCH_NOT_FOUND,
+			Msg:  "Você não está em uma partida",
+		})
+		return
+	}
+
+	var err error
+	switch msgType {
+	case protocol.OFFER_DRAW:
+		err = match.OfferDraw(player.ID)
+	case protocol.ACCEPT_DRAW:
+		err = match.AcceptDraw(player.ID)
+	case protocol.DECLINE_DRAW:
+		err = match.DeclineDraw(player.ID)
+	}
+	if err != nil {
+		code := protocol.MATCH_NOT_FOUND
+		if errors.Is(err, game.ErrNoDrawOffer) {
+			code = protocol.NO_DRAW_OFFER
+		}
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: code,
+			Msg:  err.Error(),
+		})
+	}
+}
+
+// handleGetReplay envia o replay de uma partida finalizada
+func (gs *GameServer) handleGetReplay(player *protocol.PlayerConn, matchID string) {
+	notFound := func(msg string) {
+		player.SendMsg(protocol.ServerMsg{
+			T:    protocol.ERROR,
+			Code: protocol.REPLAY_NOT_FOUND,
+			Msg:  msg,
+		})
+	}
+
+	// O ID vira nome de arquivo: recusa caminhos
+	if matchID == "" || matchID == ".." || filepath.Base(matchID) != matchID {
+		notFound("ID de partida inválido")
+		return
+	}
+
+	gs.mu.RLock()
+	_, inProgress := gs.activeMatches[matchID]
+	gs.mu.RUnlock()
+
+	// O replay revela as mãos de todos os jogadores: só fica disponível após o fim da partida
+	if inProgress {
+		notFound("Replay disponível apenas após o fim da partida")
+		return
+	}
+
+	entries, err := game.LoadReplay(gs.replayDir, matchID)
+	if err != nil {
+		log.Printf("[SERVER] Replay %s indisponível para %s: %v", matchID, player.ID, err)
+		notFound("Replay não encontrado")
+		return
+	}
+
+	// O replay revela as mãos de todos e a seed: só é servido a quem jogou a partida
+	if !game.ReplayHasPlayer(entries, player.ID) {
+		log.Printf("[SERVER] Replay %s recusado para %s: não participou da partida", matchID, player.ID)
+		notFound("Replay disponível apenas para os jogadores da partida")
+		return
+	}
+
+	player.SendMsg(protocol.ServerMsg{
+		T:       protocol.REPLAY,
+		MatchID: matchID,
+		Replay:  entries,
+	})
+}
+
+// handleChat processa mensagens de chat
+func (gs *GameServer) handleChat(player *protocol.PlayerConn, matchID, text string) {
+	match := gs.findPlayerMatch(player.ID, matchID)
+	if match == nil {
+		return
+	}
+
+	// Envia mensagem de chat para os demais jogadores da partida
+	for _, otherID := range match.Players {
+		if otherID == player.ID || !match.HasPlayer(otherID) {
+			continue
+		}
+
+		gs.mu.RLock()
+		other, online := gs.playersOnline[otherID]
+		gs.mu.RUnlock()
+		if !online {
+			continue
+		}
 
-	if opponent != nil {
-		// Envia mensagem de chat para o oponente
-		opponent.SendMsg(protocol.ServerMsg{
+		other.SendMsg(protocol.ServerMsg{
 			T:        protocol.CHAT_MESSAGE,
+			MatchID:  match.ID,
 			SenderID: player.ID,
 			Text:     text,
 		})
-		log.Printf("[SERVER] Chat de %s para %s: %s", player.ID, opponent.ID, text)
+		log.Printf("[SERVER] Chat de %s para %s: %s", player.ID, otherID, text)
 	}
 }
 
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"pingpong/server/game"
+	"pingpong/server/protocol"
+)
+
+// testConn é uma conexão falsa que guarda tudo o que o servidor envia ao jogador
+type testConn struct {
+	net.Conn
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (c *testConn) Write(data []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.Write(data)
+}
+
+func (c *testConn) RemoteAddr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 9000}
+}
+
+func (c *testConn) Close() error {
+	return nil
+}
+
+// messages decodifica as mensagens recebidas até agora
+func (c *testConn) messages(t *testing.T) []protocol.ServerMsg {
+	t.Helper()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	msgs := []protocol.ServerMsg{}
+	decoder := json.NewDecoder(bytes.NewReader(c.buf.Bytes()))
+	for decoder.More() {
+		var msg protocol.ServerMsg
+		if err := decoder.Decode(&msg); err != nil {
+			t.Fatalf("Mensagem inválida enviada ao jogador: %v", err)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// last retorna a última mensagem do tipo recebida (nil se nenhuma)
+func (c *testConn) last(t *testing.T, msgType string) *protocol.ServerMsg {
+	t.Helper()
+
+	msgs := c.messages(t)
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].T == msgType {
+			return &msgs[i]
+		}
+	}
+	return nil
+}
+
+// newTestServer cria o servidor com os arquivos de estado (contas, partidas assíncronas, progresso,
+// ranking, perfis e replays) em um diretório temporário
+func newTestServer(t *testing.T) *GameServer {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("ACCOUNT_FILE", filepath.Join(dir, "accounts.json"))
+	t.Setenv("ASYNC_DIR", filepath.Join(dir, "async"))
+	t.Setenv("CAMPAIGN_PROGRESS", filepath.Join(dir, "campaign_progress.json"))
+	t.Setenv("LADDER_DIR", filepath.Join(dir, "ladder"))
+	t.Setenv("PROFILE_FILE", filepath.Join(dir, "profiles.json"))
+	t.Setenv("REPLAY_DIR", filepath.Join(dir, "replays"))
+	t.Setenv("MATCH_SEED", "1")
+	return NewGameServer()
+}
+
+// connect registra uma conexão de jogador como handleConn faria
+func connect(gs *GameServer, id string) (*protocol.PlayerConn, *testConn) {
+	conn := &testConn{}
+	player := protocol.NewPlayerConn(id, conn)
+
+	gs.mu.Lock()
+	gs.playersOnline[id] = player
+	gs.mu.Unlock()
+	return player, conn
+}
+
+func TestInviteSurvivesFailedStart(t *testing.T) {
+	gs := newTestServer(t)
+	ana, anaConn := connect(gs, "ana")
+	bia, biaConn := connect(gs, "bia")
+
+	gs.handleMessage(ana, &protocol.ClientMsg{T: protocol.CREATE_INVITE})
+	sent := anaConn.last(t, protocol.CHALLENGE_SENT)
+	if sent == nil || sent.InviteCode == "" {
+		t.Fatalf("CHALLENGE_SENT com código esperado, obtido %+v", sent)
+	}
+
+	// O criador está em outra partida: o resgate falha e o código continua valendo
+	busy := game.NewMatch("m_busy", []string{"ana", "caio"}, gs.cardDB, game.ModeSimultaneous, 1, game.DefaultRules())
+	gs.mu.Lock()
+	gs.activeMatches[busy.ID] = busy
+	gs.mu.Unlock()
+
+	gs.handleMessage(bia, &protocol.ClientMsg{T: protocol.REDEEM_INVITE, Code: sent.InviteCode})
+	if err := biaConn.last(t, protocol.ERROR); err == nil || err.Code != protocol.PLAYER_UNAVAILABLE {
+		t.Fatalf("PLAYER_UNAVAILABLE esperado, obtido %+v", err)
+	}
+
+	gs.mu.Lock()
+	delete(gs.activeMatches, busy.ID)
+	gs.mu.Unlock()
+
+	gs.handleMessage(bia, &protocol.ClientMsg{T: protocol.REDEEM_INVITE, Code: sent.InviteCode})
+	if found := biaConn.last(t, protocol.MATCH_FOUND); found == nil || found.OpponentID != "ana" {
+		t.Fatalf("Partida contra ana esperada após o segundo resgate, obtido %+v", found)
+	}
+
+	// Depois da partida criada, o código está consumido
+	caio, caioConn := connect(gs, "caio")
+	gs.handleMessage(caio, &protocol.ClientMsg{T: protocol.REDEEM_INVITE, Code: sent.InviteCode})
+	if err := caioConn.last(t, protocol.ERROR); err == nil || err.Code != protocol.CHALLENGE_NOT_FOUND {
+		t.Fatalf("CHALLENGE_NOT_FOUND esperado para código já usado, obtido %+v", err)
+	}
+}
+
+func TestAcceptWithChallengerOffline(t *testing.T) {
+	gs := newTestServer(t)
+	bia, biaConn := connect(gs, "bia")
+
+	// O desafiante já não está online: o aceite é recusado sem derrubar o servidor
+	gs.mu.Lock()
+	gs.startChallenge(game.Challenge{ID: "ch_1", ChallengerID: "ana", TargetID: "bia", Mode: game.ModeSimultaneous}, bia)
+	gs.mu.Unlock()
+
+	if err := biaConn.last(t, protocol.ERROR); err == nil || err.Code != protocol.PLAYER_UNAVAILABLE {
+		t.Fatalf("PLAYER_UNAVAILABLE esperado, obtido %+v", err)
+	}
+}
+
+func TestLobbyRulesAndPassword(t *testing.T) {
+	gs := newTestServer(t)
+	ana, anaConn := connect(gs, "ana")
+	bia, biaConn := connect(gs, "bia")
+
+	// Regra inválida é recusada sem abrir a sala
+	gs.handleMessage(ana, &protocol.ClientMsg{T: protocol.CREATE_LOBBY, Rules: &protocol.Rules{BannedCards: []string{"c_999"}}})
+	if err := anaConn.last(t, protocol.ERROR); err == nil || err.Code != protocol.INVALID_MESSAGE {
+		t.Fatalf("INVALID_MESSAGE esperado para carta banida inexistente, obtido %+v", err)
+	}
+
+	gs.handleMessage(ana, &protocol.ClientMsg{T: protocol.CREATE_LOBBY, Rules: &protocol.Rules{HPStart: 30, HandSize: 4}, Password: "segredo"})
+	lobby := anaConn.last(t, protocol.LOBBY)
+	if lobby == nil || len(lobby.LobbyCode) != game.CodeLength || lobby.HostID != "ana" {
+		t.Fatalf("LOBBY com código e anfitrião esperado, obtido %+v", lobby)
+	}
+
+	gs.handleMessage(bia, &protocol.ClientMsg{T: protocol.JOIN_LOBBY, Code: lobby.LobbyCode, Password: "errada"})
+	if err := biaConn.last(t, protocol.ERROR); err == nil || err.Code != protocol.WRONG_PASSWORD {
+		t.Fatalf("WRONG_PASSWORD esperado, obtido %+v", err)
+	}
+	gs.handleMessage(bia, &protocol.ClientMsg{T: protocol.JOIN_LOBBY, Code: "ZZZZZZ"})
+	if err := biaConn.last(t, protocol.ERROR); err == nil || err.Code != protocol.LOBBY_NOT_FOUND {
+		t.Fatalf("LOBBY_NOT_FOUND esperado, obtido %+v", err)
+	}
+
+	// Com a senha certa (código em minúsculas), a sala enche e a partida usa as regras da sala
+	gs.handleMessage(bia, &protocol.ClientMsg{T: protocol.JOIN_LOBBY, Code: strings.ToLower(lobby.LobbyCode), Password: "segredo"})
+	found := biaConn.last(t, protocol.MATCH_FOUND)
+	if found == nil || found.Rules == nil || found.Rules.HPStart != 30 {
+		t.Fatalf("MATCH_FOUND com as regras da sala esperado, obtido %+v", found)
+	}
+	if offer := anaConn.last(t, protocol.MULLIGAN_OFFER); offer == nil || len(offer.Cards) != 4 {
+		t.Fatalf("Mão inicial de 4 cartas esperada, obtido %+v", offer)
+	}
+	gs.handleMessage(ana, &protocol.ClientMsg{T: protocol.MULLIGAN, MatchID: found.MatchID})
+	gs.handleMessage(bia, &protocol.ClientMsg{T: protocol.MULLIGAN, MatchID: found.MatchID})
+	if state := anaConn.last(t, protocol.STATE); state == nil || state.You.HP != 30 || state.Opponent.HP != 30 {
+		t.Fatalf("HP inicial 30 esperado, obtido %+v", state)
+	}
+	if _, ok := gs.lobbies.LobbyOf("ana"); ok {
+		t.Error("A sala deveria fechar quando a partida começa")
+	}
+}
+
+func TestLobbyRejectsPlayersInMatch(t *testing.T) {
+	gs := newTestServer(t)
+	ana, anaConn := connect(gs, "ana")
+	bia, _ := connect(gs, "bia")
+	caio, caioConn := connect(gs, "caio")
+	eva, evaConn := connect(gs, "eva")
+
+	busy := game.NewMatch("m_busy", []string{"ana", "davi"}, gs.cardDB, game.ModeSimultaneous, 1, game.DefaultRules())
+	gs.mu.Lock()
+	gs.activeMatches[busy.ID] = busy
+	gs.mu.Unlock()
+
+	// Quem está em partida não abre nem entra em salas
+	gs.handleMessage(ana, &protocol.ClientMsg{T: protocol.CREATE_LOBBY})
+	if err := anaConn.last(t, protocol.ERROR); err == nil || err.Code != protocol.PLAYER_UNAVAILABLE {
+		t.Fatalf("PLAYER_UNAVAILABLE esperado ao criar a sala, obtido %+v", err)
+	}
+
+	gs.handleMessage(caio, &protocol.ClientMsg{T: protocol.CREATE_LOBBY, Mode: string(game.ModeFreeForAll)})
+	lobby := caioConn.last(t, protocol.LOBBY)
+	if lobby == nil {
+		t.Fatal("LOBBY esperado para caio")
+	}
+	gs.handleMessage(ana, &protocol.ClientMsg{T: protocol.JOIN_LOBBY, Code: lobby.LobbyCode})
+	if err := anaConn.last(t, protocol.ERROR); err == nil || err.Code != protocol.PLAYER_UNAVAILABLE {
+		t.Fatalf("PLAYER_UNAVAILABLE esperado ao entrar na sala, obtido %+v", err)
+	}
+
+	// Quem entra em outra partida enquanto espera sai da sala na próxima entrada
+	gs.handleMessage(bia, &protocol.ClientMsg{T: protocol.JOIN_LOBBY, Code: lobby.LobbyCode})
+	other := game.NewMatch("m_other", []string{"caio", "davi"}, gs.cardDB, game.ModeSimultaneous, 1, game.DefaultRules())
+	gs.mu.Lock()
+	gs.activeMatches[other.ID] = other
+	gs.mu.Unlock()
+
+	gs.handleMessage(eva, &protocol.ClientMsg{T: protocol.JOIN_LOBBY, Code: lobby.LobbyCode})
+	current := evaConn.last(t, protocol.LOBBY)
+	if current == nil || current.HostID != "bia" || len(current.PlayerIDs) != 2 {
+		t.Fatalf("Sala com bia e eva esperada, obtido %+v", current)
+	}
+}
+
+func TestLobbyBrowserAndSpectators(t *testing.T) {
+	gs := newTestServer(t)
+	ana, anaConn := connect(gs, "ana")
+	bia, _ := connect(gs, "bia")
+	caio, caioConn := connect(gs, "caio")
+	dani, _ := connect(gs, "dani")
+	eva, evaConn := connect(gs, "eva")
+
+	gs.handleMessage(caio, &protocol.ClientMsg{T: protocol.LIST_LOBBIES})
+	if list := caioConn.last(t, protocol.LOBBY_LIST); list == nil || len(list.Lobbies) != 0 {
+		t.Fatalf("LOBBY_LIST vazio esperado, obtido %+v", list)
+	}
+
+	// Quem acompanha o navegador recebe as salas novas
+	gs.handleMessage(ana, &protocol.ClientMsg{T: protocol.CREATE_LOBBY, Spectators: true})
+	added := caioConn.last(t, protocol.LOBBY_ADDED)
+	if added == nil || added.Lobby == nil || added.Lobby.HostID != "ana" || !added.Lobby.Spectators {
+		t.Fatalf("LOBBY_ADDED da sala de ana com espectadores esperado, obtido %+v", added)
+	}
+	code := added.Lobby.Code
+
+	// Sala sem espectadores recusa quem quer assistir
+	gs.handleMessage(dani, &protocol.ClientMsg{T: protocol.CREATE_LOBBY})
+	closed := caioConn.last(t, protocol.LOBBY_ADDED).Lobby.Code
+	gs.handleMessage(eva, &protocol.ClientMsg{T: protocol.JOIN_LOBBY, Code: closed, Spectate: true})
+	if err := evaConn.last(t, protocol.ERROR); err == nil || err.Code != protocol.NO_SPECTATORS {
+		t.Fatalf("NO_SPECTATORS esperado, obtido %+v", err)
+	}
+
+	// O espectador não ocupa assento
+	gs.handleMessage(eva, &protocol.ClientMsg{T: protocol.JOIN_LOBBY, Code: code, Spectate: true})
+	lobby := anaConn.last(t, protocol.LOBBY)
+	if lobby == nil || len(lobby.PlayerIDs) != 1 || len(lobby.SpectatorIDs) != 1 || lobby.SpectatorIDs[0] != "eva" {
+		t.Fatalf("Sala com ana jogando e eva assistindo esperada, obtido %+v", lobby)
+	}
+
+	// Sala cheia sai da lista e o espectador passa a acompanhar a partida
+	gs.handleMessage(bia, &protocol.ClientMsg{T: protocol.JOIN_LOBBY, Code: code})
+	if removed := caioConn.last(t, protocol.LOBBY_REMOVED); removed == nil || removed.LobbyCode != code {
+		t.Fatalf("LOBBY_REMOVED da sala cheia esperado, obtido %+v", removed)
+	}
+	watching := evaConn.last(t, protocol.SPECTATING)
+	if watching == nil || watching.MatchID == "" || len(watching.PlayerIDs) != 2 {
+		t.Fatalf("SPECTATING com os dois jogadores esperado, obtido %+v", watching)
+	}
+
+	gs.handleMessage(ana, &protocol.ClientMsg{T: protocol.MULLIGAN, MatchID: watching.MatchID})
+	gs.handleMessage(bia, &protocol.ClientMsg{T: protocol.MULLIGAN, MatchID: watching.MatchID})
+	gs.handleMessage(ana, &protocol.ClientMsg{T: protocol.FORFEIT, MatchID: watching.MatchID})
+
+	end := evaConn.last(t, protocol.MATCH_END)
+	if end == nil || end.Results["bia"] != protocol.WIN || end.Results["ana"] != protocol.FORFEIT {
+		t.Fatalf("MATCH_END com o resultado de cada jogador esperado, obtido %+v", end)
+	}
+
+	// A visão do espectador nunca traz as mãos dos jogadores
+	for _, msg := range evaConn.messages(t) {
+		if msg.You != nil || msg.Opponent != nil || msg.T == protocol.STATE || msg.T == protocol.MULLIGAN_OFFER {
+			t.Errorf("Espectador recebeu a visão de um jogador: %+v", msg)
+		}
+	}
+}
+
+func TestLoginRequiresToken(t *testing.T) {
+	gs := newTestServer(t)
+	first, firstConn := connect(gs, "127.0.0.1:5001")
+
+	gs.handleMessage(first, &protocol.ClientMsg{T: protocol.LOGIN, PlayerID: "ana"})
+	logged := firstConn.last(t, protocol.LOGGED_IN)
+	if logged == nil || logged.PlayerID != "ana" || logged.Token == "" {
+		t.Fatalf("LOGGED_IN com token esperado no primeiro LOGIN, obtido %+v", logged)
+	}
+	gs.cleanup(first)
+
+	// Outra conexão não assume o nome sem o token
+	second, secondConn := connect(gs, "127.0.0.1:5002")
+	for _, token := range []string{"", "errado"} {
+		gs.handleMessage(second, &protocol.ClientMsg{T: protocol.LOGIN, PlayerID: "ana", Token: token})
+		if err := secondConn.last(t, protocol.ERROR); err == nil || err.Code != protocol.INVALID_TOKEN {
+			t.Fatalf("INVALID_TOKEN esperado com o token %q, obtido %+v", token, err)
+		}
+	}
+	if second.ID == "ana" || gs.loggedIn(second) {
+		t.Fatal("Conexão sem o token assumiu o nome")
+	}
+
+	// Com o token, o nome volta para o dono (sem emitir outro token)
+	gs.handleMessage(second, &protocol.ClientMsg{T: protocol.LOGIN, PlayerID: "ana", Token: logged.Token})
+	again := secondConn.last(t, protocol.LOGGED_IN)
+	if again == nil || again.PlayerID != "ana" || again.Token != "" || !gs.loggedIn(second) {
+		t.Fatalf("LOGGED_IN sem novo token esperado, obtido %+v", again)
+	}
+}
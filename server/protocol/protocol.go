@@ -8,10 +8,30 @@ import (
 
 // Mensagens do Cliente para o Servidor
 type ClientMsg struct {
-	T      string `json:"t"`
-	CardID string `json:"cardId,omitempty"`
-	Text   string `json:"text,omitempty"`
-	TS     int64  `json:"ts,omitempty"`
+	T           string   `json:"t"`
+	CardID      string   `json:"cardId,omitempty"`
+	Action      string   `json:"action,omitempty"` // PLAY (padrão), DEFEND ou CYCLE
+	Text        string   `json:"text,omitempty"`
+	TS          int64    `json:"ts,omitempty"`
+	Mode        string   `json:"mode,omitempty"`
+	Target      string   `json:"target,omitempty"`
+	MatchID     string   `json:"matchId,omitempty"`
+	TimeControl string   `json:"timeControl,omitempty"`
+	Cards       []string `json:"cards,omitempty"`       // cartas devolvidas no mulligan ou descartadas no CYCLE
+	Terrain     bool     `json:"terrain,omitempty"`     // FIND_MATCH: partida com terreno a cada rodada
+	Rules       *Rules   `json:"rules,omitempty"`       // CREATE_LOBBY: regras da sala (omitidas = padrão)
+	Code        string   `json:"code,omitempty"`        // JOIN_LOBBY e REDEEM_INVITE: código de convite
+	Password    string   `json:"password,omitempty"`    // CREATE_LOBBY e JOIN_LOBBY: senha da sala
+	Spectators  bool     `json:"spectators,omitempty"`  // CREATE_LOBBY: sala aceita espectadores
+	Spectate    bool     `json:"spectate,omitempty"`    // JOIN_LOBBY: entrar na sala como espectador
+	PlayerID    string   `json:"playerId,omitempty"`    // CHALLENGE: jogador desafiado
+	ChallengeID string   `json:"challengeId,omitempty"` // ACCEPT_CHALLENGE e DECLINE_CHALLENGE
+	Async       bool     `json:"async,omitempty"`       // FIND_MATCH, CHALLENGE e CREATE_INVITE: partida assíncrona
+	EncounterID string   `json:"encounterId,omitempty"` // START_ENCOUNTER: encontro da campanha
+	ScenarioID  string   `json:"scenarioId,omitempty"`  // START_SCENARIO: cenário de treino
+	Ranked      bool     `json:"ranked,omitempty"`      // FIND_MATCH: partida ranqueada (vale rating)
+	Page        int      `json:"page,omitempty"`        // LEADERBOARD: página do ranking (a partir de 1)
+	Token       string   `json:"token,omitempty"`       // LOGIN: token recebido no primeiro LOGIN do nome
 }
 
 // Mensagens do Servidor para o Cliente
@@ -24,6 +44,7 @@ type ServerMsg struct {
 	Opponent   *PlayerView `json:"opponent,omitempty"`
 	Round      int         `json:"round,omitempty"`
 	DeadlineMs int64       `json:"deadlineMs,omitempty"`
+	BankMs     int64       `json:"bankMs,omitempty"`
 	Cards      []string    `json:"cards,omitempty"`
 	Stock      int         `json:"stock,omitempty"`
 	Code       string      `json:"code,omitempty"`
@@ -31,7 +52,55 @@ type ServerMsg struct {
 	TS         int64       `json:"ts,omitempty"`
 	RTTMs      int64       `json:"rttMs,omitempty"`
 	Result     string      `json:"result,omitempty"`
+	Reason     string      `json:"reason,omitempty"`  // motivo do fim da partida (MATCH_END)
+	Terrain    string      `json:"terrain,omitempty"` // terreno da rodada (STATE e ROUND_RESULT)
 	Logs       []string    `json:"logs,omitempty"`
+	// Campos para o modo por turnos
+	Mode        string `json:"mode,omitempty"`
+	TimeControl string `json:"timeControl,omitempty"`
+	Phase       string `json:"phase,omitempty"`
+	AttackerID  string `json:"attackerId,omitempty"`
+	// Campos para partidas com mais de dois jogadores
+	Seats     []SeatView `json:"seats,omitempty"`
+	PlayerIDs []string   `json:"playerIds,omitempty"`
+	// Campos para o modo draft
+	Pool []string `json:"pool,omitempty"`
+	Pick int      `json:"pick,omitempty"`
+	// Campos para o mulligan
+	Replaced int `json:"replaced,omitempty"` // cartas trocadas pelo jogador (MULLIGAN_DONE)
+	// Campos para salas personalizadas
+	LobbyCode string      `json:"lobbyCode,omitempty"`
+	HostID    string      `json:"hostId,omitempty"`
+	Rules     *Rules      `json:"rules,omitempty"`   // regras da sala (LOBBY) ou da partida (MATCH_FOUND)
+	Lobby     *LobbyView  `json:"lobby,omitempty"`   // sala aberta ou atualizada (LOBBY_ADDED)
+	Lobbies   []LobbyView `json:"lobbies,omitempty"` // salas abertas (LOBBY_LIST)
+	// Campos para espectadores
+	SpectatorIDs []string          `json:"spectatorIds,omitempty"` // espectadores da sala (LOBBY)
+	Results      map[string]string `json:"results,omitempty"`      // resultado de cada jogador (MATCH_END enviado aos espectadores)
+	// Campos para desafios
+	ChallengeID string `json:"challengeId,omitempty"`
+	InviteCode  string `json:"inviteCode,omitempty"`
+	// Campos para partidas assíncronas
+	PlayerID string           `json:"playerId,omitempty"` // nome do jogador identificado (LOGGED_IN)
+	Token    string           `json:"token,omitempty"`    // token emitido no primeiro LOGIN do nome (LOGGED_IN)
+	Matches  []AsyncMatchView `json:"matches,omitempty"`  // partidas assíncronas em andamento (ASYNC_MATCHES)
+	// Campos para a campanha
+	EncounterID string          `json:"encounterId,omitempty"` // encontro vencido pela primeira vez (CAMPAIGN_REWARD)
+	Encounters  []EncounterView `json:"encounters,omitempty"`  // encontros da campanha (CAMPAIGN_INFO)
+	Coins       int             `json:"coins,omitempty"`       // moedas recebidas (CAMPAIGN_REWARD) ou acumuladas (CAMPAIGN_INFO)
+	// Campos para os cenários de treino
+	Scenarios []ScenarioView  `json:"scenarios,omitempty"` // cenários disponíveis (SCENARIO_LIST)
+	Report    *ScenarioReport `json:"report,omitempty"`    // resultado do objetivo (SCENARIO_RESULT)
+	// Campos para o raid
+	Boss *BossView `json:"boss,omitempty"` // chefe enfrentado (MATCH_FOUND do modo RAID)
+	// Campos para o ranking
+	Rating      *RatingChange    `json:"rating,omitempty"`      // variação do rating (RATING_UPDATE)
+	Leaderboard *LeaderboardView `json:"leaderboard,omitempty"` // página do ranking (LEADERBOARD_PAGE)
+	Season      *SeasonResult    `json:"season,omitempty"`      // resultado da temporada encerrada (SEASON_END)
+	// Campos para o perfil
+	Profile *ProfileView `json:"profile,omitempty"` // estatísticas do jogador (PROFILE_INFO)
+	// Campos para replays
+	Replay []ReplayEntry `json:"replay,omitempty"`
 	// Campos para chat
 	SenderID string `json:"senderId,omitempty"`
 	Text     string `json:"text,omitempty"`
@@ -39,45 +108,318 @@ type ServerMsg struct {
 
 // PlayerView representa a visão de um jogador no estado da partida
 type PlayerView struct {
-	HP           int      `json:"hp"`
-	Hand         []string `json:"hand,omitempty"`
-	HandSize     int      `json:"handSize,omitempty"`
-	CardID       string   `json:"cardId,omitempty"`
-	ElementBonus int      `json:"elementBonus,omitempty"`
-	DmgDealt     int      `json:"dmgDealt,omitempty"`
-	DmgTaken     int      `json:"dmgTaken,omitempty"`
+	HP           int          `json:"hp"`
+	Hand         []string     `json:"hand,omitempty"`
+	HandSize     int          `json:"handSize,omitempty"`
+	CardID       string       `json:"cardId,omitempty"`
+	Action       string       `json:"action,omitempty"` // DEFEND ou CYCLE (ausente = PLAY)
+	ElementBonus int          `json:"elementBonus,omitempty"`
+	TerrainBonus int          `json:"terrainBonus,omitempty"` // ajuste de ATK do terreno (pode ser negativo)
+	DmgDealt     int          `json:"dmgDealt,omitempty"`
+	DmgTaken     int          `json:"dmgTaken,omitempty"`
+	Effects      []StatusView `json:"effects,omitempty"`
+	Energy       int          `json:"energy,omitempty"`
+	MaxEnergy    int          `json:"maxEnergy,omitempty"`
+	DeckSize     int          `json:"deckSize,omitempty"`
+	BankMs       int64        `json:"bankMs,omitempty"` // banco de tempo restante
+}
+
+// SeatView descreve um assento em partidas com mais de dois jogadores
+type SeatView struct {
+	PlayerID   string `json:"playerId"`
+	Team       int    `json:"team"`
+	Target     string `json:"target,omitempty"`
+	Eliminated bool   `json:"eliminated,omitempty"`
+	PlayerView
+}
+
+// ReplayEntry representa um registro (linha JSONL) do replay de uma partida
+type ReplayEntry struct {
+	T        string            `json:"t"`
+	TS       int64             `json:"ts"` // ms desde epoch (chegada da jogada no servidor)
+	MatchID  string            `json:"matchId,omitempty"`
+	Mode     string            `json:"mode,omitempty"`
+	Seed     int64             `json:"seed,omitempty"`
+	Rules    *Rules            `json:"rules,omitempty"`
+	Players  []string          `json:"players,omitempty"`
+	Hands    [][]string        `json:"hands,omitempty"`
+	Round    int               `json:"round,omitempty"`
+	Terrain  string            `json:"terrain,omitempty"`
+	PlayerID string            `json:"playerId,omitempty"`
+	CardID   string            `json:"cardId,omitempty"`
+	Action   string            `json:"action,omitempty"`
+	Cards    []string          `json:"cards,omitempty"`
+	Target   string            `json:"target,omitempty"`
+	Auto     bool              `json:"auto,omitempty"`
+	Seats    []SeatView        `json:"seats,omitempty"`
+	Results  map[string]string `json:"results,omitempty"`
+	Reason   string            `json:"reason,omitempty"`
+}
+
+// Rules representa as regras de uma partida personalizada
+type Rules struct {
+	HPStart           int      `json:"hpStart,omitempty"`
+	HandSize          int      `json:"handSize,omitempty"`
+	ElementalATKBonus *int     `json:"elementalAtkBonus,omitempty"` // 0 desativa o bônus (omitido = padrão)
+	RoundPlayTimeout  int      `json:"roundPlayTimeoutMs,omitempty"`
+	DeckPolicy        string   `json:"deckPolicy,omitempty"` // RANDOM (padrão), SINGLETON ou DRAFT
+	BannedCards       []string `json:"bannedCards,omitempty"`
+}
+
+// LobbyView resume uma sala personalizada aberta no navegador de salas
+type LobbyView struct {
+	Code              string `json:"code"`
+	HostID            string `json:"hostId"`
+	Mode              string `json:"mode"`
+	Rules             *Rules `json:"rules"`
+	Players           int    `json:"players"`
+	MaxPlayers        int    `json:"maxPlayers"`
+	PasswordProtected bool   `json:"passwordProtected"`
+	Spectators        bool   `json:"spectators"`
+}
+
+// AsyncMatchView resume uma partida assíncrona em andamento na perspectiva do jogador
+type AsyncMatchView struct {
+	MatchID    string `json:"matchId"`
+	Mode       string `json:"mode"`
+	OpponentID string `json:"opponentId"`
+	Round      int    `json:"round"`
+	YourTurn   bool   `json:"yourTurn"`
+	DeadlineMs int64  `json:"deadlineMs"`
+	HP         int    `json:"hp"`
+	OpponentHP int    `json:"opponentHp"`
+}
+
+// EncounterView descreve um encontro da campanha e o progresso do jogador nele
+type EncounterView struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Mode        string `json:"mode"`
+	EnemyHP     int    `json:"enemyHp"`
+	Strategy    string `json:"strategy"`
+	RewardPacks int    `json:"rewardPacks"`
+	RewardCoins int    `json:"rewardCoins"`
+	Unlocked    bool   `json:"unlocked"`
+	Cleared     bool   `json:"cleared"`
+}
+
+// BossView descreve o chefe de um raid e suas habilidades
+type BossView struct {
+	ID          string `json:"id"`
+	PlayerID    string `json:"playerId"` // assento do chefe (alvo das jogadas)
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	HP          int    `json:"hp"`
+	Armor       int    `json:"armor,omitempty"`
+	EnrageBelow int    `json:"enrageBelow,omitempty"`
+	EnrageATK   int    `json:"enrageAtk,omitempty"`
+	CleaveEvery int    `json:"cleaveEvery,omitempty"`
+}
+
+// ScenarioView descreve um cenário de treino
+type ScenarioView struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Mode        string `json:"mode"`
+	Goal        string `json:"goal"`   // WIN ou SURVIVE
+	Rounds      int    `json:"rounds"` // rodadas do objetivo
+}
+
+// ScenarioReport informa se o jogador cumpriu o objetivo do cenário de treino
+type ScenarioReport struct {
+	ScenarioID string `json:"scenarioId"`
+	Goal       string `json:"goal"`
+	Rounds     int    `json:"rounds"`
+	Round      int    `json:"round"`  // rodada em que o cenário terminou
+	Passed     bool   `json:"passed"` // objetivo cumprido
+	HP         int    `json:"hp"`
+	OpponentHP int    `json:"opponentHp"`
+}
+
+// RatingChange informa o rating do jogador após uma partida ranqueada
+type RatingChange struct {
+	PlayerID string `json:"playerId"`
+	Season   int    `json:"season"`
+	Rating   int    `json:"rating"`
+	Delta    int    `json:"delta"` // variação causada pela partida (pode ser negativa)
+	Division string `json:"division"`
+}
+
+// LadderEntry é a linha de um jogador no ranking da temporada
+type LadderEntry struct {
+	Rank     int    `json:"rank"`
+	PlayerID string `json:"playerId"`
+	Rating   int    `json:"rating"`
+	Division string `json:"division"`
+	Wins     int    `json:"wins"`
+	Losses   int    `json:"losses"`
+	Draws    int    `json:"draws"`
+}
+
+// LeaderboardView é uma página do ranking da temporada
+type LeaderboardView struct {
+	Season      int           `json:"season"`
+	SeasonEndMs int64         `json:"seasonEndMs"` // tempo restante da temporada
+	Page        int           `json:"page"`
+	Pages       int           `json:"pages"`
+	Entries     []LadderEntry `json:"entries"`
+	You         *LadderEntry  `json:"you,omitempty"` // posição de quem pediu (ausente se não jogou na temporada)
+}
+
+// SeasonResult é a colocação final do jogador em uma temporada encerrada e sua recompensa
+type SeasonResult struct {
+	PlayerID  string `json:"playerId"`
+	Season    int    `json:"season"`
+	Rank      int    `json:"rank"`
+	Rating    int    `json:"rating"`    // rating final da temporada
+	NewRating int    `json:"newRating"` // rating inicial da próxima temporada (soft reset)
+	Division  string `json:"division"`
+	Coins     int    `json:"coins"` // moedas da recompensa da divisão
+}
+
+// ProfileView são as estatísticas de um jogador, calculadas a partir das partidas que jogou identificado
+type ProfileView struct {
+	PlayerID         string         `json:"playerId"`
+	Matches          int            `json:"matches"`
+	Wins             int            `json:"wins"`
+	Losses           int            `json:"losses"` // inclui desistências e abandonos
+	Draws            int            `json:"draws"`
+	WinRate          int            `json:"winRate"` // % de vitórias
+	Elements         []ElementStats `json:"elements,omitempty"`
+	Rounds           int            `json:"rounds"`
+	AvgDamageDealt   float64        `json:"avgDamageDealt"` // por rodada jogada
+	AvgDamageTaken   float64        `json:"avgDamageTaken"`
+	FavoriteCards    []CardCount    `json:"favoriteCards,omitempty"`
+	CurrentStreak    int            `json:"currentStreak"`
+	LongestWinStreak int            `json:"longestWinStreak"`
+	AutoPlayRate     int            `json:"autoPlayRate"` // % das jogadas feitas por timeout
+	Rating           int            `json:"rating"`       // rating do ranking (§3.16)
+	Division         string         `json:"division"`
+}
+
+// ElementStats é o desempenho do jogador nas partidas em que usou cartas do elemento
+type ElementStats struct {
+	Element string `json:"element"`
+	Matches int    `json:"matches"`
+	Wins    int    `json:"wins"`
+	WinRate int    `json:"winRate"` // %
+}
+
+// CardCount é uma carta e quantas vezes o jogador a usou
+type CardCount struct {
+	CardID string `json:"cardId"`
+	Plays  int    `json:"plays"`
+}
+
+// StatusView representa um efeito de status ativo em um jogador
+type StatusView struct {
+	Type     string `json:"type"`
+	Duration int    `json:"duration"`
+	Stacks   int    `json:"stacks"`
 }
 
 // Constantes de tipos de mensagens
 const (
 	// Cliente -> Servidor
-	FIND_MATCH = "FIND_MATCH"
-	PLAY       = "PLAY"
-	CHAT       = "CHAT"
-	PING       = "PING"
-	OPEN_PACK  = "OPEN_PACK"
-	LEAVE      = "LEAVE"
+	FIND_MATCH        = "FIND_MATCH"
+	PLAY              = "PLAY"
+	CHAT              = "CHAT"
+	PING              = "PING"
+	OPEN_PACK         = "OPEN_PACK"
+	LEAVE             = "LEAVE"
+	DRAFT_PICK        = "DRAFT_PICK"
+	GET_REPLAY        = "GET_REPLAY"
+	OFFER_DRAW        = "OFFER_DRAW"
+	ACCEPT_DRAW       = "ACCEPT_DRAW"
+	DECLINE_DRAW      = "DECLINE_DRAW"
+	UNPLAY            = "UNPLAY"
+	LOCK_IN           = "LOCK_IN"
+	MULLIGAN          = "MULLIGAN"
+	CREATE_LOBBY      = "CREATE_LOBBY"
+	JOIN_LOBBY        = "JOIN_LOBBY"
+	CHALLENGE         = "CHALLENGE"
+	CREATE_INVITE     = "CREATE_INVITE"
+	REDEEM_INVITE     = "REDEEM_INVITE"
+	ACCEPT_CHALLENGE  = "ACCEPT_CHALLENGE"
+	DECLINE_CHALLENGE = "DECLINE_CHALLENGE"
+	LIST_LOBBIES      = "LIST_LOBBIES"
+	LOGIN             = "LOGIN"
+	LIST_MATCHES      = "LIST_MATCHES"
+	GET_STATE         = "GET_STATE"
+	CAMPAIGN          = "CAMPAIGN"
+	START_ENCOUNTER   = "START_ENCOUNTER"
+	LIST_SCENARIOS    = "LIST_SCENARIOS"
+	START_SCENARIO    = "START_SCENARIO"
+	LEADERBOARD       = "LEADERBOARD"
+	PROFILE           = "PROFILE"
 
 	// Servidor -> Cliente
-	MATCH_FOUND  = "MATCH_FOUND"
-	STATE        = "STATE"
-	ROUND_RESULT = "ROUND_RESULT"
-	PACK_OPENED  = "PACK_OPENED"
-	ERROR        = "ERROR"
-	PONG         = "PONG"
-	MATCH_END    = "MATCH_END"
-	CHAT_MESSAGE = "CHAT_MESSAGE"
+	MATCH_FOUND        = "MATCH_FOUND"
+	STATE              = "STATE"
+	ROUND_RESULT       = "ROUND_RESULT"
+	PACK_OPENED        = "PACK_OPENED"
+	ERROR              = "ERROR"
+	PONG               = "PONG"
+	MATCH_END          = "MATCH_END"
+	CHAT_MESSAGE       = "CHAT_MESSAGE"
+	DRAFT_PACK         = "DRAFT_PACK"
+	DRAFT_DONE         = "DRAFT_DONE"
+	REPLAY             = "REPLAY"
+	DRAW_OFFERED       = "DRAW_OFFERED"
+	DRAW_DECLINED      = "DRAW_DECLINED"
+	LOCKED_IN          = "LOCKED_IN"
+	MULLIGAN_OFFER     = "MULLIGAN_OFFER"
+	MULLIGAN_DONE      = "MULLIGAN_DONE"
+	LOBBY              = "LOBBY"
+	CHALLENGE_SENT     = "CHALLENGE_SENT"
+	CHALLENGE_RECEIVED = "CHALLENGE_RECEIVED"
+	CHALLENGE_DECLINED = "CHALLENGE_DECLINED"
+	CHALLENGE_EXPIRED  = "CHALLENGE_EXPIRED"
+	LOBBY_LIST         = "LOBBY_LIST"
+	LOBBY_ADDED        = "LOBBY_ADDED"
+	LOBBY_REMOVED      = "LOBBY_REMOVED"
+	LOGGED_IN          = "LOGGED_IN"
+	ASYNC_MATCHES      = "ASYNC_MATCHES"
+	YOUR_TURN          = "YOUR_TURN"
+	CAMPAIGN_INFO      = "CAMPAIGN_INFO"
+	CAMPAIGN_REWARD    = "CAMPAIGN_REWARD"
+	SCENARIO_LIST      = "SCENARIO_LIST"
+	SCENARIO_RESULT    = "SCENARIO_RESULT"
+	RATING_UPDATE      = "RATING_UPDATE"
+	LEADERBOARD_PAGE   = "LEADERBOARD_PAGE"
+	SEASON_END         = "SEASON_END"
+	PROFILE_INFO       = "PROFILE_INFO"
+	SPECTATING         = "SPECTATING"
 )
 
 // Códigos de erro
 const (
-	INVALID_MESSAGE = "INVALID_MESSAGE"
-	INVALID_CARD    = "INVALID_CARD"
-	NOT_YOUR_TURN   = "NOT_YOUR_TURN"
-	TIMEOUT_PLAY    = "TIMEOUT_PLAY"
-	MATCH_NOT_FOUND = "MATCH_NOT_FOUND"
-	OUT_OF_STOCK    = "OUT_OF_STOCK"
-	INTERNAL        = "INTERNAL"
+	INVALID_MESSAGE     = "INVALID_MESSAGE"
+	INVALID_CARD        = "INVALID_CARD"
+	NOT_YOUR_TURN       = "NOT_YOUR_TURN"
+	TIMEOUT_PLAY        = "TIMEOUT_PLAY"
+	MATCH_NOT_FOUND     = "MATCH_NOT_FOUND"
+	OUT_OF_STOCK        = "OUT_OF_STOCK"
+	NOT_ENOUGH_ENERGY   = "NOT_ENOUGH_ENERGY"
+	INVALID_TARGET      = "INVALID_TARGET"
+	REPLAY_NOT_FOUND    = "REPLAY_NOT_FOUND"
+	AFK_WARNING         = "AFK_WARNING"
+	NO_DRAW_OFFER       = "NO_DRAW_OFFER"
+	TIME_BANK           = "TIME_BANK"
+	LOBBY_NOT_FOUND     = "LOBBY_NOT_FOUND"
+	CHALLENGE_NOT_FOUND = "CHALLENGE_NOT_FOUND"
+	PLAYER_UNAVAILABLE  = "PLAYER_UNAVAILABLE"
+	WRONG_PASSWORD      = "WRONG_PASSWORD"
+	NO_SPECTATORS       = "NO_SPECTATORS"
+	LOGIN_REQUIRED      = "LOGIN_REQUIRED"
+	INVALID_TOKEN       = "INVALID_TOKEN"
+	ENCOUNTER_NOT_FOUND = "ENCOUNTER_NOT_FOUND"
+	ENCOUNTER_LOCKED    = "ENCOUNTER_LOCKED"
+	SCENARIO_NOT_FOUND  = "SCENARIO_NOT_FOUND"
+	PROFILE_NOT_FOUND   = "PROFILE_NOT_FOUND"
+	INTERNAL            = "INTERNAL"
 )
 
 // Resultados de partida
@@ -85,6 +427,18 @@ const (
 	WIN  = "WIN"
 	LOSE = "LOSE"
 	DRAW = "DRAW"
+
+	FORFEIT = "FORFEIT" // derrota por desistência ou abandono; também é o tipo da mensagem de desistência do cliente
+)
+
+// Motivos de fim de partida (MATCH_END.reason)
+const (
+	END_HP          = "hp"          // times restantes eliminados por dano
+	END_FORFEIT     = "forfeit"     // desistência com FORFEIT
+	END_DISCONNECT  = "disconnect"  // desconexão ou LEAVE
+	END_DRAW_AGREED = "draw_agreed" // empate aceito por todos
+	END_TIMEOUT     = "timeout"     // abandono por inatividade
+	END_GOAL        = "goal"        // objetivo do cenário de treino decidido
 )
 
 // PlayerConn representa um jogador conectado com encoder/decoder JSON
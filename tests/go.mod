@@ -1,7 +1,12 @@
 module tests
 
-go 1.21
+go 1.22
 
 replace pingpong/server/packs => ../server/packs
 
-require pingpong/server/packs v0.0.0-00010101000000-000000000000
+replace pingpong/server => ../server
+
+require (
+	pingpong/server v0.0.0-00010101000000-000000000000
+	pingpong/server/packs v0.0.0-00010101000000-000000000000
+)
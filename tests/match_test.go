@@ -0,0 +1,1306 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pingpong/server/game"
+	"pingpong/server/protocol"
+)
+
+// eventRecorder guarda os eventos emitidos pela partida, por jogador, com o momento da emissão.
+// Os timers da partida emitem em outras goroutines, então todo acesso passa pelo mutex
+type eventRecorder struct {
+	mu   sync.Mutex
+	msgs map[string][]protocol.ServerMsg
+	at   map[string][]time.Time
+}
+
+// newEventRecorder cria um gravador de eventos vazio
+func newEventRecorder() *eventRecorder {
+	return &eventRecorder{msgs: make(map[string][]protocol.ServerMsg), at: make(map[string][]time.Time)}
+}
+
+func (r *eventRecorder) OnEvent(event game.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.msgs[event.PlayerID] = append(r.msgs[event.PlayerID], event.Msg)
+	r.at[event.PlayerID] = append(r.at[event.PlayerID], time.Now())
+}
+
+// find retorna a última mensagem do jogador aceita por match e o momento em que foi emitida
+func (r *eventRecorder) find(playerID string, match func(msg protocol.ServerMsg) bool) (*protocol.ServerMsg, time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	msgs := r.msgs[playerID]
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if match(msgs[i]) {
+			msg := msgs[i]
+			return &msg, r.at[playerID][i]
+		}
+	}
+	return nil, time.Time{}
+}
+
+// last retorna a última mensagem do tipo recebida pelo jogador
+func (r *eventRecorder) last(playerID, msgType string) *protocol.ServerMsg {
+	msg, _ := r.find(playerID, func(msg protocol.ServerMsg) bool { return msg.T == msgType })
+	return msg
+}
+
+// lastError retorna o último ERROR com o código recebido pelo jogador
+func (r *eventRecorder) lastError(playerID, code string) *protocol.ServerMsg {
+	msg, _ := r.find(playerID, func(msg protocol.ServerMsg) bool {
+		return msg.T == protocol.ERROR && msg.Code == code
+	})
+	return msg
+}
+
+// waitFor aguarda uma mensagem do jogador aceita por match (emitida pelos timers da partida) e
+// falha o teste se ela não chegar dentro do prazo
+func (r *eventRecorder) waitFor(t *testing.T, playerID string, timeout time.Duration, match func(msg protocol.ServerMsg) bool) (*protocol.ServerMsg, time.Time) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		if msg, at := r.find(playerID, match); msg != nil {
+			return msg, at
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Mensagem esperada por %s não chegou em %s", playerID, timeout)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// loadTestCards carrega a base de cartas do servidor
+func loadTestCards(t *testing.T) *game.CardDB {
+	t.Helper()
+
+	cardDB := game.NewCardDB()
+	if err := cardDB.LoadFromFile("../server/cards.json"); err != nil {
+		t.Fatalf("Erro ao carregar cartas: %v", err)
+	}
+	return cardDB
+}
+
+// newMulliganMatch cria uma partida sem rede com as mãos definidas pelo teste, parada no mulligan
+func newMulliganMatch(t *testing.T, mode game.MatchMode, hands ...game.Hand) (*game.Match, *eventRecorder) {
+	t.Helper()
+
+	players := []string{"p1", "p2", "p3", "p4"}[:len(hands)]
+	match := game.NewMatch("m_test", players, loadTestCards(t), mode, 1, game.DefaultRules())
+	copy(match.Hands, hands)
+
+	recorder := newEventRecorder()
+	match.Subscribe(recorder)
+	match.Start()
+
+	return match, recorder
+}
+
+// newTestMatch cria uma partida sem rede com as mãos definidas pelo teste, já na primeira rodada
+func newTestMatch(t *testing.T, mode game.MatchMode, hands ...game.Hand) (*game.Match, *eventRecorder) {
+	t.Helper()
+
+	match, recorder := newMulliganMatch(t, mode, hands...)
+	keepHands(match)
+
+	return match, recorder
+}
+
+// keepHands encerra o mulligan mantendo as mãos iniciais de todos os jogadores
+func keepHands(match *game.Match) {
+	for _, playerID := range match.Players {
+		match.Mulligan(playerID, nil)
+	}
+}
+
+func TestRoundResolution(t *testing.T) {
+	match, events := newTestMatch(t, game.ModeSimultaneous,
+		game.Hand{"c_001", "c_004", "c_004", "c_004", "c_004"}, // Fire Dragon (ATK 8 / DEF 5, BURN)
+		game.Hand{"c_002", "c_004", "c_004", "c_004", "c_004"}, // Ice Mage (ATK 6 / DEF 6, FREEZE)
+	)
+
+	if err := match.Apply(game.Play{PlayerID: "p1", CardID: "c_001", Lock: true}); err != nil {
+		t.Fatalf("Jogada de p1 rejeitada: %v", err)
+	}
+	if events.last("p1", protocol.ROUND_RESULT) != nil {
+		t.Fatal("Rodada resolvida antes de todos jogarem")
+	}
+	if err := match.Apply(game.Play{PlayerID: "p2", CardID: "c_002", Lock: true}); err != nil {
+		t.Fatalf("Jogada de p2 rejeitada: %v", err)
+	}
+
+	result := events.last("p1", protocol.ROUND_RESULT)
+	if result == nil {
+		t.Fatal("ROUND_RESULT não foi emitido")
+	}
+
+	// p1: 8 ATK - 6 DEF = 2; p2: 6 ATK + 3 (WATER > FIRE) - 5 DEF = 4
+	if result.You.DmgDealt != 2 || result.You.DmgTaken != 4 {
+		t.Errorf("Dano esperado 2/4, obtido %d/%d", result.You.DmgDealt, result.You.DmgTaken)
+	}
+	if match.HP[0] != 16 || match.HP[1] != 18 {
+		t.Errorf("HP esperado 16/18, obtido %d/%d", match.HP[0], match.HP[1])
+	}
+	if match.Round != 2 {
+		t.Errorf("Rodada esperada 2, obtida %d", match.Round)
+	}
+
+	// Os efeitos das cartas passam a valer na próxima rodada
+	if err := match.Apply(game.Play{PlayerID: "p1", CardID: "c_004", Lock: true}); err == nil {
+		t.Error("Jogador congelado conseguiu jogar")
+	}
+	if state := events.last("p2", protocol.STATE); state == nil || len(state.Opponent.Effects) != 1 {
+		t.Error("STATE deveria mostrar o congelamento do oponente")
+	}
+}
+
+func TestPlayValidation(t *testing.T) {
+	match, _ := newTestMatch(t, game.ModeTurnBased,
+		game.Hand{"c_007", "c_004", "c_004", "c_004", "c_004"},
+		game.Hand{"c_007", "c_004", "c_004", "c_004", "c_004"},
+	)
+
+	if err := match.Apply(game.Play{PlayerID: "p2", CardID: "c_004", Lock: true}); !errors.Is(err, game.ErrNotYourTurn) {
+		t.Errorf("Defensor jogando no ataque: esperado ErrNotYourTurn, obtido %v", err)
+	}
+	if err := match.Apply(game.Play{PlayerID: "p1", CardID: "c_009", Lock: true}); err == nil {
+		t.Error("Carta fora da mão foi aceita")
+	}
+
+	match.Energy[0] = 3
+	if err := match.Apply(game.Play{PlayerID: "p1", CardID: "c_007", Lock: true}); !errors.Is(err, game.ErrNotEnoughEnergy) {
+		t.Errorf("Carta de custo 4 com 3 de energia: esperado ErrNotEnoughEnergy, obtido %v", err)
+	}
+}
+
+func TestFreeForAllElimination(t *testing.T) {
+	hand := game.Hand{"c_007", "c_007", "c_007", "c_007", "c_007"} // Inferno Titan (ATK 10 / DEF 2)
+	match, events := newTestMatch(t, game.ModeFreeForAll, hand, hand, hand)
+
+	if err := match.Apply(game.Play{PlayerID: "p1", CardID: "c_007", Target: "p1", Lock: true}); !errors.Is(err, game.ErrInvalidTarget) {
+		t.Errorf("Alvo em si mesmo: esperado ErrInvalidTarget, obtido %v", err)
+	}
+
+	// p1 e p2 atacam p3, que ataca p1
+	match.HP[2] = 10
+	for _, play := range []game.Play{
+		{PlayerID: "p1", CardID: "c_007", Target: "p3", Lock: true},
+		{PlayerID: "p2", CardID: "c_007", Target: "p3", Lock: true},
+		{PlayerID: "p3", CardID: "c_007", Target: "p1", Lock: true},
+	} {
+		if err := match.Apply(play); err != nil {
+			t.Fatalf("Jogada de %s rejeitada: %v", play.PlayerID, err)
+		}
+	}
+
+	if match.HasPlayer("p3") && match.HP[2] > 0 {
+		t.Errorf("p3 deveria ter sido eliminado (HP %d)", match.HP[2])
+	}
+	if err := match.Apply(game.Play{PlayerID: "p3", CardID: match.Hands[2][0], Lock: true}); err == nil {
+		t.Error("Jogador eliminado conseguiu jogar")
+	}
+	if events.last("p1", protocol.MATCH_END) != nil {
+		t.Error("Partida terminou com dois jogadores ativos")
+	}
+}
+
+func TestSeededMatchIsReproducible(t *testing.T) {
+	cardDB := loadTestCards(t)
+
+	// Joga a primeira carta de cada mão por algumas rodadas e retorna as mãos vistas
+	playRounds := func(seed int64) []game.Hand {
+		match := game.NewMatch("m_seed", []string{"p1", "p2"}, cardDB, game.ModeSimultaneous, seed, game.DefaultRules())
+		match.Start()
+		keepHands(match)
+
+		hands := []game.Hand{}
+		for round := 0; round < 3; round++ {
+			hands = append(hands, append(game.Hand{}, match.Hands[0]...), append(game.Hand{}, match.Hands[1]...))
+			match.Energy[0], match.Energy[1] = game.EnergyCap, game.EnergyCap
+			match.Apply(game.Play{PlayerID: "p1", CardID: match.Hands[0][0], Lock: true})
+			match.Apply(game.Play{PlayerID: "p2", CardID: match.Hands[1][0], Lock: true})
+		}
+		return hands
+	}
+
+	first, second := playRounds(42), playRounds(42)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Partidas com a mesma seed divergiram:\n%v\n%v", first, second)
+	}
+	if reflect.DeepEqual(first, playRounds(43)) {
+		t.Error("Partidas com seeds diferentes geraram as mesmas mãos")
+	}
+}
+
+func TestReplayRecording(t *testing.T) {
+	dir := t.TempDir()
+	match, _ := newTestMatch(t, game.ModeSimultaneous,
+		game.Hand{"c_007", "c_004", "c_004", "c_004", "c_004"},
+		game.Hand{"c_006", "c_004", "c_004", "c_004", "c_004"},
+	)
+
+	// O gravador entra após Start: o replay começa na primeira jogada
+	recorder, err := game.NewReplayRecorder(dir, match.ID)
+	if err != nil {
+		t.Fatalf("Erro ao criar gravador de replay: %v", err)
+	}
+	match.Subscribe(recorder)
+
+	// Inferno Titan (ATK 10 + 3 FIRE > PLANT) contra Forest Guardian (DEF 8) encerra a partida
+	match.HP[1] = 2
+	match.Apply(game.Play{PlayerID: "p1", CardID: "c_007", Lock: true})
+	match.Apply(game.Play{PlayerID: "p2", CardID: "c_006", Lock: true})
+
+	entries, err := game.LoadReplay(dir, match.ID)
+	if err != nil {
+		t.Fatalf("Erro ao ler replay: %v", err)
+	}
+
+	types := []string{}
+	for _, entry := range entries {
+		types = append(types, entry.T)
+	}
+	expected := []string{game.RecordPlay, game.RecordPlay, game.RecordRoundResult, game.RecordEnd}
+	if !reflect.DeepEqual(types, expected) {
+		t.Fatalf("Registros esperados %v, obtidos %v", expected, types)
+	}
+
+	if entries[0].PlayerID != "p1" || entries[0].CardID != "c_007" || entries[0].TS == 0 {
+		t.Errorf("Jogada gravada incorretamente: %+v", entries[0])
+	}
+	if !entries[2].Seats[1].Eliminated || entries[2].Seats[0].DmgDealt != 5 {
+		t.Errorf("Resultado da rodada gravado incorretamente: %+v", entries[2].Seats)
+	}
+	if entries[3].Results["p1"] != protocol.WIN || entries[3].Results["p2"] != protocol.LOSE {
+		t.Errorf("Resultado final gravado incorretamente: %v", entries[3].Results)
+	}
+}
+
+func TestRoundTimeoutAutoplay(t *testing.T) {
+	match, events := newTestMatch(t, game.ModeSimultaneous,
+		game.Hand{"c_004", "c_004", "c_004", "c_004", "c_004"},
+		game.Hand{"c_004", "c_004", "c_004", "c_004", "c_004"},
+	)
+
+	// A primeira rodada já tem prazo
+	if state := events.last("p2", protocol.STATE); state == nil || state.DeadlineMs <= 0 {
+		t.Fatalf("Primeira rodada sem prazo: %+v", state)
+	}
+
+	match.Apply(game.Play{PlayerID: "p1", CardID: "c_004", Lock: true})
+	match.AutoplayIfNeeded()
+
+	notice := events.lastError("p2", protocol.TIMEOUT_PLAY)
+	if notice == nil || notice.Round != 1 {
+		t.Fatalf("Aviso de TIMEOUT_PLAY esperado para p2, obtido %+v", notice)
+	}
+	if events.last("p1", protocol.ERROR) != nil {
+		t.Error("Jogador que jogou a tempo recebeu aviso de timeout")
+	}
+	if result := events.last("p1", protocol.ROUND_RESULT); result == nil {
+		t.Fatalf("Rodada 1 não foi resolvida pelo auto-play: %+v", result)
+	}
+
+	// A rodada seguinte recebe um novo prazo
+	if state := events.last("p1", protocol.STATE); state.Round != 2 || state.DeadlineMs <= 0 {
+		t.Errorf("Rodada 2 sem prazo: %+v", state)
+	}
+}
+
+func TestAFKForfeit(t *testing.T) {
+	match, events := newTestMatch(t, game.ModeSimultaneous,
+		game.Hand{"c_004", "c_004", "c_004", "c_004", "c_004"},
+		game.Hand{"c_004", "c_004", "c_004", "c_004", "c_004"},
+	)
+	match.HP[0], match.HP[1] = 100, 100
+
+	// p1 joga todas as rodadas; p2 só é jogado pelo auto-play
+	for round := 1; round <= game.AFKAutoplayLimit; round++ {
+		hand := match.Hands[0]
+		match.Apply(game.Play{PlayerID: "p1", CardID: hand[0], Lock: true})
+		match.AutoplayIfNeeded()
+
+		if round == 1 && events.lastError("p2", protocol.AFK_WARNING) == nil {
+			t.Fatal("Aviso de inatividade esperado após o primeiro auto-play")
+		}
+		if round < game.AFKAutoplayLimit && events.last("p1", protocol.MATCH_END) != nil {
+			t.Fatalf("Partida encerrada antes do limite de inatividade (rodada %d)", round)
+		}
+	}
+
+	if end := events.last("p2", protocol.MATCH_END); end == nil || end.Result != protocol.FORFEIT || end.Reason != protocol.END_TIMEOUT {
+		t.Fatalf("p2 deveria perder por abandono, obtido %+v", end)
+	}
+	if end := events.last("p1", protocol.MATCH_END); end == nil || end.Result != protocol.WIN {
+		t.Fatalf("p1 deveria vencer por abandono, obtido %+v", end)
+	}
+	if events.lastError("p1", "OPPONENT_FORFEITED") == nil {
+		t.Error("p1 não foi avisado do abandono")
+	}
+}
+
+func TestForfeitAndDraw(t *testing.T) {
+	hand := game.Hand{"c_004", "c_004", "c_004", "c_004", "c_004"}
+
+	// Empate recusado não encerra a partida; aceito encerra com draw_agreed
+	match, events := newTestMatch(t, game.ModeSimultaneous, hand, hand)
+	if err := match.AcceptDraw("p2"); !errors.Is(err, game.ErrNoDrawOffer) {
+		t.Fatalf("Aceite sem oferta deveria falhar, obtido %v", err)
+	}
+	match.OfferDraw("p1")
+	if events.last("p2", protocol.DRAW_OFFERED) == nil {
+		t.Fatal("Oferta de empate não chegou ao oponente")
+	}
+	match.DeclineDraw("p2")
+	if events.last("p1", protocol.DRAW_DECLINED) == nil || events.last("p1", protocol.MATCH_END) != nil {
+		t.Fatal("Recusa do empate não foi avisada ou encerrou a partida")
+	}
+	match.OfferDraw("p2")
+	match.AcceptDraw("p1")
+	for _, playerID := range []string{"p1", "p2"} {
+		if end := events.last(playerID, protocol.MATCH_END); end == nil || end.Result != protocol.DRAW || end.Reason != protocol.END_DRAW_AGREED {
+			t.Fatalf("Empate combinado esperado para %s, obtido %+v", playerID, end)
+		}
+	}
+
+	// Desistência encerra imediatamente
+	match, events = newTestMatch(t, game.ModeSimultaneous, hand, hand)
+	if err := match.Forfeit("p1"); err != nil {
+		t.Fatalf("Desistência rejeitada: %v", err)
+	}
+	if end := events.last("p1", protocol.MATCH_END); end == nil || end.Result != protocol.FORFEIT || end.Reason != protocol.END_FORFEIT {
+		t.Fatalf("p1 deveria perder por desistência, obtido %+v", end)
+	}
+	if end := events.last("p2", protocol.MATCH_END); end == nil || end.Result != protocol.WIN {
+		t.Fatalf("p2 deveria vencer por desistência, obtido %+v", end)
+	}
+}
+
+func TestLockIn(t *testing.T) {
+	match, events := newTestMatch(t, game.ModeSimultaneous,
+		game.Hand{"c_001", "c_004", "c_004", "c_004", "c_004"},
+		game.Hand{"c_002", "c_004", "c_004", "c_004", "c_004"},
+	)
+
+	// Escolhas sem confirmação podem ser trocadas e retiradas
+	match.Apply(game.Play{PlayerID: "p1", CardID: "c_004"})
+	match.Apply(game.Play{PlayerID: "p1", CardID: "c_001"})
+	match.Apply(game.Play{PlayerID: "p2", CardID: "c_004"})
+	if err := match.Unplay("p2"); err != nil {
+		t.Fatalf("Retirada da carta rejeitada: %v", err)
+	}
+	if err := match.LockIn("p2", time.Time{}); !errors.Is(err, game.ErrNoCardChosen) {
+		t.Fatalf("Confirmação sem carta deveria falhar, obtido %v", err)
+	}
+
+	// O oponente só fica sabendo que houve confirmação, nunca a carta
+	if err := match.LockIn("p1", time.Time{}); err != nil {
+		t.Fatalf("Confirmação rejeitada: %v", err)
+	}
+	locked := events.last("p2", protocol.LOCKED_IN)
+	if locked == nil || locked.SenderID != "p1" || locked.You != nil || locked.Opponent != nil {
+		t.Fatalf("Aviso de confirmação incorreto: %+v", locked)
+	}
+	if err := match.Apply(game.Play{PlayerID: "p1", CardID: "c_004"}); !errors.Is(err, game.ErrAlreadyLocked) {
+		t.Fatalf("Troca após confirmação deveria falhar, obtido %v", err)
+	}
+	if events.last("p1", protocol.ROUND_RESULT) != nil {
+		t.Fatal("Rodada resolvida antes de todos confirmarem")
+	}
+
+	// A rodada resolve assim que todos confirmam, com as cartas confirmadas
+	match.Apply(game.Play{PlayerID: "p2", CardID: "c_002", Lock: true})
+	result := events.last("p1", protocol.ROUND_RESULT)
+	if result == nil || result.You.CardID != "c_001" || result.Opponent.CardID != "c_002" {
+		t.Fatalf("Rodada não resolveu com as cartas confirmadas: %+v", result)
+	}
+}
+
+func TestTimeBank(t *testing.T) {
+	hand := game.Hand{"c_004", "c_004", "c_004", "c_004", "c_004"}
+	match := game.NewMatch("m_bank", []string{"p1", "p2"}, loadTestCards(t), game.ModeSimultaneous, 1, game.DefaultRules())
+	copy(match.Hands, []game.Hand{hand, hand})
+	match.HP[0], match.HP[1] = 100, 100
+	match.SetTimeControl(game.TimeControl{Name: "TEST", BaseMs: 50, BankMs: 400, IdleMs: 60_000})
+
+	events := newEventRecorder()
+	match.Subscribe(events)
+	match.Start()
+	keepHands(match)
+	defer match.Forfeit("p1")
+
+	// p2 confirma depois do prazo base, consumindo parte do banco
+	match.Apply(game.Play{PlayerID: "p1", CardID: "c_004", Lock: true})
+	events.waitFor(t, "p2", time.Second, func(msg protocol.ServerMsg) bool {
+		return msg.T == protocol.ERROR && msg.Code == protocol.TIME_BANK
+	})
+	time.Sleep(150 * time.Millisecond)
+	roundStart := time.Now()
+	if err := match.Apply(game.Play{PlayerID: "p2", CardID: "c_004", Lock: true}); err != nil {
+		t.Fatalf("Jogada de p2 com banco restante rejeitada: %v", err)
+	}
+
+	state := events.last("p2", protocol.STATE)
+	if state.Round != 2 || state.You.BankMs <= 0 || state.You.BankMs >= 400 {
+		t.Fatalf("Banco de p2 deveria ter sido parcialmente consumido: %+v", state.You)
+	}
+	if state.Opponent.BankMs != 400 {
+		t.Fatalf("Banco de p1 não deveria mudar: %d", state.Opponent.BankMs)
+	}
+	bank := map[string]int64{"p1": state.Opponent.BankMs, "p2": state.You.BankMs}
+
+	// Sem jogadas, cada um recebe auto-play apenas quando o próprio banco acaba: p2, com menos
+	// banco, primeiro; p1 continua pensando até esgotar o seu
+	autoplayed := func(msg protocol.ServerMsg) bool {
+		return msg.T == protocol.ERROR && msg.Code == protocol.TIMEOUT_PLAY && msg.Round == 2
+	}
+	_, p2At := events.waitFor(t, "p2", 2*time.Second, autoplayed)
+	if notice, _ := events.find("p1", autoplayed); notice != nil {
+		t.Fatal("p1 recebeu auto-play junto com p2, antes de esgotar o próprio banco")
+	}
+	_, p1At := events.waitFor(t, "p1", 2*time.Second, autoplayed)
+	for playerID, at := range map[string]time.Time{"p1": p1At, "p2": p2At} {
+		earliest := time.Duration(50+bank[playerID]) * time.Millisecond
+		if elapsed := at.Sub(roundStart); elapsed < earliest {
+			t.Errorf("Auto-play de %s após %s, antes do fim do prazo base e do banco (%s)", playerID, elapsed, earliest)
+		}
+	}
+	if !p2At.Before(p1At) {
+		t.Error("p2 tinha menos banco e deveria receber auto-play antes de p1")
+	}
+
+	// Com os bancos esgotados, as rodadas seguintes usam apenas o prazo base
+	round3, _ := events.waitFor(t, "p1", time.Second, func(msg protocol.ServerMsg) bool {
+		return msg.T == protocol.STATE && msg.Round == 3
+	})
+	if round3.You.BankMs != 0 || round3.Opponent.BankMs != 0 {
+		t.Fatalf("Bancos deveriam estar esgotados na rodada 3: %+v / %+v", round3.You, round3.Opponent)
+	}
+}
+
+func TestMulligan(t *testing.T) {
+	match, events := newMulliganMatch(t, game.ModeSimultaneous,
+		game.Hand{"c_006", "c_006", "c_009", "c_003", "c_003"}, // mão fraca de PLANT
+		game.Hand{"c_004", "c_004", "c_004", "c_004", "c_004"},
+	)
+
+	if offer := events.last("p1", protocol.MULLIGAN_OFFER); offer == nil || len(offer.Cards) != game.HandSize || offer.DeadlineMs <= 0 {
+		t.Fatalf("Oferta de mulligan incorreta: %+v", offer)
+	}
+	if err := match.Apply(game.Play{PlayerID: "p1", CardID: "c_006", Lock: true}); !errors.Is(err, game.ErrNotYourTurn) {
+		t.Fatalf("Jogada durante o mulligan: esperado ErrNotYourTurn, obtido %v", err)
+	}
+	if err := match.Mulligan("p1", []string{"c_006", "c_006", "c_006"}); !errors.Is(err, game.ErrNotInHand) {
+		t.Fatalf("Devolução de cópias a mais: esperado ErrNotInHand, obtido %v", err)
+	}
+
+	// p1 troca três cartas; o oponente sabe apenas quantas
+	if err := match.Mulligan("p1", []string{"c_006", "c_006", "c_009"}); err != nil {
+		t.Fatalf("Mulligan rejeitado: %v", err)
+	}
+	if err := match.Mulligan("p1", nil); !errors.Is(err, game.ErrAlreadyMulliganed) {
+		t.Fatalf("Segundo mulligan: esperado ErrAlreadyMulliganed, obtido %v", err)
+	}
+	if done := events.last("p1", protocol.MULLIGAN_DONE); done == nil || done.Replaced != 3 || len(done.Cards) != game.HandSize {
+		t.Fatalf("Mão nova de p1 incorreta: %+v", done)
+	}
+	if done := events.last("p2", protocol.MULLIGAN_DONE); done == nil || done.Replaced != 3 || done.Cards != nil {
+		t.Fatalf("Aviso do mulligan para o oponente incorreto: %+v", done)
+	}
+	if !reflect.DeepEqual(match.Hands[0][:2], game.Hand{"c_003", "c_003"}) {
+		t.Errorf("Cartas mantidas deveriam continuar na mão: %v", match.Hands[0])
+	}
+	if events.last("p1", protocol.STATE) != nil {
+		t.Fatal("Primeira rodada começou antes de todos decidirem")
+	}
+
+	// A primeira rodada começa quando todos decidiram
+	match.Mulligan("p2", nil)
+	if state := events.last("p1", protocol.STATE); state == nil || state.Round != 1 || state.DeadlineMs <= 0 {
+		t.Fatalf("Primeira rodada não começou após o mulligan: %+v", state)
+	}
+	if err := match.Mulligan("p2", nil); !errors.Is(err, game.ErrNotInMulligan) {
+		t.Fatalf("Mulligan fora da fase: esperado ErrNotInMulligan, obtido %v", err)
+	}
+}
+
+func TestRoundActions(t *testing.T) {
+	match, events := newTestMatch(t, game.ModeSimultaneous,
+		game.Hand{"c_007", "c_007", "c_004", "c_004", "c_004"}, // Inferno Titan (ATK 10 / DEF 2)
+		game.Hand{"c_006", "c_004", "c_004", "c_004", "c_004"}, // Forest Guardian (ATK 5 / DEF 8)
+	)
+
+	if err := match.Apply(game.Play{PlayerID: "p2", Action: "BLUFF", CardID: "c_006"}); !errors.Is(err, game.ErrInvalidAction) {
+		t.Fatalf("Ação desconhecida: esperado ErrInvalidAction, obtido %v", err)
+	}
+	if err := match.Apply(game.Play{PlayerID: "p2", Action: game.ActionCycle, Cards: []string{"c_004"}}); !errors.Is(err, game.ErrCycleCards) {
+		t.Fatalf("CYCLE com uma carta: esperado ErrCycleCards, obtido %v", err)
+	}
+
+	// DEFEND dobra a DEF e não ataca: 10 + 3 (FIRE > PLANT) contra 8 × 2
+	match.Apply(game.Play{PlayerID: "p1", CardID: "c_007", Lock: true})
+	match.Apply(game.Play{PlayerID: "p2", Action: game.ActionDefend, CardID: "c_006", Lock: true})
+
+	result := events.last("p1", protocol.ROUND_RESULT)
+	if result == nil || result.Opponent.Action != string(game.ActionDefend) || result.Opponent.CardID != "c_006" {
+		t.Fatalf("ROUND_RESULT deveria revelar o DEFEND do oponente: %+v", result)
+	}
+	if result.You.DmgDealt != 0 || result.You.DmgTaken != 0 {
+		t.Errorf("DEFEND deveria anular os danos, obtido %d/%d", result.You.DmgDealt, result.You.DmgTaken)
+	}
+
+	// CYCLE troca duas cartas e deixa o jogador sem defesa
+	match.Energy[0], match.Energy[1] = game.EnergyCap, game.EnergyCap
+	match.Apply(game.Play{PlayerID: "p1", CardID: "c_007", Lock: true})
+	if err := match.Apply(game.Play{PlayerID: "p2", Action: game.ActionCycle, Cards: []string{"c_004", "c_004"}, Lock: true}); err != nil {
+		t.Fatalf("CYCLE rejeitado: %v", err)
+	}
+
+	result = events.last("p1", protocol.ROUND_RESULT)
+	if result.Opponent.Action != string(game.ActionCycle) || result.Opponent.CardID != "" || result.You.DmgDealt != 10 {
+		t.Fatalf("CYCLE deveria receber o dano completo sem revelar as cartas: %+v %+v", result.You, result.Opponent)
+	}
+	if len(match.Hands[1]) != game.HandSize || !reflect.DeepEqual(match.Discard[1], []string{"c_006", "c_004", "c_004"}) {
+		t.Errorf("Cartas do CYCLE deveriam ir para o descarte com a mão reposta: mão %v, descarte %v", match.Hands[1], match.Discard[1])
+	}
+
+	// O modo por turnos já separa ataque e defesa
+	turnBased, _ := newTestMatch(t, game.ModeTurnBased, game.Hand{"c_004", "c_004"}, game.Hand{"c_004", "c_004"})
+	if err := turnBased.Apply(game.Play{PlayerID: "p1", Action: game.ActionDefend, CardID: "c_004"}); !errors.Is(err, game.ErrActionUnavailable) {
+		t.Errorf("DEFEND no modo por turnos: esperado ErrActionUnavailable, obtido %v", err)
+	}
+}
+
+func TestTerrain(t *testing.T) {
+	cardDB := loadTestCards(t)
+
+	// Sequência de terrenos anunciados no STATE ao longo de algumas rodadas
+	terrains := func(seed int64, enabled bool) []string {
+		match := game.NewMatch("m_terrain", []string{"p1", "p2"}, cardDB, game.ModeSimultaneous, seed, game.DefaultRules())
+		match.SetTerrain(enabled)
+		events := newEventRecorder()
+		match.Subscribe(events)
+		match.Start()
+		keepHands(match)
+
+		sequence := []string{}
+		for round := 0; round < 5; round++ {
+			sequence = append(sequence, events.last("p1", protocol.STATE).Terrain)
+			match.Energy[0], match.Energy[1] = game.EnergyCap, game.EnergyCap
+			match.Apply(game.Play{PlayerID: "p1", Action: game.ActionDefend, CardID: match.Hands[0][0], Lock: true})
+			match.Apply(game.Play{PlayerID: "p2", Action: game.ActionDefend, CardID: match.Hands[1][0], Lock: true})
+		}
+		return sequence
+	}
+
+	if first := terrains(7, true); !reflect.DeepEqual(first, terrains(7, true)) || first[0] == "" {
+		t.Fatalf("Terrenos deveriam vir do gerador da partida: %v", first)
+	}
+	if sequence := terrains(7, false); sequence[0] != "" {
+		t.Fatalf("Partida sem o modificador anunciou terreno: %v", sequence)
+	}
+
+	// VOLCANO: FIRE +2 e WATER -2, somados ao bônus elemental
+	match := game.NewMatch("m_volcano", []string{"p1", "p2"}, cardDB, game.ModeSimultaneous, 1, game.DefaultRules())
+	copy(match.Hands, []game.Hand{
+		{"c_007", "c_004", "c_004", "c_004", "c_004"}, // Inferno Titan (FIRE, ATK 10 / DEF 2)
+		{"c_002", "c_004", "c_004", "c_004", "c_004"}, // Ice Mage (WATER, ATK 6 / DEF 6)
+	})
+	match.SetTerrain(true)
+	events := newEventRecorder()
+	match.Subscribe(events)
+	match.Start()
+	keepHands(match)
+
+	match.Terrain = game.TerrainVolcano
+	match.Apply(game.Play{PlayerID: "p1", CardID: "c_007", Lock: true})
+	match.Apply(game.Play{PlayerID: "p2", CardID: "c_002", Lock: true})
+
+	// p1: 10 + 2 - 6 = 6; p2: 6 + 3 - 2 - 2 = 5
+	result := events.last("p1", protocol.ROUND_RESULT)
+	if result.Terrain != string(game.TerrainVolcano) || result.You.TerrainBonus != 2 || result.Opponent.TerrainBonus != -2 {
+		t.Fatalf("ROUND_RESULT deveria trazer o terreno e seus ajustes: %+v %+v %+v", result.Terrain, result.You, result.Opponent)
+	}
+	if result.You.DmgDealt != 6 || result.You.DmgTaken != 5 {
+		t.Errorf("Dano esperado 6/5 no vulcão, obtido %d/%d", result.You.DmgDealt, result.You.DmgTaken)
+	}
+}
+
+func TestCustomRules(t *testing.T) {
+	cardDB := loadTestCards(t)
+
+	noBonus := 0
+	rules, err := game.ParseRules(&protocol.Rules{
+		HPStart:           30,
+		HandSize:          3,
+		ElementalATKBonus: &noBonus,
+		RoundPlayTimeout:  20_000,
+		DeckPolicy:        string(game.DeckSingleton),
+		BannedCards:       []string{"c_002"},
+	}, cardDB)
+	if err != nil {
+		t.Fatalf("Regras válidas rejeitadas: %v", err)
+	}
+
+	// Campos omitidos usam o padrão; limites, políticas e cartas desconhecidas são recusados
+	if defaults, err := game.ParseRules(&protocol.Rules{HPStart: 40}, cardDB); err != nil || defaults.HandSize != game.HandSize || defaults.ElementalATKBonus != game.ElementalATKBonus {
+		t.Errorf("Regras parciais deveriam completar com o padrão, obtido %+v (%v)", defaults, err)
+	}
+	invalid := []*protocol.Rules{
+		{HPStart: -1},
+		{HandSize: game.MaxRulesHandSize + 1},
+		{RoundPlayTimeout: 100},
+		{DeckPolicy: "SHARED"},
+		{BannedCards: []string{"c_999"}},
+		{BannedCards: []string{"c_001", "c_002", "c_003", "c_004", "c_005"}},
+	}
+	for _, view := range invalid {
+		if _, err := game.ParseRules(view, cardDB); !errors.Is(err, game.ErrInvalidRules) {
+			t.Errorf("Regras %+v deveriam ser recusadas, obtido %v", view, err)
+		}
+	}
+
+	match := game.NewMatch("m_rules", []string{"p1", "p2"}, cardDB, game.ModeSimultaneous, 1, rules)
+	if match.HP[0] != 30 || match.HP[1] != 30 || match.TimeControl.BaseMs != 20_000 {
+		t.Errorf("HP e prazo das regras não aplicados: HP %v, prazo %d", match.HP, match.TimeControl.BaseMs)
+	}
+
+	// Deck singleton: mão e deck formam uma cópia de cada carta permitida
+	for seat := range match.Players {
+		if len(match.Hands[seat]) != 3 {
+			t.Errorf("Mão do assento %d com %d cartas, esperado 3", seat, len(match.Hands[seat]))
+		}
+		seen := map[string]bool{}
+		for _, cardID := range append(append([]string{}, match.Hands[seat]...), match.Decks[seat]...) {
+			if cardID == "c_002" || seen[cardID] {
+				t.Errorf("Carta %s banida ou repetida no deck singleton do assento %d", cardID, seat)
+			}
+			seen[cardID] = true
+		}
+		if len(seen) != len(cardDB.CardIDs())-1 {
+			t.Errorf("Deck singleton do assento %d com %d cartas, esperado %d", seat, len(seen), len(cardDB.CardIDs())-1)
+		}
+	}
+
+	match.Hands[0] = game.Hand{"c_001", "c_004", "c_004"} // Fire Dragon (ATK 8 / DEF 5)
+	match.Hands[1] = game.Hand{"c_003", "c_004", "c_004"} // Vine Beast (ATK 7 / DEF 4)
+	events := newEventRecorder()
+	match.Subscribe(events)
+	match.Start()
+	if found := events.last("p1", protocol.MATCH_FOUND); found == nil || found.Rules == nil || found.Rules.HPStart != 30 {
+		t.Fatalf("MATCH_FOUND deveria trazer as regras da partida: %+v", found)
+	}
+	keepHands(match)
+
+	match.Apply(game.Play{PlayerID: "p1", CardID: "c_001", Lock: true})
+	match.Apply(game.Play{PlayerID: "p2", CardID: "c_003", Lock: true})
+
+	// Sem bônus elemental: FIRE contra PLANT causa apenas 8 - 4
+	result := events.last("p1", protocol.ROUND_RESULT)
+	if result == nil || result.You.ElementBonus != 0 || result.You.DmgDealt != 4 {
+		t.Fatalf("Bônus elemental das regras não aplicado: %+v", result)
+	}
+	if len(match.Hands[0]) != 3 || len(match.Hands[1]) != 3 {
+		t.Errorf("Mãos deveriam ser repostas até 3 cartas, obtido %v", match.Hands)
+	}
+}
+
+func TestLobbies(t *testing.T) {
+	lobbies := game.NewLobbyManager(1)
+
+	lobby := lobbies.Create(game.Lobby{HostID: "p1", Mode: game.ModeSimultaneous, Rules: game.DefaultRules()})
+	if len(lobby.Code) != game.CodeLength || lobby.HostID != "p1" || lobby.Full() {
+		t.Fatalf("Sala criada inválida: %+v", lobby)
+	}
+	if _, err := lobbies.Join("ZZZZZZ", "p2", ""); !errors.Is(err, game.ErrLobbyNotFound) {
+		t.Errorf("Código desconhecido deveria ser recusado, obtido %v", err)
+	}
+
+	// Entrar de novo na própria sala não ocupa outro assento
+	if again, err := lobbies.Join(lobby.Code, "p1", ""); err != nil || len(again.Players) != 1 {
+		t.Errorf("Anfitrião duplicado na sala: %+v (%v)", again, err)
+	}
+
+	// Com todos os assentos ocupados a sala fecha e a partida começa
+	full, err := lobbies.Join(lobby.Code, "p2", "")
+	if err != nil || !full.Full() || !reflect.DeepEqual(full.Players, []string{"p1", "p2"}) {
+		t.Fatalf("Sala deveria ficar cheia com p1 e p2: %+v (%v)", full, err)
+	}
+	if _, err := lobbies.Join(lobby.Code, "p3", ""); !errors.Is(err, game.ErrLobbyNotFound) {
+		t.Errorf("Sala cheia deveria ser fechada, obtido %v", err)
+	}
+	if _, ok := lobbies.LobbyOf("p1"); ok {
+		t.Error("Jogadores da sala fechada não deveriam continuar nela")
+	}
+
+	// O anfitrião que sai passa a sala ao próximo jogador; a sala vazia é fechada
+	teams := lobbies.Create(game.Lobby{HostID: "p3", Mode: game.ModeTeams, Rules: game.DefaultRules()})
+	lobbies.Join(teams.Code, "p4", "")
+	remaining, ok := lobbies.Leave("p3")
+	if !ok || remaining.HostID != "p4" || !reflect.DeepEqual(remaining.Players, []string{"p4"}) {
+		t.Errorf("p4 deveria assumir a sala: %+v", remaining)
+	}
+	if closed, ok := lobbies.Leave("p4"); !ok || len(closed.Players) != 0 {
+		t.Errorf("Sala vazia deveria ser fechada: %+v", closed)
+	}
+	if _, err := lobbies.Join(teams.Code, "p5", ""); !errors.Is(err, game.ErrLobbyNotFound) {
+		t.Errorf("Sala vazia deveria ser removida, obtido %v", err)
+	}
+}
+
+func TestChallenges(t *testing.T) {
+	challenges := game.NewChallengeManager(1)
+
+	if _, err := challenges.Send(game.Challenge{ChallengerID: "p1", TargetID: "p1"}); !errors.Is(err, game.ErrSelfChallenge) {
+		t.Errorf("Desafio a si mesmo deveria ser recusado, obtido %v", err)
+	}
+
+	// Desafio direto: só o desafiado aceita, uma única vez
+	direct, err := challenges.Send(game.Challenge{ChallengerID: "p1", TargetID: "p2", Mode: game.ModeSimultaneous})
+	if err != nil || direct.ID == "" || direct.Code != "" {
+		t.Fatalf("Desafio direto inválido: %+v (%v)", direct, err)
+	}
+	if _, err := challenges.Accept(direct.ID, "p3"); !errors.Is(err, game.ErrChallengeNotFound) {
+		t.Errorf("Apenas o desafiado deveria aceitar, obtido %v", err)
+	}
+	if accepted, err := challenges.Accept(direct.ID, "p2"); err != nil || accepted.ChallengerID != "p1" {
+		t.Fatalf("Aceite do desafiado falhou: %+v (%v)", accepted, err)
+	}
+	if _, err := challenges.Accept(direct.ID, "p2"); !errors.Is(err, game.ErrChallengeNotFound) {
+		t.Errorf("Desafio aceito não deveria continuar pendente, obtido %v", err)
+	}
+
+	// Convite por código: qualquer outro jogador resgata, uma única vez
+	invite, _ := challenges.Send(game.Challenge{ChallengerID: "p1", Mode: game.ModeTurnBased})
+	if len(invite.Code) != game.CodeLength {
+		t.Fatalf("Convite sem código: %+v", invite)
+	}
+	if _, err := challenges.Redeem(invite.Code, "p1"); !errors.Is(err, game.ErrSelfChallenge) {
+		t.Errorf("Criador não deveria resgatar o próprio convite, obtido %v", err)
+	}
+	redeemed, err := challenges.Redeem(invite.Code, "p4")
+	if err != nil || redeemed.TargetID != "p4" || redeemed.Mode != game.ModeTurnBased {
+		t.Fatalf("Resgate do convite falhou: %+v (%v)", redeemed, err)
+	}
+	if _, err := challenges.Redeem(invite.Code, "p5"); !errors.Is(err, game.ErrChallengeNotFound) {
+		t.Errorf("Convite deveria ser de uso único, obtido %v", err)
+	}
+
+	// Recusa, expiração e desconexão retiram os desafios pendentes
+	declined, _ := challenges.Send(game.Challenge{ChallengerID: "p1", TargetID: "p2"})
+	if _, err := challenges.Decline(declined.ID, "p2"); err != nil {
+		t.Errorf("Recusa falhou: %v", err)
+	}
+	expiring, _ := challenges.Send(game.Challenge{ChallengerID: "p1", TargetID: "p2"})
+	lasting, _ := challenges.Send(game.Challenge{ChallengerID: "p3"})
+	expired := challenges.Expire(time.Now().Add(time.Duration(game.ChallengeTimeout+1) * time.Millisecond))
+	if len(expired) != 1 || expired[0].ID != expiring.ID {
+		t.Errorf("Apenas o desafio direto deveria expirar, obtido %+v", expired)
+	}
+	if removed := challenges.RemovePlayer("p3"); len(removed) != 1 || removed[0].ID != lasting.ID {
+		t.Errorf("Convite de quem saiu deveria ser cancelado, obtido %+v", removed)
+	}
+}
+
+func TestLobbyBrowser(t *testing.T) {
+	lobbies := game.NewLobbyManager(1)
+
+	rules := game.DefaultRules()
+	rules.HPStart = 35
+	open := lobbies.Create(game.Lobby{HostID: "p1", Mode: game.ModeFreeForAll, Rules: rules, Spectators: true})
+	private := lobbies.Create(game.Lobby{HostID: "p2", Mode: game.ModeSimultaneous, Rules: game.DefaultRules(), Password: "segredo"})
+
+	// A lista traz as salas abertas com o resumo das regras, sem revelar a senha
+	listed := lobbies.List()
+	if len(listed) != 2 {
+		t.Fatalf("Esperadas 2 salas abertas, obtido %+v", listed)
+	}
+	views := map[string]protocol.LobbyView{}
+	for _, lobby := range listed {
+		views[lobby.Code] = lobby.View()
+	}
+	if view := views[open.Code]; view.HostID != "p1" || view.Rules.HPStart != 35 || view.Players != 1 || view.MaxPlayers != 4 || !view.Spectators || view.PasswordProtected {
+		t.Errorf("Resumo da sala aberta incorreto: %+v", view)
+	}
+	if view := views[private.Code]; !view.PasswordProtected || view.Spectators {
+		t.Errorf("Sala com senha deveria ser marcada como protegida: %+v", view)
+	}
+
+	// Sala com senha recusa quem não a informa
+	if _, err := lobbies.Join(private.Code, "p3", ""); !errors.Is(err, game.ErrWrongPassword) {
+		t.Errorf("Senha ausente deveria ser recusada, obtido %v", err)
+	}
+	if joined, err := lobbies.Join(private.Code, "p3", "segredo"); err != nil || !joined.Full() {
+		t.Fatalf("Senha correta deveria completar a sala: %+v (%v)", joined, err)
+	}
+
+	// Salas cheias deixam a lista
+	if listed := lobbies.List(); len(listed) != 1 || listed[0].Code != open.Code {
+		t.Errorf("Apenas a sala aberta deveria continuar na lista, obtido %+v", listed)
+	}
+}
+
+func TestAsyncMatches(t *testing.T) {
+	cardDB := loadTestCards(t)
+	store, err := game.NewAsyncStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Erro ao criar store: %v", err)
+	}
+
+	if game.AsyncModeAllowed(game.ModeDraft) || game.AsyncModeAllowed(game.ModeTeams) || !game.AsyncModeAllowed(game.ModeTurnBased) {
+		t.Error("Partidas assíncronas deveriam aceitar apenas modos 1v1 sem draft")
+	}
+
+	match := game.NewMatch("m_async", []string{"p1", "p2"}, cardDB, game.ModeSimultaneous, 7, game.DefaultRules())
+	match.SetTimeControl(game.TimeControls[game.TimeCorrespondence])
+	events := newEventRecorder()
+	match.Subscribe(events)
+	match.Persist(store)
+	match.Start()
+
+	// O mulligan tem o prazo base da correspondência, não o do mulligan ao vivo
+	if offer := events.last("p1", protocol.MULLIGAN_OFFER); offer == nil || offer.DeadlineMs <= game.MulliganTimeout {
+		t.Fatalf("Mulligan assíncrono deveria ter o prazo da correspondência: %+v", offer)
+	}
+	keepHands(match)
+	if turn := events.last("p2", protocol.YOUR_TURN); turn == nil || turn.MatchID != "m_async" || turn.Round != 1 {
+		t.Fatalf("YOUR_TURN esperado no início da rodada, obtido %+v", turn)
+	}
+
+	if err := match.Apply(game.Play{PlayerID: "p1", CardID: match.Hands[0][0], Lock: true}); err != nil {
+		t.Fatalf("Jogada de p1 rejeitada: %v", err)
+	}
+	if view := match.AsyncView("p1"); view.YourTurn || view.OpponentID != "p2" {
+		t.Errorf("p1 já jogou e não deveria estar na vez: %+v", view)
+	}
+	if view := match.AsyncView("p2"); !view.YourTurn {
+		t.Errorf("p2 deveria estar na vez: %+v", view)
+	}
+
+	// O estado gravado restaura a jogada confirmada e o gerador da partida no mesmo ponto
+	restored, err := store.LoadAll(cardDB)
+	if err != nil || len(restored) != 1 {
+		t.Fatalf("Esperada 1 partida restaurada, obtido %d (%v)", len(restored), err)
+	}
+	copyMatch := restored[0]
+	if !copyMatch.IsAsync() || copyMatch.Waiting["p1"] != match.Waiting["p1"] || !reflect.DeepEqual(copyMatch.Hands, match.Hands) {
+		t.Fatalf("Partida restaurada difere da original: %+v", copyMatch.Waiting)
+	}
+	copyEvents := newEventRecorder()
+	copyMatch.Subscribe(copyEvents)
+	copyMatch.Resume()
+
+	for _, m := range []*game.Match{match, copyMatch} {
+		if err := m.Apply(game.Play{PlayerID: "p2", CardID: m.Hands[1][0], Lock: true}); err != nil {
+			t.Fatalf("Jogada de p2 rejeitada: %v", err)
+		}
+	}
+	if !reflect.DeepEqual(copyMatch.HP, match.HP) || !reflect.DeepEqual(copyMatch.Hands, match.Hands) {
+		t.Errorf("Rodada da partida restaurada difere: HP %v/%v, mãos %v/%v", copyMatch.HP, match.HP, copyMatch.Hands, match.Hands)
+	}
+
+	// Quem volta à partida recebe o estado atual; o fim da partida apaga o arquivo
+	if err := copyMatch.SyncState("p1"); err != nil || copyEvents.last("p1", protocol.STATE) == nil {
+		t.Errorf("SyncState deveria reenviar o STATE: %v", err)
+	}
+	if err := copyMatch.Forfeit("p2"); err != nil {
+		t.Fatalf("Desistência falhou: %v", err)
+	}
+	if remaining, _ := store.LoadAll(cardDB); len(remaining) != 0 {
+		t.Errorf("Partida finalizada não deveria ser restaurada, obtido %d", len(remaining))
+	}
+}
+
+func TestCampaign(t *testing.T) {
+	cardDB := loadTestCards(t)
+	campaign, err := game.LoadCampaign("../server/campaign.json", cardDB)
+	if err != nil {
+		t.Fatalf("Erro ao carregar campanha: %v", err)
+	}
+	encounter, _, ok := campaign.Find("e_01")
+	if !ok {
+		t.Fatal("Encontro e_01 não encontrado")
+	}
+
+	// O bot joga sozinho: o jogador só acompanha as próprias mensagens
+	msgs := make(chan protocol.ServerMsg, 64)
+	match := encounter.NewMatch("m_campaign", "p1", cardDB, 5)
+	match.Subscribe(game.ObserverFunc(func(event game.Event) {
+		if event.PlayerID == "p1" {
+			msgs <- event.Msg
+		}
+	}))
+	if match.HP[1] != encounter.EnemyHP || match.HP[0] != game.HPStart {
+		t.Fatalf("HP esperado %d (jogador) e %d (bot), obtido %v", game.HPStart, encounter.EnemyHP, match.HP)
+	}
+	for _, cardID := range match.Hands[1] {
+		if !containsCard(encounter.EnemyDeck, cardID) {
+			t.Errorf("Carta %s fora do deck fixo do bot", cardID)
+		}
+	}
+	match.Start()
+	match.Mulligan("p1", nil)
+
+	waitMsg := func(msgType string) protocol.ServerMsg {
+		t.Helper()
+		for {
+			select {
+			case msg := <-msgs:
+				if msg.T == msgType {
+					return msg
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("%s não recebido", msgType)
+			}
+		}
+	}
+	state := waitMsg(protocol.STATE)
+	if err := match.Apply(game.Play{PlayerID: "p1", CardID: state.You.Hand[0], Lock: true}); err != nil {
+		t.Fatalf("Jogada rejeitada: %v", err)
+	}
+	if result := waitMsg(protocol.ROUND_RESULT); result.Opponent.CardID == "" {
+		t.Errorf("O bot deveria ter jogado uma carta: %+v", result.Opponent)
+	}
+
+	// Progresso por conta: o segundo encontro exige vencer o primeiro, que recompensa uma única vez
+	path := t.TempDir() + "/progress.json"
+	progress, err := game.LoadCampaignProgress(path)
+	if err != nil {
+		t.Fatalf("Erro ao abrir progresso: %v", err)
+	}
+	if err := progress.CheckUnlocked(campaign, "p1", "e_02"); !errors.Is(err, game.ErrEncounterLocked) {
+		t.Errorf("e_02 deveria estar bloqueado, obtido %v", err)
+	}
+	if first, err := progress.Clear("p1", encounter); !first || err != nil {
+		t.Fatalf("Primeira vitória deveria ser recompensada: %t %v", first, err)
+	}
+	if first, _ := progress.Clear("p1", encounter); first {
+		t.Error("Segunda vitória não deveria ser recompensada")
+	}
+
+	reloaded, err := game.LoadCampaignProgress(path)
+	if err != nil {
+		t.Fatalf("Erro ao recarregar progresso: %v", err)
+	}
+	if err := reloaded.CheckUnlocked(campaign, "p1", "e_02"); err != nil {
+		t.Errorf("e_02 deveria estar desbloqueado após vencer e_01: %v", err)
+	}
+	views, coins := reloaded.View(campaign, "p1")
+	if coins != encounter.Reward.Coins || !views[0].Cleared || !views[1].Unlocked || views[2].Unlocked {
+		t.Errorf("Progresso recarregado incorreto: %d moedas, %+v", coins, views[:3])
+	}
+}
+
+func TestScenarios(t *testing.T) {
+	cardDB := loadTestCards(t)
+	scenarios, err := game.LoadScenarios("../server/scenarios", cardDB)
+	if err != nil {
+		t.Fatalf("Erro ao carregar cenários: %v", err)
+	}
+	if len(scenarios.Scenarios) == 0 {
+		t.Fatal("Nenhum cenário carregado")
+	}
+
+	// Regressão: a solução de cada cenário continua cumprindo o objetivo com as regras atuais
+	for _, scenario := range scenarios.Scenarios {
+		report, err := scenario.Verify(cardDB)
+		if err != nil {
+			t.Errorf("Cenário %s: %v", scenario.ID, err)
+		} else if !report.Passed {
+			t.Errorf("Cenário %s: solução não cumpre o objetivo: %+v", scenario.ID, report)
+		}
+	}
+
+	// Partida ao vivo contra o oponente roteirizado, sem mulligan e com a mão do arquivo
+	scenario, ok := scenarios.Find("t_01")
+	if !ok {
+		t.Fatal("Cenário t_01 não encontrado")
+	}
+	msgs := make(chan protocol.ServerMsg, 64)
+	match := scenario.NewMatch("m_scenario", "p1", cardDB, 5)
+	match.Subscribe(game.ObserverFunc(func(event game.Event) {
+		if event.PlayerID == "p1" {
+			msgs <- event.Msg
+		}
+	}))
+	match.Start()
+
+	waitMsg := func(msgType string) protocol.ServerMsg {
+		t.Helper()
+		for {
+			select {
+			case msg := <-msgs:
+				if msg.T == protocol.MULLIGAN_OFFER {
+					t.Fatal("Cenários não oferecem mulligan")
+				}
+				if msg.T == msgType {
+					return msg
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("%s não recebido", msgType)
+			}
+		}
+	}
+	state := waitMsg(protocol.STATE)
+	if !reflect.DeepEqual(state.You.Hand, scenario.Player.Hand) || state.You.HP != scenario.Player.HP {
+		t.Fatalf("Situação inicial incorreta: mão %v, HP %d", state.You.Hand, state.You.HP)
+	}
+
+	// Carta errada: a Ice Mage perde para PLANT e o objetivo de vencer nesta rodada falha
+	if err := match.Apply(game.Play{PlayerID: "p1", CardID: "c_002", Lock: true}); err != nil {
+		t.Fatalf("Jogada rejeitada: %v", err)
+	}
+	if end := waitMsg(protocol.MATCH_END); end.Result != protocol.LOSE || end.Reason != protocol.END_GOAL {
+		t.Errorf("Esperado LOSE por objetivo, obtido %s (%s)", end.Result, end.Reason)
+	}
+	report := waitMsg(protocol.SCENARIO_RESULT).Report
+	if report == nil || report.Passed || report.ScenarioID != "t_01" || report.Round != 1 {
+		t.Errorf("Relatório incorreto: %+v", report)
+	}
+}
+
+func TestRaidBoss(t *testing.T) {
+	cardDB := loadTestCards(t)
+	roster, err := game.LoadRaidRoster("../server/raids.json", cardDB)
+	if err != nil {
+		t.Fatalf("Erro ao carregar chefes do raid: %v", err)
+	}
+	if current := roster.Current(time.Now()); current.ID == "" || current.HP <= game.HPStart {
+		t.Errorf("Chefe da semana inválido: %+v", current)
+	}
+
+	// Chefe de teste: só Forest Guardian (PLANT, ATK 5, DEF 8), armadura 1, golpe em área toda rodada
+	// e em fúria desde o início
+	deck := make([]string, 10)
+	for i := range deck {
+		deck[i] = "c_006"
+	}
+	boss := game.Boss{
+		ID: "golem", Name: "Golem", HP: 80, Deck: deck, Strategy: game.BotAggressive,
+		Abilities: game.BossAbilities{Armor: 1, EnrageBelow: 80, EnrageATK: 2, CleaveEvery: 1},
+	}
+
+	msgs := make(chan protocol.ServerMsg, 64)
+	match := game.NewMatch("m_raid", []string{"p1", "p2", boss.BotID()}, cardDB, game.ModeRaid, 7, game.DefaultRules())
+	for _, playerID := range []string{"p1", "p2"} {
+		match.SetHand(playerID, []string{"c_007", "c_006", "c_009"}, nil)
+	}
+	boss.Join(match)
+	match.Subscribe(game.ObserverFunc(func(event game.Event) {
+		if event.PlayerID == "p1" {
+			msgs <- event.Msg
+		}
+	}))
+	if !reflect.DeepEqual(match.Teams, []int{0, 0, 1}) || match.HP[2] != boss.HP {
+		t.Fatalf("Lados incorretos: times %v, HP %v", match.Teams, match.HP)
+	}
+	match.Start()
+	match.Mulligan("p1", nil)
+	match.Mulligan("p2", nil)
+
+	waitMsg := func(msgType string) protocol.ServerMsg {
+		t.Helper()
+		for {
+			select {
+			case msg := <-msgs:
+				if msg.T == msgType {
+					return msg
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("%s não recebido", msgType)
+			}
+		}
+	}
+	if found := waitMsg(protocol.MATCH_FOUND); found.Boss == nil || found.Boss.PlayerID != boss.BotID() {
+		t.Fatalf("MATCH_FOUND sem o chefe: %+v", found.Boss)
+	}
+	waitMsg(protocol.STATE)
+
+	// Os dois jogam Inferno Titan (FIRE, ATK 10 + 3 contra PLANT): 13 - (8 + 1) = 4 de dano cada
+	for _, playerID := range []string{"p1", "p2"} {
+		if err := match.Apply(game.Play{PlayerID: playerID, CardID: "c_007", Lock: true}); err != nil {
+			t.Fatalf("Jogada de %s rejeitada: %v", playerID, err)
+		}
+	}
+	result := waitMsg(protocol.ROUND_RESULT)
+	if len(result.Seats) != 3 {
+		t.Fatalf("ROUND_RESULT deveria ter 3 assentos: %+v", result.Seats)
+	}
+
+	// O dano dos jogadores soma no chefe; em fúria (ATK 5 + 2) o golpe em área acerta os dois (DEF 2)
+	if got := result.Seats[2]; got.HP != 72 || got.DmgTaken != 8 || got.DmgDealt != 10 {
+		t.Errorf("Chefe: HP %d, recebeu %d, causou %d (esperado 72, 8, 10)", got.HP, got.DmgTaken, got.DmgDealt)
+	}
+	if logs := strings.Join(result.Logs, " "); !strings.Contains(logs, "Chefe Golem entrou em fúria") || !strings.Contains(logs, "golpe em área") {
+		t.Errorf("Logs sem as habilidades do chefe: %v", result.Logs)
+	}
+	for _, seat := range result.Seats[:2] {
+		if seat.HP != game.HPStart-5 || seat.Target != boss.BotID() {
+			t.Errorf("Jogador %s: HP %d, alvo %s", seat.PlayerID, seat.HP, seat.Target)
+		}
+	}
+}
+
+func TestLadder(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ladder, err := game.OpenLadder(dir, 24*time.Hour, start)
+	if err != nil {
+		t.Fatalf("Erro ao abrir ranking: %v", err)
+	}
+
+	// Ratings iguais: a vitória vale metade de EloK, e o perdedor perde o mesmo
+	changes, err := ladder.Record("m_1", protocol.END_HP, map[string]string{"ana": protocol.WIN, "bia": protocol.LOSE}, start)
+	if err != nil {
+		t.Fatalf("Erro ao registrar partida: %v", err)
+	}
+	if changes[0].Rating != 1216 || changes[0].Delta != 16 || changes[1].Rating != 1184 || changes[1].Delta != -16 {
+		t.Errorf("Variação de rating incorreta: %+v", changes)
+	}
+	if _, err := ladder.Record("m_x", protocol.END_HP, map[string]string{"a": protocol.WIN, "b": protocol.LOSE, "c": protocol.LOSE}, start); err == nil {
+		t.Error("Partida ranqueada com 3 jogadores deveria ser rejeitada")
+	}
+
+	// Treze jogadores na temporada: a segunda página tem três, e o último é quem perdeu todas
+	for i := 1; i <= 10; i++ {
+		winner := fmt.Sprintf("p%02d", i)
+		if _, err := ladder.Record(fmt.Sprintf("m_%d", i+1), protocol.END_HP, map[string]string{winner: protocol.WIN, "zeca": protocol.LOSE}, start); err != nil {
+			t.Fatalf("Erro ao registrar partida: %v", err)
+		}
+	}
+	page := ladder.Leaderboard("zeca", 2, start)
+	if page.Season != 1 || page.Page != 2 || page.Pages != 2 || len(page.Entries) != 3 {
+		t.Fatalf("Segunda página incorreta: %+v", page)
+	}
+	if page.You == nil || page.You.Rank != 13 || page.Entries[2].PlayerID != "zeca" || page.You.Losses != 10 {
+		t.Errorf("Posição de zeca incorreta: %+v", page.You)
+	}
+	if page := ladder.Leaderboard("ninguem", 99, start); page.Page != 2 || page.You != nil {
+		t.Errorf("Página além da última deveria virar a última, sem posição: %+v", page)
+	}
+	if first := ladder.Leaderboard("ana", 1, start); first.Entries[0].PlayerID != "ana" || first.You.Rank != 1 || first.You.Division != string(game.DivisionSilver) {
+		t.Errorf("Primeira página incorreta: %+v", first)
+	}
+	if game.DivisionOf(1700) != game.DivisionDiamond || game.DivisionOf(1299) != game.DivisionSilver || game.DivisionOf(1099) != game.DivisionBronze {
+		t.Error("Faixas das divisões incorretas")
+	}
+
+	// O ranking e o histórico de resultados sobrevivem ao reinício
+	ladder, err = game.OpenLadder(dir, 24*time.Hour, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Erro ao reabrir ranking: %v", err)
+	}
+	if ladder.Rating("ana") != 1216 || ladder.Rating("novato") != game.InitialRating {
+		t.Errorf("Rating recarregado incorreto: ana %d, novato %d", ladder.Rating("ana"), ladder.Rating("novato"))
+	}
+	history, err := os.ReadFile(filepath.Join(dir, "results.jsonl"))
+	if err != nil || strings.Count(string(history), "\n") != 11 {
+		t.Errorf("Histórico deveria ter 11 resultados: %v", err)
+	}
+
+	// Fim da temporada: recompensa pela divisão final e soft reset na metade do caminho até o inicial
+	if results, _ := ladder.EndSeasonIfDue(start.Add(23 * time.Hour)); results != nil {
+		t.Fatalf("Temporada encerrada antes do prazo: %+v", results)
+	}
+	results, err := ladder.EndSeasonIfDue(start.Add(25 * time.Hour))
+	if err != nil || len(results) != 13 {
+		t.Fatalf("Fim de temporada incorreto: %d resultados, %v", len(results), err)
+	}
+	ana, zeca := results[0], results[12]
+	if ana.PlayerID != "ana" || ana.Season != 1 || ana.Rank != 1 || ana.Coins != 100 || ana.NewRating != 1208 {
+		t.Errorf("Resultado de ana incorreto: %+v", ana)
+	}
+	if zeca.PlayerID != "zeca" || zeca.Division != string(game.DivisionBronze) || zeca.Coins != 50 || zeca.NewRating != game.InitialRating+(zeca.Rating-game.InitialRating)/2 {
+		t.Errorf("Resultado de zeca incorreto: %+v", zeca)
+	}
+	if next := ladder.Leaderboard("ana", 1, start.Add(25*time.Hour)); next.Season != 2 || len(next.Entries) != 0 || next.SeasonEndMs != (24*time.Hour).Milliseconds() {
+		t.Errorf("Nova temporada deveria começar vazia: %+v", next)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	cardDB := loadTestCards(t)
+	path := t.TempDir() + "/profiles.json"
+	store, err := game.LoadProfiles(path)
+	if err != nil {
+		t.Fatalf("Erro ao abrir perfis: %v", err)
+	}
+
+	seat := func(playerID, cardID, action string, dealt, taken int) protocol.SeatView {
+		return protocol.SeatView{PlayerID: playerID, PlayerView: protocol.PlayerView{CardID: cardID, Action: action, DmgDealt: dealt, DmgTaken: taken}}
+	}
+
+	// Vitória de p1 sobre p2 em duas rodadas; p2 teve a primeira jogada feita por timeout
+	store.Track([]string{"p1", "p2"}, cardDB).Feed([]protocol.ReplayEntry{
+		{T: game.RecordPlay, Round: 1, PlayerID: "p1", CardID: "c_001"},
+		{T: game.RecordPlay, Round: 1, PlayerID: "p2", CardID: "c_003", Auto: true},
+		{T: game.RecordRoundResult, Round: 1, Seats: []protocol.SeatView{seat("p1", "c_001", "", 5, 2), seat("p2", "c_003", "", 2, 5)}},
+		{T: game.RecordPlay, Round: 2, PlayerID: "p1", CardID: "c_001"},
+		{T: game.RecordPlay, Round: 2, PlayerID: "p2", Action: "CYCLE"},
+		{T: game.RecordRoundResult, Round: 2, Seats: []protocol.SeatView{seat("p1", "c_001", "", 7, 0), seat("p2", "", "CYCLE", 0, 7)}},
+		{T: game.RecordEnd, Round: 2, Results: map[string]string{"p1": protocol.WIN, "p2": protocol.LOSE}},
+	})
+
+	// Derrota de p1 para um bot, que não é registrado
+	store.Track([]string{"p1"}, cardDB).Feed([]protocol.ReplayEntry{
+		{T: game.RecordPlay, Round: 1, PlayerID: "p1", CardID: "c_002", Auto: true},
+		{T: game.RecordPlay, Round: 1, PlayerID: "bot#e_01", CardID: "c_004"},
+		{T: game.RecordRoundResult, Round: 1, Seats: []protocol.SeatView{seat("p1", "c_002", "", 0, 9), seat("bot#e_01", "c_004", "", 9, 0)}},
+		{T: game.RecordEnd, Round: 1, Results: map[string]string{"p1": protocol.LOSE, "bot#e_01": protocol.WIN}},
+	})
+
+	// As estatísticas sobrevivem ao reinício
+	store, err = game.LoadProfiles(path)
+	if err != nil {
+		t.Fatalf("Erro ao recarregar perfis: %v", err)
+	}
+	if _, ok := store.View("bot#e_01"); ok {
+		t.Error("Bot não deveria ter perfil")
+	}
+
+	p1, ok := store.View("p1")
+	if !ok {
+		t.Fatal("Perfil de p1 não encontrado")
+	}
+	if p1.Matches != 2 || p1.Wins != 1 || p1.Losses != 1 || p1.WinRate != 50 || p1.CurrentStreak != 0 || p1.LongestWinStreak != 1 {
+		t.Errorf("Resultados de p1 incorretos: %+v", p1)
+	}
+	if p1.Rounds != 3 || p1.AvgDamageDealt != 4 || p1.AvgDamageTaken != 3.7 || p1.AutoPlayRate != 33 {
+		t.Errorf("Médias de p1 incorretas: %+v", p1)
+	}
+	elements := []protocol.ElementStats{{Element: "FIRE", Matches: 1, Wins: 1, WinRate: 100}, {Element: "WATER", Matches: 1}}
+	if !reflect.DeepEqual(p1.Elements, elements) {
+		t.Errorf("Vitórias por elemento de p1 incorretas: %+v", p1.Elements)
+	}
+	favorites := []protocol.CardCount{{CardID: "c_001", Plays: 2}, {CardID: "c_002", Plays: 1}}
+	if !reflect.DeepEqual(p1.FavoriteCards, favorites) {
+		t.Errorf("Cartas favoritas de p1 incorretas: %+v", p1.FavoriteCards)
+	}
+
+	p2, _ := store.View("p2")
+	if p2.Matches != 1 || p2.Losses != 1 || p2.Rounds != 2 || p2.AutoPlayRate != 50 || len(p2.Elements) != 1 || p2.Elements[0].Element != "PLANT" {
+		t.Errorf("Perfil de p2 incorreto: %+v", p2)
+	}
+}
+
+// containsCard verifica se a carta está na lista
+func containsCard(cards []string, cardID string) bool {
+	for _, id := range cards {
+		if id == cardID {
+			return true
+		}
+	}
+	return false
+}